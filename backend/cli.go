@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/term"
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/audit"
+	"smart-door-lock/backend/internal/auth"
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/migrations"
+)
+
+const usage = `Pemakaian:
+  backend [serve]                 jalankan server (default)
+  backend migrate up              terapkan semua migrasi yang belum jalan
+  backend migrate down [n]        rollback n migrasi terakhir (default 1)
+  backend migrate status          tampilkan status migrasi
+  backend create-admin <username> buat admin; password dibaca dari terminal
+                                  atau ADMIN_PASSWORD
+  backend seed                    isi data demo ke database kosong
+                                  (khusus development: admin/admin123)
+
+Administrasi (password dibaca seperti create-admin):
+  backend users list
+  backend users create [--role admin|user] <username>
+  backend users disable|enable <username>
+  backend users reset-password <username>   juga mencabut semua sesinya
+  backend sessions revoke <username>|--all
+  backend doorlock-users import [--update] [--dry-run] [--partial] <file.csv|->
+  backend doorlock-users export [--with-pins] [file.csv|-]
+  backend doors list
+  backend db backup <path>        salin database sqlite (VACUUM INTO)
+  backend audit verify            periksa rantai hash audit log`
+
+// run memilih subcommand dari argumen command line.
+func run(cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return serve(cfg)
+	}
+	switch args[0] {
+	case "serve":
+		return serve(cfg)
+	case "migrate":
+		return runMigrate(cfg, args[1:])
+	case "create-admin":
+		return runCreateAdmin(cfg, args[1:])
+	case "seed":
+		return runSeed(cfg)
+	case "users":
+		return runUsers(cfg, args[1:])
+	case "sessions":
+		return runSessions(cfg, args[1:])
+	case "doorlock-users":
+		return runDoorlockUsers(cfg, args[1:])
+	case "doors":
+		return runDoors(cfg, args[1:])
+	case "db":
+		return runDB(cfg, args[1:])
+	case "audit":
+		return runAudit(cfg, args[1:])
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+		return nil
+	default:
+		return fmt.Errorf("subcommand tidak dikenal: %q\n%s", args[0], usage)
+	}
+}
+
+// migrateUp dipanggil saat startup server.
+func migrateUp(db *gorm.DB) error {
+	ran, err := migrations.New(db).Up()
+	for _, m := range ran {
+		log.Printf("🗄️ Migrasi %04d_%s diterapkan", m.Version, m.Name)
+	}
+	return err
+}
+
+// openMigrated membuka database dan memastikan skemanya terbaru.
+func openMigrated(cfg config.Config) (*gorm.DB, error) {
+	db, err := store.Open(cfg.DBDriver, cfg.DBSource())
+	if err != nil {
+		return nil, err
+	}
+	return db, migrateUp(db)
+}
+
+func runMigrate(cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("migrate butuh up, down atau status\n%s", usage)
+	}
+	db, err := store.Open(cfg.DBDriver, cfg.DBSource())
+	if err != nil {
+		return err
+	}
+	m := migrations.New(db)
+
+	switch args[0] {
+	case "up":
+		if err := migrateUp(db); err != nil {
+			return err
+		}
+		fmt.Println("✅ Skema sudah versi terbaru")
+		return nil
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+				return fmt.Errorf("jumlah migrasi tidak valid: %q", args[1])
+			}
+		}
+		rolled, err := m.Down(n)
+		for _, mig := range rolled {
+			fmt.Printf("↩️ Migrasi %04d_%s di-rollback\n", mig.Version, mig.Name)
+		}
+		if err == nil && len(rolled) == 0 {
+			fmt.Println("Tidak ada migrasi untuk di-rollback")
+		}
+		return err
+	case "status":
+		statuses, err := m.Status()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range statuses {
+			state, at := "pending", ""
+			if s.Applied {
+				state, at = "applied", s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Unknown {
+				state = "unknown"
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, state, at)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("migrate %q tidak dikenal\n%s", args[0], usage)
+	}
+}
+
+func runSeed(cfg config.Config) error {
+	db, err := openMigrated(cfg)
+	if err != nil {
+		return err
+	}
+	if !seedIfEmpty(db) {
+		fmt.Println("Database sudah berisi user, data demo tidak diisi")
+		return nil
+	}
+	fmt.Println("⚠️ Data demo memakai password dan PIN yang diketahui umum, jangan dipakai di produksi")
+	return nil
+}
+
+func runCreateAdmin(cfg config.Config, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("create-admin butuh username\n%s", usage)
+	}
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+	a, err := openAdmin(cfg)
+	if err != nil {
+		return err
+	}
+	u, err := a.users.Create(args[0], password, store.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	a.record(audit.UserCreate, u.Username, u)
+	fmt.Printf("✅ Admin %q dibuat\n", u.Username)
+	return nil
+}
+
+// readPassword membaca password dari ADMIN_PASSWORD, dari terminal tanpa
+// echo (diminta dua kali), atau dari satu baris stdin bila bukan terminal.
+func readPassword() (string, error) {
+	if pw := os.Getenv("ADMIN_PASSWORD"); pw != "" {
+		return pw, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("gagal membaca password dari stdin: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Password (minimal %d karakter): ", auth.MinPasswordLength)
+	pw, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(os.Stderr, "Ulangi password: ")
+	again, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if string(pw) != string(again) {
+		return "", fmt.Errorf("password tidak sama")
+	}
+	return string(pw), nil
+}
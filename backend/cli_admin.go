@@ -0,0 +1,313 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"text/tabwriter"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/audit"
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/users"
+)
+
+// adminCLI adalah dependency subcommand administrasi. Semua perubahan
+// lewat users.Service yang sama dengan API dan dicatat ke audit log dengan
+// actor cli:<user OS>.
+type adminCLI struct {
+	db    *gorm.DB
+	st    store.Store
+	users *users.Service
+	audit *audit.Logger
+	actor string
+}
+
+func openAdmin(cfg config.Config) (*adminCLI, error) {
+	db, err := openMigrated(cfg)
+	if err != nil {
+		return nil, err
+	}
+	actor := "cli"
+	if u, err := user.Current(); err == nil {
+		actor = "cli:" + u.Username
+	}
+	st := store.NewGorm(db)
+	return &adminCLI{db: db, st: st, users: users.NewService(db, st), audit: audit.New(db), actor: actor}, nil
+}
+
+func (a *adminCLI) record(action, target string, after any) {
+	a.audit.Origin(a.actor, action, target, "cli", nil, after)
+}
+
+func table() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+}
+
+// ====== users ======
+
+func runUsers(cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("users butuh list, create, disable, enable atau reset-password\n%s", usage)
+	}
+	a, err := openAdmin(cfg)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		list, err := a.users.List()
+		if err != nil {
+			return err
+		}
+		w := table()
+		fmt.Fprintln(w, "ID\tUSERNAME\tROLE\tACTIVE\tCREATED AT")
+		for _, u := range list {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%s\n", u.ID, u.Username, u.Role, u.IsActive, u.CreatedAt.Format("2006-01-02 15:04"))
+		}
+		return w.Flush()
+
+	case "create":
+		fs := flag.NewFlagSet("users create", flag.ContinueOnError)
+		role := fs.String("role", store.RoleUser, "admin atau user")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return fmt.Errorf("users create butuh username\n%s", usage)
+		}
+		password, err := readPassword()
+		if err != nil {
+			return err
+		}
+		u, err := a.users.Create(fs.Arg(0), password, *role)
+		if err != nil {
+			return err
+		}
+		a.record(audit.UserCreate, u.Username, u)
+		fmt.Printf("✅ User %q (%s) dibuat\n", u.Username, u.Role)
+		return nil
+
+	case "disable", "enable":
+		if len(args) != 2 {
+			return fmt.Errorf("users %s butuh username\n%s", args[0], usage)
+		}
+		active := args[0] == "enable"
+		u, err := a.users.SetActive(args[1], active)
+		if err != nil {
+			return err
+		}
+		action := audit.UserDisable
+		if active {
+			action = audit.UserEnable
+		}
+		a.record(action, u.Username, u)
+		fmt.Printf("✅ User %q is_active=%t\n", u.Username, u.IsActive)
+		return nil
+
+	case "reset-password":
+		if len(args) != 2 {
+			return fmt.Errorf("users reset-password butuh username\n%s", usage)
+		}
+		password, err := readPassword()
+		if err != nil {
+			return err
+		}
+		u, err := a.users.ResetPassword(args[1], password)
+		if err != nil {
+			return err
+		}
+		a.record(audit.UserPassword, u.Username, nil)
+		fmt.Printf("✅ Password %q diganti, semua sesinya dicabut\n", u.Username)
+		return nil
+
+	default:
+		return fmt.Errorf("users %q tidak dikenal\n%s", args[0], usage)
+	}
+}
+
+// ====== sessions ======
+
+func runSessions(cfg config.Config, args []string) error {
+	if len(args) != 2 || args[0] != "revoke" {
+		return fmt.Errorf("pemakaian: sessions revoke <username>|--all\n%s", usage)
+	}
+	a, err := openAdmin(cfg)
+	if err != nil {
+		return err
+	}
+	if args[1] == "--all" {
+		n, err := a.users.RevokeAllSessions()
+		if err != nil {
+			return err
+		}
+		a.record(audit.SessionsRevoke, "*", map[string]int64{"users": n})
+		fmt.Printf("✅ Sesi %d user dicabut\n", n)
+		return nil
+	}
+	u, err := a.users.RevokeSessions(args[1])
+	if err != nil {
+		return err
+	}
+	a.record(audit.SessionsRevoke, u.Username, nil)
+	fmt.Printf("✅ Semua sesi %q dicabut\n", u.Username)
+	return nil
+}
+
+// ====== doorlock-users ======
+
+func runDoorlockUsers(cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("doorlock-users butuh import atau export\n%s", usage)
+	}
+	a, err := openAdmin(cfg)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "import":
+		fs := flag.NewFlagSet("doorlock-users import", flag.ContinueOnError)
+		update := fs.Bool("update", false, "timpa user dengan access_id yang sudah ada")
+		dryRun := fs.Bool("dry-run", false, "hanya validasi, tidak menyimpan")
+		partial := fs.Bool("partial", false, "simpan baris yang valid walaupun ada baris error")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return fmt.Errorf("doorlock-users import butuh file CSV atau -\n%s", usage)
+		}
+		in, err := openInput(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		rows, err := users.ReadDoorlockCSV(in, cfg.Location)
+		if err != nil {
+			return err
+		}
+
+		report, err := a.users.ImportDoorlockUsers(rows, users.ImportOptions{Update: *update, DryRun: *dryRun, Partial: *partial})
+		for _, r := range report.Rows {
+			for _, msg := range r.Errors {
+				fmt.Fprintf(os.Stderr, "baris %d (%s): %s\n", r.Row, r.AccessID, msg)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if report.DryRun {
+			fmt.Printf("Dry run: %d baru, %d diperbarui, %d error; tidak ada yang disimpan\n", report.Created, report.Updated, report.Failed)
+			return nil
+		}
+		if !report.Applied {
+			fmt.Println("Tidak ada baris valid untuk disimpan")
+			return nil
+		}
+		a.record(audit.DoorlockImport, "", report)
+		fmt.Printf("✅ %d user doorlock diimport (%d baru, %d diperbarui, %d dilewati)\n",
+			report.Created+report.Updated, report.Created, report.Updated, report.Failed)
+		return nil
+
+	case "export":
+		fs := flag.NewFlagSet("doorlock-users export", flag.ContinueOnError)
+		withPins := fs.Bool("with-pins", false, "sertakan PIN di file")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() > 1 {
+			return fmt.Errorf("doorlock-users export menerima satu file tujuan\n%s", usage)
+		}
+		list, err := a.users.DoorlockUsers()
+		if err != nil {
+			return err
+		}
+		out := io.Writer(os.Stdout)
+		if path := fs.Arg(0); path != "" && path != "-" {
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		return users.WriteDoorlockCSV(out, list, *withPins)
+
+	default:
+		return fmt.Errorf("doorlock-users %q tidak dikenal\n%s", args[0], usage)
+	}
+}
+
+// openInput membuka file, atau stdin bila path "-".
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// ====== doors ======
+
+func runDoors(cfg config.Config, args []string) error {
+	if len(args) != 1 || args[0] != "list" {
+		return fmt.Errorf("pemakaian: doors list\n%s", usage)
+	}
+	a, err := openAdmin(cfg)
+	if err != nil {
+		return err
+	}
+	list, err := a.st.Doors()
+	if err != nil {
+		return err
+	}
+	w := table()
+	fmt.Fprintln(w, "DOOR ID\tNAME\tZONE\tANTI-PASSBACK\tLOCKOUT (MIN)")
+	for _, d := range list {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", d.DoorID, d.Name, d.Zone, d.AntiPassback, d.LockoutMinutes)
+	}
+	return w.Flush()
+}
+
+// ====== db ======
+
+func runDB(cfg config.Config, args []string) error {
+	if len(args) != 2 || args[0] != "backup" {
+		return fmt.Errorf("pemakaian: db backup <path>\n%s", usage)
+	}
+	a, err := openAdmin(cfg)
+	if err != nil {
+		return err
+	}
+	if err := store.Backup(a.db, args[1]); err != nil {
+		return err
+	}
+	a.record(audit.DBBackup, args[1], nil)
+	fmt.Printf("✅ Backup disimpan ke %s\n", args[1])
+	return nil
+}
+
+// ====== audit ======
+
+func runAudit(cfg config.Config, args []string) error {
+	if len(args) != 1 || args[0] != "verify" {
+		return fmt.Errorf("pemakaian: audit verify\n%s", usage)
+	}
+	a, err := openAdmin(cfg)
+	if err != nil {
+		return err
+	}
+	v, err := a.audit.Verify()
+	if err != nil {
+		return err
+	}
+	if !v.Valid {
+		return fmt.Errorf("rantai audit rusak di event #%d: %s (%d event diperiksa)", v.BrokenAt, v.Reason, v.Checked)
+	}
+	fmt.Printf("✅ Rantai audit utuh (%d event diperiksa)\n", v.Checked)
+	return nil
+}
@@ -0,0 +1,283 @@
+// Package access memutuskan permintaan akses dari reader/pinpad: validasi
+// kredensial, anti-passback, hitungan gagal dan lockout, serta occupancy.
+package access
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"smart-door-lock/backend/internal/alarms"
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/devices"
+	"smart-door-lock/backend/internal/store"
+)
+
+// Alasan penolakan akses yang disimpan di Attendance.Reason
+const (
+	DenyUnknownAccessID = "access_id not found"
+	DenyInactive        = "user inactive"
+	DenySuspended       = "user suspended"
+	DenyExpired         = "credential expired"
+	DenyWrongDoor       = "wrong door"
+	DenyWrongPin        = "wrong pin"
+	DenyLockedOut       = "locked out"
+	DenyAntiPassback    = "anti-passback"
+)
+
+// Mode anti-passback per pintu
+const (
+	AntiPassbackOff  = "off"
+	AntiPassbackSoft = "soft" // hanya dicatat + alarm
+	AntiPassbackHard = "hard" // akses ditolak + alarm
+)
+
+// ====== FAILED ATTEMPT TRACKING ======
+
+// failedAttemptTracker menghitung akses yang ditolak dalam sliding window,
+// per pintu ("door:<id>") dan per kredensial ("cred:<access_id>").
+type failedAttemptTracker struct {
+	sync.Mutex
+	attempts map[string][]time.Time
+}
+
+// record mencatat satu kegagalan dan mengembalikan jumlah kegagalan
+// yang masih berada di dalam window.
+func (t *failedAttemptTracker) record(key string, at time.Time, window time.Duration) int {
+	t.Lock()
+	defer t.Unlock()
+
+	cutoff := at.Add(-window)
+	kept := t.attempts[key][:0]
+	for _, ts := range t.attempts[key] {
+		if ts.After(cutoff) {
+			kept = append(kept, ts)
+		}
+	}
+	kept = append(kept, at)
+	t.attempts[key] = kept
+	return len(kept)
+}
+
+func (t *failedAttemptTracker) reset(keys ...string) {
+	t.Lock()
+	defer t.Unlock()
+	for _, key := range keys {
+		delete(t.attempts, key)
+	}
+}
+
+// Service menjalankan keputusan akses beserta efek sampingnya (alarm,
+// buzzer, lockout, status pintu, occupancy).
+type Service struct {
+	store     store.Store
+	alarms    *alarms.Service
+	control   *devices.Controller
+	doors     *devices.Tracker
+	occupancy *Occupancy
+	attempts  *failedAttemptTracker
+}
+
+func NewService(st store.Store, alarmSvc *alarms.Service, control *devices.Controller, doors *devices.Tracker, occupancy *Occupancy) *Service {
+	return &Service{
+		store:     st,
+		alarms:    alarmSvc,
+		control:   control,
+		doors:     doors,
+		occupancy: occupancy,
+		attempts:  &failedAttemptTracker{attempts: make(map[string][]time.Time)},
+	}
+}
+
+// registerFailedAttempt dipanggil untuk setiap akses yang ditolak. Counter
+// pintu dan counter kredensial dihitung terpisah: bila counter pintu
+// mencapai batas, backend membuat alarm dan menyalakan buzzer; kredensial
+// hanya dikunci selama LockoutMinutes bila counter kredensial itu sendiri
+// mencapai batas, sehingga percobaan dengan access_id acak tidak bisa
+// mengunci access_id orang lain.
+func (s *Service) registerFailedAttempt(doorID, accessID, username string) {
+	door := devices.LoadDoor(s.store, doorID)
+	now := time.Now()
+	window := time.Duration(door.FailedAttemptWindow) * time.Second
+
+	doorKey := "door:" + doorID
+	credKey := "cred:" + accessID
+
+	if n := s.attempts.record(doorKey, now, window); n >= door.FailedAttemptLimit {
+		s.attempts.reset(doorKey)
+		s.raiseFailedAttempts(door, accessID, username, n, now)
+	}
+	if accessID == "" {
+		return
+	}
+	if n := s.attempts.record(credKey, now, window); n >= door.FailedAttemptLimit {
+		s.attempts.reset(credKey)
+		s.lockCredential(door, accessID, now)
+	}
+}
+
+// raiseFailedAttempts membuat alarm dan menyalakan buzzer pintu.
+func (s *Service) raiseFailedAttempts(door store.Door, accessID, username string, count int, now time.Time) {
+	doorID := door.DoorID
+	if username == "" {
+		username = "Unknown"
+	}
+	al := store.Alarm{
+		AlarmType: store.AlarmTypeFailedAttempts,
+		Username:  username,
+		AccessID:  accessID,
+		DoorID:    doorID,
+		Reason:    fmt.Sprintf("%d kali gagal masuk", count),
+		CreatedAt: now,
+	}
+	if err := s.alarms.Raise(&al); err != nil {
+		log.Printf("❌ %v", err)
+	}
+
+	if door.BuzzerID != "" {
+		if err := s.control.TriggerBuzzer(door.BuzzerID, door.BuzzerDuration); err != nil {
+			log.Printf("⚠️ Gagal menyalakan buzzer %s: %v", door.BuzzerID, err)
+		}
+	}
+}
+
+// lockCredential mengunci access_id selama LockoutMinutes pintu.
+func (s *Service) lockCredential(door store.Door, accessID string, now time.Time) {
+	if door.LockoutMinutes > 0 {
+		until := now.Add(time.Duration(door.LockoutMinutes) * time.Minute)
+		locked, err := s.store.LockDoorlockUser(accessID, until)
+		if err != nil {
+			log.Printf("Gagal mengunci access ID %s: %v", accessID, err)
+		} else if locked {
+			log.Printf("🔒 Access ID %s dikunci sampai %s", accessID, until.Format(time.RFC3339))
+		}
+	}
+}
+
+// ====== ACCESS DECISION ======
+
+type Request struct {
+	AccessID string
+	DoorID   string
+	Pin      string
+	Arrow    string
+}
+
+// Decide memutuskan apakah akses diterima, menyimpan hasilnya ke
+// Attendance (termasuk yang ditolak) dan mencatat kegagalan.
+func (s *Service) Decide(req Request) (store.Attendance, error) {
+	now := time.Now()
+	rec := store.Attendance{
+		AccessID:  req.AccessID,
+		DoorID:    req.DoorID,
+		Arrow:     req.Arrow,
+		Status:    "success",
+		CreatedAt: now,
+	}
+
+	doorUser, err := s.store.DoorlockUser(req.AccessID)
+	found := err == nil
+
+	switch {
+	case !found:
+		rec.Username = "Unknown"
+		rec.Reason = DenyUnknownAccessID
+	case doorUser.LockedUntil != nil && doorUser.LockedUntil.After(now):
+		rec.Reason = DenyLockedOut
+	case doorUser.ExpiresAt != nil && !doorUser.ExpiresAt.After(now):
+		// ditolak walaupun job expiry belum sempat menonaktifkan
+		rec.Reason = DenyExpired
+	case !doorUser.IsActive && doorUser.SuspendedAt != nil:
+		rec.Reason = DenySuspended
+	case !doorUser.IsActive:
+		rec.Reason = DenyInactive
+	case req.DoorID != "" && doorUser.DoorID != req.DoorID:
+		rec.Reason = DenyWrongDoor
+	case req.Pin != "" && doorUser.Pin != req.Pin:
+		rec.Reason = DenyWrongPin
+	}
+	if found {
+		rec.Username = doorUser.Name
+	}
+
+	if rec.Reason != "" {
+		rec.Status = "denied"
+	}
+
+	// Anti-passback dievaluasi terakhir; mode soft tetap mengizinkan akses
+	var passback *store.Attendance
+	if rec.Status == "success" {
+		door := devices.LoadDoor(s.store, req.DoorID)
+		if prev, violated := s.checkAntiPassback(door, req.AccessID, req.Arrow, now); violated {
+			passback = &prev
+			rec.Reason = DenyAntiPassback
+			if door.AntiPassback == AntiPassbackHard {
+				rec.Status = "denied"
+			}
+		}
+	}
+
+	if err := s.store.CreateAttendance(&rec); err != nil {
+		return rec, fmt.Errorf("gagal menyimpan attendance: %w", err)
+	}
+
+	if passback != nil {
+		s.raiseAntiPassbackAlarm(rec, *passback)
+	}
+
+	if rec.Status == "denied" {
+		log.Printf("⛔ Akses ditolak: %s (%s) pintu %s - %s", rec.Username, rec.AccessID, rec.DoorID, rec.Reason)
+		if passback == nil {
+			s.registerFailedAttempt(rec.DoorID, rec.AccessID, rec.Username)
+		}
+	} else {
+		s.attempts.reset("cred:" + rec.AccessID)
+		s.doors.RecordAccess(rec)
+		s.occupancy.Record(rec)
+	}
+
+	return rec, nil
+}
+
+// ====== ANTI-PASSBACK ======
+
+// checkAntiPassback melihat arah akses sukses terakhir access_id di zona
+// pintu ini. Arah yang sama dua kali berturut-turut (misalnya "in" lalu
+// "in") dianggap pelanggaran, kecuali sudah lewat waktu reset.
+func (s *Service) checkAntiPassback(door store.Door, accessID, arrow string, now time.Time) (store.Attendance, bool) {
+	if door.AntiPassback != AntiPassbackSoft && door.AntiPassback != AntiPassbackHard {
+		return store.Attendance{}, false
+	}
+
+	since := config.StartOfDay(now, s.occupancy.loc)
+	if door.AntiPassbackReset > 0 {
+		since = now.Add(-time.Duration(door.AntiPassbackReset) * time.Minute)
+	}
+
+	prev, err := s.store.LastSuccessfulAccess(accessID, door.DoorID, door.Zone, since)
+	if err != nil {
+		return prev, false
+	}
+	return prev, prev.Arrow == arrow
+}
+
+func (s *Service) raiseAntiPassbackAlarm(rec store.Attendance, prev store.Attendance) {
+	severity := store.SeverityMedium
+	if rec.Status == "denied" {
+		severity = store.SeverityHigh
+	}
+	al := store.Alarm{
+		AlarmType: store.AlarmTypeAntiPassback,
+		Username:  rec.Username,
+		AccessID:  rec.AccessID,
+		DoorID:    rec.DoorID,
+		Reason: fmt.Sprintf("Anti-passback: \"%s\" dua kali berturut-turut (sebelumnya di %s %s)",
+			rec.Arrow, prev.DoorID, prev.CreatedAt.In(s.occupancy.loc).Format("15:04")),
+		Severity:  severity,
+		CreatedAt: rec.CreatedAt,
+	}
+	if err := s.alarms.Raise(&al); err != nil {
+		log.Printf("❌ %v", err)
+	}
+}
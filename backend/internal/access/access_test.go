@@ -0,0 +1,150 @@
+package access
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/alarms"
+	"smart-door-lock/backend/internal/devices"
+	"smart-door-lock/backend/internal/mqttbus"
+	"smart-door-lock/backend/internal/notify"
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+func openAccessTestDB(t *testing.T) *gorm.DB {
+	db := storetest.Open(t, &store.DoorlockUser{}, &store.Attendance{}, &store.Alarm{}, &store.Door{},
+		&store.NotificationRule{}, &store.NotificationOutbox{}, &store.Occupancy{}, &store.CommandLog{})
+	db.Create(&store.DoorlockUser{Name: "Budi", AccessID: "A001", DoorID: "D01", Pin: "123456", IsActive: true})
+	return db
+}
+
+// newTestService merangkai Service tanpa broker MQTT dan tanpa channel
+// notifikasi.
+func newTestService(db *gorm.DB) *Service {
+	st := store.NewGorm(db)
+	dispatcher := notify.NewDispatcher(db, 0, 0)
+	alarmSvc := alarms.NewService(st, dispatcher, nil, 10*time.Minute, siteLoc)
+	tracker := devices.NewTracker(st, alarmSvc, dispatcher, siteLoc)
+	control := devices.NewController(st, mqttbus.New(nil))
+	return NewService(st, alarmSvc, control, tracker, NewOccupancy(db, st, siteLoc))
+}
+
+func TestDecideAccessRaisesAlarmAfterFailedAttempts(t *testing.T) {
+	db := openAccessTestDB(t)
+	svc := newTestService(db)
+	db.Create(&store.Door{DoorID: "D01", FailedAttemptLimit: 3, LockoutMinutes: 5})
+
+	for i := 0; i < 3; i++ {
+		rec, err := svc.Decide(Request{AccessID: "A001", DoorID: "D01", Pin: "000000", Arrow: "in"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.Status != "denied" || rec.Reason != DenyWrongPin {
+			t.Fatalf("attempt %d: status=%s reason=%s", i+1, rec.Status, rec.Reason)
+		}
+	}
+
+	var list []store.Alarm
+	db.Find(&list)
+	if len(list) != 1 || list[0].AlarmType != store.AlarmTypeFailedAttempts || list[0].DoorID != "D01" {
+		t.Fatalf("alarms = %+v, want one failed-attempt alarm on D01", list)
+	}
+
+	// kredensial dikunci, PIN benar pun ditolak
+	rec, _ := svc.Decide(Request{AccessID: "A001", DoorID: "D01", Pin: "123456", Arrow: "in"})
+	if rec.Reason != DenyLockedOut {
+		t.Fatalf("after lockout: reason=%q, want %q", rec.Reason, DenyLockedOut)
+	}
+}
+
+func TestFailedAttemptsWithRandomIDsDoNotLockOtherCredential(t *testing.T) {
+	db := openAccessTestDB(t)
+	svc := newTestService(db)
+	db.Create(&store.Door{DoorID: "D01", FailedAttemptLimit: 3, LockoutMinutes: 5})
+
+	// dua access_id acak lalu satu percobaan memakai access_id korban
+	for _, id := range []string{"X901", "X902", "A001"} {
+		if _, err := svc.Decide(Request{AccessID: id, DoorID: "D01", Pin: "000000", Arrow: "in"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var n int64
+	db.Model(&store.Alarm{}).Where("alarm_type = ?", store.AlarmTypeFailedAttempts).Count(&n)
+	if n != 1 {
+		t.Fatalf("failed-attempt alarms = %d, want 1 from the door counter", n)
+	}
+
+	// counter kredensial A001 baru satu, jadi A001 tidak dikunci
+	rec, _ := svc.Decide(Request{AccessID: "A001", DoorID: "D01", Pin: "123456", Arrow: "in"})
+	if rec.Status != "success" {
+		t.Fatalf("victim after random attempts: status=%s reason=%q, want success", rec.Status, rec.Reason)
+	}
+}
+
+func TestDecideAccessAntiPassback(t *testing.T) {
+	cases := []struct {
+		mode       string
+		wantStatus string
+	}{
+		{AntiPassbackHard, "denied"},
+		{AntiPassbackSoft, "success"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.mode, func(t *testing.T) {
+			db := openAccessTestDB(t)
+			svc := newTestService(db)
+			db.Create(&store.Door{DoorID: "D01", Zone: "Gedung A", AntiPassback: tc.mode})
+
+			first, _ := svc.Decide(Request{AccessID: "A001", DoorID: "D01", Arrow: "in"})
+			if first.Status != "success" || first.Reason != "" {
+				t.Fatalf("first entry: %+v", first)
+			}
+
+			second, _ := svc.Decide(Request{AccessID: "A001", DoorID: "D01", Arrow: "in"})
+			if second.Status != tc.wantStatus || second.Reason != DenyAntiPassback {
+				t.Fatalf("second entry: status=%s reason=%s", second.Status, second.Reason)
+			}
+
+			var alarm store.Alarm
+			if err := db.Where("alarm_type = ?", store.AlarmTypeAntiPassback).First(&alarm).Error; err != nil {
+				t.Fatalf("no anti-passback alarm: %v", err)
+			}
+
+			// keluar setelah masuk tidak melanggar
+			out, _ := svc.Decide(Request{AccessID: "A001", DoorID: "D01", Arrow: "out"})
+			if out.Status != "success" || out.Reason != "" {
+				t.Fatalf("exit after violation: %+v", out)
+			}
+		})
+	}
+}
+
+func TestDecideAccessLifecycle(t *testing.T) {
+	db := openAccessTestDB(t)
+	svc := newTestService(db)
+	past := time.Now().Add(-time.Minute)
+
+	for _, tc := range []struct {
+		name   string
+		update map[string]interface{}
+		reason string
+	}{
+		{"expired", map[string]interface{}{"expires_at": past}, DenyExpired},
+		{"suspended", map[string]interface{}{"expires_at": nil, "is_active": false, "suspended_at": past}, DenySuspended},
+		{"deleted", map[string]interface{}{"is_active": true, "suspended_at": nil, "deleted_at": past}, DenyUnknownAccessID},
+	} {
+		db.Unscoped().Model(&store.DoorlockUser{}).Where("access_id = ?", "A001").Updates(tc.update)
+		rec, err := svc.Decide(Request{AccessID: "A001", DoorID: "D01", Pin: "123456", Arrow: "in"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rec.Status != "denied" || rec.Reason != tc.reason {
+			t.Errorf("%s: status=%s reason=%q, want %q", tc.name, rec.Status, rec.Reason, tc.reason)
+		}
+	}
+}
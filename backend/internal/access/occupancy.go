@@ -0,0 +1,189 @@
+package access
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/devices"
+	"smart-door-lock/backend/internal/store"
+)
+
+const (
+	ExitReasonOut     = "out"
+	ExitReasonReentry = "reentry"
+	ExitReasonExpired = "expired"
+)
+
+// Occupancy memasangkan akses "in" dan "out" menjadi kunjungan untuk
+// menghitung siapa yang sedang berada di dalam.
+type Occupancy struct {
+	db    *gorm.DB
+	store store.Store
+	loc   *time.Location // batas hari di zona waktu situs
+}
+
+func NewOccupancy(db *gorm.DB, st store.Store, loc *time.Location) *Occupancy {
+	return &Occupancy{db: db, store: st, loc: loc}
+}
+
+// Record memperbarui kehadiran dari akses yang berhasil.
+func (o *Occupancy) Record(rec store.Attendance) {
+	at := rec.CreatedAt
+
+	switch rec.Arrow {
+	case "in":
+		o.close(rec.AccessID, at, rec.DoorID, ExitReasonReentry)
+		door := devices.LoadDoor(o.store, rec.DoorID)
+		occ := store.Occupancy{
+			AccessID:  rec.AccessID,
+			Username:  rec.Username,
+			DoorID:    rec.DoorID,
+			Zone:      door.Zone,
+			EnteredAt: at,
+			CreatedAt: time.Now(),
+		}
+		if err := o.db.Create(&occ).Error; err != nil {
+			log.Printf("Gagal menyimpan occupancy: %v", err)
+		}
+
+	case "out":
+		if o.close(rec.AccessID, at, rec.DoorID, ExitReasonOut) == 0 {
+			log.Printf("Occupancy: %s (%s) keluar tanpa catatan masuk", rec.Username, rec.AccessID)
+		}
+	}
+}
+
+func (o *Occupancy) close(accessID string, at time.Time, doorID, reason string) int64 {
+	res := o.db.Model(&store.Occupancy{}).
+		Where("access_id = ? AND exited_at IS NULL", accessID).
+		Updates(map[string]interface{}{
+			"exited_at":    at,
+			"exit_door_id": doorID,
+			"exit_reason":  reason,
+		})
+	if res.Error != nil {
+		log.Printf("Gagal menutup occupancy %s: %v", accessID, res.Error)
+	}
+	return res.RowsAffected
+}
+
+// Expire menutup kunjungan yang masuk sebelum hari ini tanpa "out". Waktu
+// keluar diisi akhir hari masuk.
+func (o *Occupancy) Expire(now time.Time) {
+	var stale []store.Occupancy
+	o.db.Where("exited_at IS NULL AND entered_at < ?", config.StartOfDay(now, o.loc)).Find(&stale)
+
+	for _, occ := range stale {
+		endOfDay := config.StartOfDay(occ.EnteredAt, o.loc).AddDate(0, 0, 1).Add(-time.Second)
+		o.db.Model(&store.Occupancy{}).Where("id = ?", occ.ID).Updates(map[string]interface{}{
+			"exited_at":   endOfDay,
+			"exit_reason": ExitReasonExpired,
+		})
+	}
+	if len(stale) > 0 {
+		log.Printf("Occupancy: %d kunjungan tanpa check-out di-expire", len(stale))
+	}
+}
+
+func (o *Occupancy) Run() {
+	o.Rebuild(time.Now())
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		o.Expire(time.Now())
+	}
+}
+
+// Rebuild mengisi tabel dari attendance hari ini bila tabel masih kosong
+// (misalnya setelah upgrade).
+func (o *Occupancy) Rebuild(now time.Time) {
+	var count int64
+	o.db.Model(&store.Occupancy{}).Count(&count)
+	if count > 0 {
+		return
+	}
+
+	var recs []store.Attendance
+	o.db.Where("status = ? AND created_at >= ?", "success", config.StartOfDay(now, o.loc)).Order("created_at").Find(&recs)
+	for _, rec := range recs {
+		o.Record(rec)
+	}
+	if len(recs) > 0 {
+		log.Printf("Occupancy dibangun ulang dari %d attendance hari ini", len(recs))
+	}
+}
+
+// ====== QUERIES ======
+
+type Report struct {
+	Total  int               `json:"total"`
+	ByDoor map[string]int    `json:"by_door"`
+	ByZone map[string]int    `json:"by_zone"`
+	People []store.Occupancy `json:"people"`
+}
+
+// Current mengembalikan siapa yang sedang di dalam. doorID bisa berupa ID
+// pintu atau nama zona; kosong berarti semua.
+func (o *Occupancy) Current(now time.Time, doorID string) (Report, error) {
+	o.Expire(now)
+
+	report := Report{
+		ByDoor: make(map[string]int),
+		ByZone: make(map[string]int),
+		People: []store.Occupancy{},
+	}
+
+	q := o.db.Where("exited_at IS NULL").Order("entered_at")
+	if doorID != "" {
+		q = q.Where("door_id = ? OR zone = ?", doorID, doorID)
+	}
+	if err := q.Find(&report.People).Error; err != nil {
+		return report, err
+	}
+
+	for _, occ := range report.People {
+		report.ByDoor[occ.DoorID]++
+		report.ByZone[occ.Zone]++
+	}
+	report.Total = len(report.People)
+	return report, nil
+}
+
+type HeadcountZone struct {
+	Zone   string            `json:"zone"`
+	Count  int               `json:"count"`
+	People []store.Occupancy `json:"people"`
+}
+
+type HeadcountReport struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	Total       int             `json:"total"`
+	Zones       []HeadcountZone `json:"zones"`
+}
+
+// Headcount mengelompokkan orang di dalam per zona, diurutkan per nama,
+// untuk dicocokkan di titik kumpul.
+func (o *Occupancy) Headcount(now time.Time) (HeadcountReport, error) {
+	occ, err := o.Current(now, "")
+	if err != nil {
+		return HeadcountReport{}, err
+	}
+
+	byZone := make(map[string][]store.Occupancy)
+	for _, p := range occ.People {
+		byZone[p.Zone] = append(byZone[p.Zone], p)
+	}
+
+	report := HeadcountReport{GeneratedAt: now, Total: occ.Total, Zones: []HeadcountZone{}}
+	for zone, people := range byZone {
+		sort.Slice(people, func(i, j int) bool { return people[i].Username < people[j].Username })
+		report.Zones = append(report.Zones, HeadcountZone{Zone: zone, Count: len(people), People: people})
+	}
+	sort.Slice(report.Zones, func(i, j int) bool { return report.Zones[i].Zone < report.Zones[j].Zone })
+	return report, nil
+}
@@ -0,0 +1,94 @@
+package access
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+// siteLoc adalah zona waktu situs bawaan (Asia/Jakarta) untuk semua tes.
+var siteLoc = config.Default().Location
+
+func openOccupancyTestDB(t *testing.T) (*Occupancy, *gorm.DB) {
+	db := storetest.Open(t, &store.Door{}, &store.Occupancy{}, &store.Attendance{})
+	return NewOccupancy(db, store.NewGorm(db), siteLoc), db
+}
+
+func TestOccupancyPairsInAndOut(t *testing.T) {
+	occ, db := openOccupancyTestDB(t)
+	db.Create(&store.Door{DoorID: "D01", Zone: "Lobby"})
+
+	loc := siteLoc
+	day := time.Date(2025, 10, 6, 8, 0, 0, 0, loc)
+	occ.Record(store.Attendance{AccessID: "A001", Username: "Budi", DoorID: "D01", Arrow: "in", CreatedAt: day})
+	occ.Record(store.Attendance{AccessID: "A002", Username: "Citra", DoorID: "D02", Arrow: "in", CreatedAt: day.Add(time.Minute)})
+	occ.Record(store.Attendance{AccessID: "A002", Username: "Citra", DoorID: "D02", Arrow: "out", CreatedAt: day.Add(time.Hour)})
+
+	report, err := occ.Current(day.Add(2*time.Hour), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Total != 1 || report.People[0].AccessID != "A001" {
+		t.Fatalf("inside = %+v, want only A001", report.People)
+	}
+	if report.ByZone["Lobby"] != 1 {
+		t.Errorf("by_zone = %v", report.ByZone)
+	}
+
+	byZone, _ := occ.Current(day.Add(2*time.Hour), "Lobby")
+	if byZone.Total != 1 {
+		t.Errorf("zone filter total = %d, want 1", byZone.Total)
+	}
+	byDoor, _ := occ.Current(day.Add(2*time.Hour), "D02")
+	if byDoor.Total != 0 {
+		t.Errorf("door D02 total = %d, want 0", byDoor.Total)
+	}
+}
+
+func TestOccupancyReentryClosesPreviousVisit(t *testing.T) {
+	occ, db := openOccupancyTestDB(t)
+
+	day := time.Date(2025, 10, 6, 8, 0, 0, 0, siteLoc)
+	occ.Record(store.Attendance{AccessID: "A001", Username: "Budi", DoorID: "D01", Arrow: "in", CreatedAt: day})
+	occ.Record(store.Attendance{AccessID: "A001", Username: "Budi", DoorID: "D01", Arrow: "in", CreatedAt: day.Add(time.Hour)})
+
+	var visits []store.Occupancy
+	db.Order("entered_at").Find(&visits)
+	if len(visits) != 2 {
+		t.Fatalf("got %d visits, want 2", len(visits))
+	}
+	if visits[0].ExitReason != ExitReasonReentry || visits[1].ExitedAt != nil {
+		t.Errorf("visits = %+v", visits)
+	}
+}
+
+func TestOccupancyExpiresAtEndOfDay(t *testing.T) {
+	occ, db := openOccupancyTestDB(t)
+
+	loc := siteLoc
+	entered := time.Date(2025, 10, 6, 9, 0, 0, 0, loc)
+	occ.Record(store.Attendance{AccessID: "A003", Username: "Dewi", DoorID: "D02", Arrow: "in", CreatedAt: entered})
+
+	// masih hari yang sama (23:30 WIB): belum expire
+	report, _ := occ.Current(time.Date(2025, 10, 6, 23, 30, 0, 0, loc), "")
+	if report.Total != 1 {
+		t.Fatalf("total before midnight = %d, want 1", report.Total)
+	}
+
+	report, _ = occ.Current(time.Date(2025, 10, 7, 0, 5, 0, 0, loc), "")
+	if report.Total != 0 {
+		t.Fatalf("total after midnight = %d, want 0", report.Total)
+	}
+
+	var visit store.Occupancy
+	db.First(&visit)
+	want := time.Date(2025, 10, 6, 23, 59, 59, 0, loc)
+	if visit.ExitReason != ExitReasonExpired || visit.ExitedAt == nil || !visit.ExitedAt.Equal(want) {
+		t.Errorf("expired visit = %+v, want exit at %v", visit, want)
+	}
+}
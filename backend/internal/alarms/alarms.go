@@ -0,0 +1,258 @@
+// Package alarms menyimpan alarm, mengelola siklus hidupnya (acknowledge,
+// assign, resolve) dan mengeskalasi alarm yang terlalu lama dibiarkan.
+package alarms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"smart-door-lock/backend/internal/mqttbus"
+	"smart-door-lock/backend/internal/store"
+)
+
+var (
+	ErrNotFound = errors.New("alarm not found")
+	ErrResolved = errors.New("alarm already resolved")
+	ErrConflict = errors.New("alarm changed concurrently")
+)
+
+// messageTimeLayout dipakai untuk waktu di isi notifikasi; singkatan zona
+// (WIB, WITA, ...) diambil dari zona waktu situs.
+const messageTimeLayout = "2 Jan 2006, 15:04:05 MST"
+
+// Berapa kali update dicoba ulang bila alarm diubah proses lain di antara
+// baca dan simpan.
+const updateAttempts = 3
+
+// Event notifikasi, sama dengan notify.Event*.
+const (
+	eventAlarm      = "alarm"
+	eventEscalation = "escalation"
+)
+
+// Notifier meneruskan alarm ke aturan notifikasi (dipenuhi
+// notify.Dispatcher) dan mengembalikan jumlah pesan yang diantrekan.
+type Notifier interface {
+	NotifyAlarm(event, subject, body string, al store.Alarm) int
+}
+
+// Service menyimpan alarm lalu mengirim notifikasi dan event MQTT.
+type Service struct {
+	store    store.Store
+	notifier Notifier
+	// bus boleh nil; event "alarms/new" tidak dikirim
+	bus           mqttbus.Bus
+	escalateAfter time.Duration
+	loc           *time.Location // zona waktu situs untuk isi pesan
+}
+
+func NewService(st store.Store, notifier Notifier, bus mqttbus.Bus, escalateAfter time.Duration, loc *time.Location) *Service {
+	return &Service{store: st, notifier: notifier, bus: bus, escalateAfter: escalateAfter, loc: loc}
+}
+
+func Reason(alarmType int) string {
+	switch alarmType {
+	case store.AlarmTypeFailedAttempts:
+		return "3 kali gagal masuk"
+	case store.AlarmTypeDoorOpenTooLong:
+		return "Pintu terbuka > 1 menit"
+	case store.AlarmTypeAntiPassback:
+		return "Anti-passback violation"
+	case store.AlarmTypeAnomaly:
+		return "Access anomaly"
+	}
+	return "Unknown"
+}
+
+func Severity(alarmType int) string {
+	switch alarmType {
+	case store.AlarmTypeFailedAttempts:
+		return store.SeverityHigh
+	case store.AlarmTypeDoorOpenTooLong:
+		return store.SeverityMedium
+	}
+	return store.SeverityMedium
+}
+
+// Raise menyimpan alarm lalu mengirim notifikasi dan event MQTT di
+// background, sama seperti alarm yang dikirim oleh perangkat.
+func (s *Service) Raise(al *store.Alarm) error {
+	if al.CreatedAt.IsZero() {
+		al.CreatedAt = time.Now()
+	}
+	if al.Reason == "" {
+		al.Reason = Reason(al.AlarmType)
+	}
+	if al.Severity == "" {
+		al.Severity = Severity(al.AlarmType)
+	}
+	al.Status = store.AlarmStatusOpen
+
+	if err := s.store.CreateAlarm(al); err != nil {
+		return fmt.Errorf("gagal menyimpan alarm: %w", err)
+	}
+
+	log.Printf("🚨 Alarm #%d: %s (%s) - %s", al.ID, al.Username, al.AccessID, al.Reason)
+
+	siteTime := al.CreatedAt.In(s.loc)
+
+	message := fmt.Sprintf(
+		"🚨 ALARM TERDETEKSI 🚨\n\nID: #%d\nNama: %s\nAccess ID: %s\nAlasan: %s\nSeverity: %s\nWaktu: %s",
+		al.ID,
+		al.Username,
+		al.AccessID,
+		al.Reason,
+		al.Severity,
+		siteTime.Format(messageTimeLayout),
+	)
+	if al.DoorID != "" {
+		message += fmt.Sprintf("\nPintu: %s", al.DoorID)
+	}
+	s.notifier.NotifyAlarm(eventAlarm, fmt.Sprintf("[%s] Alarm #%d: %s", al.Severity, al.ID, al.Reason), message, *al)
+
+	// Dashboard mendengarkan topic ini untuk auto-refresh
+	if s.bus != nil {
+		saved := *al
+		go func() {
+			if payload, err := json.Marshal(saved); err == nil && s.bus.Connected() {
+				if err := s.bus.Publish("alarms/new", string(payload)); err != nil {
+					log.Printf("⚠️ MQTT publish alarm gagal: %v", err)
+				}
+			}
+		}()
+	}
+
+	return nil
+}
+
+// appendNote menambahkan catatan bertanda waktu dan nama pengguna.
+func appendNote(notes, actor, note string, at time.Time) string {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return notes
+	}
+	line := fmt.Sprintf("[%s %s] %s", at.Format("2006-01-02 15:04"), actor, note)
+	if notes == "" {
+		return line
+	}
+	return notes + "\n" + line
+}
+
+// update memuat alarm yang belum resolved lalu menerapkan perubahan.
+// Penyimpanan bersyarat pada status yang dibaca, sehingga ack dan resolve
+// yang bersamaan tidak saling menimpa; pihak yang kalah membaca ulang
+// alarm dan mencoba lagi (lalu mendapat ErrResolved bila sudah selesai).
+func (s *Service) update(id uint, apply func(al *store.Alarm, now time.Time)) (store.Alarm, error) {
+	for attempt := 0; attempt < updateAttempts; attempt++ {
+		al, err := s.store.Alarm(id)
+		if err != nil {
+			if store.IsNotFound(err) {
+				return al, ErrNotFound
+			}
+			return al, err
+		}
+		if al.Status == store.AlarmStatusResolved {
+			return al, ErrResolved
+		}
+
+		status := al.Status
+		apply(&al, time.Now())
+		ok, err := s.store.UpdateAlarm(&al, status)
+		if err != nil {
+			return al, err
+		}
+		if ok {
+			return al, nil
+		}
+	}
+	return store.Alarm{}, ErrConflict
+}
+
+func (s *Service) Acknowledge(id uint, actor, note string) (store.Alarm, error) {
+	al, err := s.update(id, func(al *store.Alarm, now time.Time) {
+		if al.AcknowledgedAt == nil {
+			al.AcknowledgedBy = actor
+			al.AcknowledgedAt = &now
+		}
+		al.Status = store.AlarmStatusAcknowledged
+		al.Notes = appendNote(al.Notes, actor, note, now)
+	})
+	if err == nil {
+		log.Printf("Alarm #%d acknowledged by %s", id, actor)
+	}
+	return al, err
+}
+
+func (s *Service) Assign(id uint, actor, assignee, note string) (store.Alarm, error) {
+	al, err := s.update(id, func(al *store.Alarm, now time.Time) {
+		al.AssignedTo = assignee
+		al.Notes = appendNote(al.Notes, actor, note, now)
+	})
+	if err == nil {
+		log.Printf("Alarm #%d assigned to %s by %s", id, assignee, actor)
+	}
+	return al, err
+}
+
+func (s *Service) Resolve(id uint, actor, note string) (store.Alarm, error) {
+	al, err := s.update(id, func(al *store.Alarm, now time.Time) {
+		if al.AcknowledgedAt == nil {
+			al.AcknowledgedBy = actor
+			al.AcknowledgedAt = &now
+		}
+		al.Status = store.AlarmStatusResolved
+		al.ResolvedBy = actor
+		al.ResolvedAt = &now
+		al.Notes = appendNote(al.Notes, actor, note, now)
+	})
+	if err == nil {
+		log.Printf("Alarm #%d resolved by %s", id, actor)
+	}
+	return al, err
+}
+
+// ====== ESCALATION ======
+
+// RunEscalation mengirim alarm yang belum di-acknowledge setelah
+// escalateAfter ke target notifikasi kedua (aturan event "escalation").
+func (s *Service) RunEscalation() {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.Escalate(time.Now())
+	}
+}
+
+func (s *Service) Escalate(now time.Time) {
+	list, err := s.store.EscalationDue(now.Add(-s.escalateAfter))
+	if err != nil {
+		log.Printf("Gagal memuat alarm untuk eskalasi: %v", err)
+		return
+	}
+
+	loc := s.loc
+	for _, al := range list {
+		message := fmt.Sprintf(
+			"⏫ ESKALASI ALARM ⏫\n\nAlarm #%d belum di-acknowledge selama %s\nNama: %s\nAccess ID: %s\nPintu: %s\nAlasan: %s\nSeverity: %s\nWaktu: %s",
+			al.ID,
+			now.Sub(al.CreatedAt).Round(time.Minute),
+			al.Username,
+			al.AccessID,
+			al.DoorID,
+			al.Reason,
+			al.Severity,
+			al.CreatedAt.In(loc).Format(messageTimeLayout),
+		)
+		subject := fmt.Sprintf("[%s] Eskalasi alarm #%d: %s", al.Severity, al.ID, al.Reason)
+		if s.notifier.NotifyAlarm(eventEscalation, subject, message, al) == 0 {
+			log.Printf("Tidak ada target eskalasi untuk alarm #%d", al.ID)
+		}
+		if err := s.store.MarkEscalated(al.ID, now); err != nil {
+			log.Printf("Gagal menandai eskalasi alarm #%d: %v", al.ID, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package alarms
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+type nopNotifier struct{}
+
+func (nopNotifier) NotifyAlarm(event, subject, body string, al store.Alarm) int { return 0 }
+
+type bodyRecorder struct{ bodies []string }
+
+func (r *bodyRecorder) NotifyAlarm(event, subject, body string, al store.Alarm) int {
+	r.bodies = append(r.bodies, body)
+	return 1
+}
+
+func TestRaiseUsesSiteZoneAbbreviation(t *testing.T) {
+	db := storetest.Open(t, &store.Alarm{})
+	rec := &bodyRecorder{}
+	wita := time.FixedZone("WITA", 8*60*60)
+	svc := NewService(store.NewGorm(db), rec, nil, time.Hour, wita)
+
+	al := store.Alarm{AlarmType: store.AlarmTypeFailedAttempts, CreatedAt: time.Date(2025, 10, 6, 1, 0, 0, 0, time.UTC)}
+	if err := svc.Raise(&al); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.bodies) != 1 || !strings.Contains(rec.bodies[0], "Waktu: 6 Oct 2025, 09:00:00 WITA") {
+		t.Errorf("body = %q, want site time in WITA", rec.bodies)
+	}
+}
+
+// racingStore menjalankan between setelah pembacaan alarm pertama, meniru
+// request lain yang menyimpan di antara baca dan simpan.
+type racingStore struct {
+	store.Store
+	between func()
+}
+
+func (s *racingStore) Alarm(id uint) (store.Alarm, error) {
+	al, err := s.Store.Alarm(id)
+	if s.between != nil {
+		between := s.between
+		s.between = nil
+		between()
+	}
+	return al, err
+}
+
+func TestAcknowledgeLosesRaceToResolve(t *testing.T) {
+	db := storetest.Open(t, &store.Alarm{})
+	st := &racingStore{Store: store.NewGorm(db)}
+	svc := NewService(st, nopNotifier{}, nil, time.Hour, time.UTC)
+
+	al := store.Alarm{AlarmType: store.AlarmTypeFailedAttempts, Username: "Budi"}
+	if err := svc.Raise(&al); err != nil {
+		t.Fatal(err)
+	}
+
+	st.between = func() {
+		if _, err := NewService(store.NewGorm(db), nopNotifier{}, nil, time.Hour, time.UTC).Resolve(al.ID, "citra", "selesai"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := svc.Acknowledge(al.ID, "budi", "dicek"); !errors.Is(err, ErrResolved) {
+		t.Fatalf("ack after concurrent resolve: err = %v, want ErrResolved", err)
+	}
+
+	got, _ := st.Store.Alarm(al.ID)
+	if got.Status != store.AlarmStatusResolved || got.ResolvedBy != "citra" || got.AcknowledgedBy != "citra" {
+		t.Errorf("alarm = %+v, want resolved by citra", got)
+	}
+}
+
+func TestAssignRetriesAfterConcurrentAck(t *testing.T) {
+	db := storetest.Open(t, &store.Alarm{})
+	st := &racingStore{Store: store.NewGorm(db)}
+	svc := NewService(st, nopNotifier{}, nil, time.Hour, time.UTC)
+
+	al := store.Alarm{AlarmType: store.AlarmTypeFailedAttempts, Username: "Budi"}
+	if err := svc.Raise(&al); err != nil {
+		t.Fatal(err)
+	}
+
+	st.between = func() {
+		if _, err := NewService(store.NewGorm(db), nopNotifier{}, nil, time.Hour, time.UTC).Acknowledge(al.ID, "citra", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := svc.Assign(al.ID, "budi", "dewi", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// assign dibaca ulang sehingga ack dari request lain tidak tertimpa
+	if got.Status != store.AlarmStatusAcknowledged || got.AcknowledgedBy != "citra" || got.AssignedTo != "dewi" {
+		t.Errorf("alarm = %+v, want acknowledged by citra and assigned to dewi", got)
+	}
+}
@@ -0,0 +1,264 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/store"
+)
+
+// Ukuran bucket time series
+const (
+	BucketMinute = "minute"
+	BucketHour   = "hour"
+	BucketDay    = "day"
+	BucketWeek   = "week"
+)
+
+// maxTimeseriesBuckets membatasi jumlah bucket per request agar bucket
+// menit tidak diminta untuk rentang berbulan-bulan.
+const maxTimeseriesBuckets = 5000
+
+// Dimensi yang bisa dipakai untuk memecah series
+var analyticsSplits = map[string]func(store.Attendance) string{
+	"arrow":  func(a store.Attendance) string { return a.Arrow },
+	"door":   func(a store.Attendance) string { return a.DoorID },
+	"status": func(a store.Attendance) string { return a.Status },
+}
+
+// Urutan hari untuk heatmap, dimulai Senin
+var heatmapWeekdays = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+func bucketStart(bucket string, t time.Time, loc *time.Location) time.Time {
+	if bucket == BucketMinute {
+		local := t.In(loc)
+		return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), 0, 0, local.Location())
+	}
+	return PeriodStart(bucket, t, loc)
+}
+
+func bucketNext(bucket string, start time.Time) time.Time {
+	if bucket == BucketMinute {
+		return start.Add(time.Minute)
+	}
+	return PeriodEnd(bucket, start)
+}
+
+// Filter adalah filter attendance untuk endpoint analytics.
+type Filter struct {
+	From     time.Time
+	To       time.Time
+	DoorID   string
+	AccessID string
+	Status   string
+	Arrow    string
+}
+
+// eachAttendance men-stream attendance yang cocok dengan filter.
+func eachAttendance(db *gorm.DB, f Filter, fn func(store.Attendance)) error {
+	q := db.Model(&store.Attendance{}).Select("id, access_id, door_id, status, arrow, created_at").
+		Where("created_at >= ? AND created_at < ?", f.From, f.To)
+	for col, v := range map[string]string{"door_id": f.DoorID, "access_id": f.AccessID, "status": f.Status, "arrow": f.Arrow} {
+		if v != "" {
+			q = q.Where(col+" = ?", v)
+		}
+	}
+	rows, err := q.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var a store.Attendance
+		if err := db.ScanRows(rows, &a); err != nil {
+			return err
+		}
+		fn(a)
+	}
+	return rows.Err()
+}
+
+type TimeseriesSeries struct {
+	Key    string            `json:"key"`
+	Labels map[string]string `json:"labels"`
+	Counts []int             `json:"counts"`
+	Total  int               `json:"total"`
+}
+
+type Timeseries struct {
+	Bucket   string             `json:"bucket"`
+	Timezone string             `json:"timezone"`
+	Split    []string           `json:"split"`
+	Buckets  []time.Time        `json:"buckets"`
+	Series   []TimeseriesSeries `json:"series"`
+	Total    int                `json:"total"`
+}
+
+// ParseSplit memvalidasi parameter split=arrow,door,status.
+func ParseSplit(s string) ([]string, error) {
+	split := []string{}
+	if s == "" || s == "none" {
+		return split, nil
+	}
+	for _, dim := range strings.Split(s, ",") {
+		if _, ok := analyticsSplits[dim]; !ok {
+			return nil, fmt.Errorf("cannot split by %q, use arrow, door or status", dim)
+		}
+		split = append(split, dim)
+	}
+	return split, nil
+}
+
+// BuildTimeseries menghitung jumlah attendance per bucket (zona waktu situs
+// loc) dengan nilai nol untuk bucket kosong agar langsung bisa digambar.
+func BuildTimeseries(db *gorm.DB, f Filter, bucket string, split []string, loc *time.Location) (Timeseries, error) {
+	ts := Timeseries{Bucket: bucket, Timezone: loc.String(), Split: split, Buckets: []time.Time{}, Series: []TimeseriesSeries{}}
+
+	index := make(map[int64]int)
+	for t := bucketStart(bucket, f.From, loc); t.Before(f.To); t = bucketNext(bucket, t) {
+		if len(ts.Buckets) >= maxTimeseriesBuckets {
+			return ts, fmt.Errorf("too many buckets, use a larger bucket or a shorter range")
+		}
+		index[t.Unix()] = len(ts.Buckets)
+		ts.Buckets = append(ts.Buckets, t)
+	}
+
+	series := make(map[string]*TimeseriesSeries)
+	err := eachAttendance(db, f, func(a store.Attendance) {
+		labels := make(map[string]string, len(split))
+		parts := make([]string, len(split))
+		for i, dim := range split {
+			v := analyticsSplits[dim](a)
+			labels[dim] = v
+			parts[i] = v
+		}
+		key := strings.Join(parts, "|")
+		if len(split) == 0 {
+			key = "all"
+		}
+		s, ok := series[key]
+		if !ok {
+			s = &TimeseriesSeries{Key: key, Labels: labels, Counts: make([]int, len(ts.Buckets))}
+			series[key] = s
+		}
+		if i, ok := index[bucketStart(bucket, a.CreatedAt, loc).Unix()]; ok {
+			s.Counts[i]++
+			s.Total++
+			ts.Total++
+		}
+	})
+	if err != nil {
+		return ts, err
+	}
+
+	for _, s := range series {
+		ts.Series = append(ts.Series, *s)
+	}
+	sort.Slice(ts.Series, func(i, j int) bool { return ts.Series[i].Key < ts.Series[j].Key })
+	return ts, nil
+}
+
+// DayCount adalah jumlah attendance pada satu tanggal situs.
+type DayCount struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// DailyCounts menghitung attendance per tanggal (zona waktu situs) untuk
+// tanggal yang ada datanya, terbaru dulu. Pengelompokan dilakukan di Go
+// supaya tidak bergantung fungsi tanggal milik dialect SQL tertentu.
+func DailyCounts(db *gorm.DB, from, to time.Time, loc *time.Location) ([]DayCount, error) {
+	ts, err := BuildTimeseries(db, Filter{From: from, To: to}, BucketDay, nil, loc)
+	if err != nil {
+		return nil, err
+	}
+	out := []DayCount{}
+	for _, s := range ts.Series {
+		for i := len(ts.Buckets) - 1; i >= 0; i-- {
+			if s.Counts[i] > 0 {
+				out = append(out, DayCount{Date: ts.Buckets[i].Format("2006-01-02"), Count: s.Counts[i]})
+			}
+		}
+	}
+	return out, nil
+}
+
+type PeakHour struct {
+	Hour      int     `json:"hour"`
+	Count     int     `json:"count"`
+	AvgPerDay float64 `json:"avg_per_day"`
+}
+
+type WeekdayPeak struct {
+	Weekday string `json:"weekday"`
+	Hour    int    `json:"hour"`
+	Count   int    `json:"count"`
+	Total   int    `json:"total"`
+}
+
+type Heatmap struct {
+	Timezone      string        `json:"timezone"`
+	Weekdays      []string      `json:"weekdays"`
+	Matrix        [7][24]int    `json:"matrix"` // [weekday][hour]
+	Total         int           `json:"total"`
+	PeakHours     []PeakHour    `json:"peak_hours"`
+	PeakByWeekday []WeekdayPeak `json:"peak_by_weekday"`
+	BusiestDay    string        `json:"busiest_weekday"`
+}
+
+// BuildHeatmap menghitung matriks jam x hari (Senin = baris 0) beserta
+// jam tersibuk. top membatasi jumlah peak_hours.
+func BuildHeatmap(db *gorm.DB, f Filter, top int, loc *time.Location) (Heatmap, error) {
+	hm := Heatmap{Timezone: loc.String(), Weekdays: heatmapWeekdays}
+	err := eachAttendance(db, f, func(a store.Attendance) {
+		at := a.CreatedAt.In(loc)
+		hm.Matrix[(int(at.Weekday())+6)%7][at.Hour()]++
+		hm.Total++
+	})
+	if err != nil {
+		return hm, err
+	}
+
+	days := int(f.To.Sub(f.From).Hours()/24 + 0.5)
+	if days < 1 {
+		days = 1
+	}
+	var hours [24]int
+	busiest := -1
+	for d := 0; d < 7; d++ {
+		peak := WeekdayPeak{Weekday: heatmapWeekdays[d]}
+		for h := 0; h < 24; h++ {
+			n := hm.Matrix[d][h]
+			hours[h] += n
+			peak.Total += n
+			if n > peak.Count {
+				peak.Count, peak.Hour = n, h
+			}
+		}
+		hm.PeakByWeekday = append(hm.PeakByWeekday, peak)
+		if peak.Total > 0 && (busiest < 0 || peak.Total > hm.PeakByWeekday[busiest].Total) {
+			busiest = d
+		}
+	}
+	if busiest >= 0 {
+		hm.BusiestDay = heatmapWeekdays[busiest]
+	}
+
+	for h, n := range hours {
+		if n > 0 {
+			hm.PeakHours = append(hm.PeakHours, PeakHour{Hour: h, Count: n, AvgPerDay: float64(n) / float64(days)})
+		}
+	}
+	sort.SliceStable(hm.PeakHours, func(i, j int) bool { return hm.PeakHours[i].Count > hm.PeakHours[j].Count })
+	if top > 0 && len(hm.PeakHours) > top {
+		hm.PeakHours = hm.PeakHours[:top]
+	}
+	if hm.PeakHours == nil {
+		hm.PeakHours = []PeakHour{}
+	}
+	return hm, nil
+}
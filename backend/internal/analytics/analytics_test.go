@@ -0,0 +1,70 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+// siteLoc adalah zona waktu situs bawaan (Asia/Jakarta) untuk semua tes.
+var siteLoc = config.Default().Location
+
+func TestBuildTimeseriesSplitByArrow(t *testing.T) {
+	db := storetest.Open(t, &store.Attendance{})
+
+	loc := siteLoc
+	at := func(day, hour int) time.Time { return time.Date(2025, 10, day, hour, 0, 0, 0, loc) }
+	db.Create(&store.Attendance{AccessID: "A001", DoorID: "D01", Arrow: "in", Status: "success", CreatedAt: at(6, 8)})
+	db.Create(&store.Attendance{AccessID: "A002", DoorID: "D01", Arrow: "in", Status: "success", CreatedAt: at(6, 9)})
+	db.Create(&store.Attendance{AccessID: "A001", DoorID: "D01", Arrow: "out", Status: "success", CreatedAt: at(6, 17)})
+	// 23:30 WIB masih tanggal 7, walau di UTC sudah berbeda jam
+	db.Create(&store.Attendance{AccessID: "A001", DoorID: "D02", Arrow: "in", Status: "success",
+		CreatedAt: time.Date(2025, 10, 7, 23, 30, 0, 0, loc)})
+
+	f := Filter{From: at(6, 0), To: at(8, 0)}
+	ts, err := BuildTimeseries(db, f, BucketDay, []string{"arrow"}, siteLoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts.Buckets) != 2 || len(ts.Series) != 2 || ts.Total != 4 {
+		t.Fatalf("Timeseries = %+v", ts)
+	}
+	in := ts.Series[0]
+	if in.Key != "in" || in.Counts[0] != 2 || in.Counts[1] != 1 {
+		t.Errorf("in series = %+v", in)
+	}
+
+	hourly, _ := BuildTimeseries(db, Filter{From: at(6, 0), To: at(7, 0)}, BucketHour, nil, siteLoc)
+	if len(hourly.Buckets) != 24 || hourly.Series[0].Counts[8] != 1 || hourly.Series[0].Counts[17] != 1 {
+		t.Errorf("hourly = %+v", hourly.Series)
+	}
+
+	if _, err := BuildTimeseries(db, Filter{From: at(1, 0), To: at(30, 0)}, BucketMinute, nil, siteLoc); err == nil {
+		t.Error("expected error for too many minute buckets")
+	}
+}
+
+func TestBuildHeatmapPeaks(t *testing.T) {
+	db := storetest.Open(t, &store.Attendance{})
+
+	loc := siteLoc
+	monday := time.Date(2025, 10, 6, 0, 0, 0, 0, loc)
+	for i := 0; i < 3; i++ {
+		db.Create(&store.Attendance{AccessID: "A001", Arrow: "in", Status: "success", CreatedAt: monday.Add(8*time.Hour + time.Duration(i)*time.Minute)})
+	}
+	db.Create(&store.Attendance{AccessID: "A001", Arrow: "out", Status: "success", CreatedAt: monday.AddDate(0, 0, 6).Add(17 * time.Hour)})
+
+	hm, err := BuildHeatmap(db, Filter{From: monday, To: monday.AddDate(0, 0, 7)}, 1, siteLoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hm.Matrix[0][8] != 3 || hm.Matrix[6][17] != 1 {
+		t.Errorf("matrix mon 08 = %d, sun 17 = %d", hm.Matrix[0][8], hm.Matrix[6][17])
+	}
+	if len(hm.PeakHours) != 1 || hm.PeakHours[0].Hour != 8 || hm.BusiestDay != "Mon" {
+		t.Errorf("peaks = %+v busiest = %s", hm.PeakHours, hm.BusiestDay)
+	}
+}
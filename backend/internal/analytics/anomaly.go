@@ -0,0 +1,261 @@
+package analytics
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/alarms"
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/store"
+)
+
+// Jenis temuan anomali
+const (
+	AnomalyFirstTimeDoor = "first_time_door"
+	AnomalyOffHours      = "off_hours"
+	AnomalySpike         = "frequency_spike"
+)
+
+// AnomalyConfig adalah ambang batas satu kali deteksi.
+type AnomalyConfig struct {
+	BaselineDays  int     `json:"baseline_days"`
+	MinHistory    int     `json:"min_history"`
+	HourTolerance int     `json:"hour_tolerance"`
+	SpikeFactor   float64 `json:"spike_factor"`
+	SpikeMin      int     `json:"spike_min"`
+}
+
+// AnomalyDefaults mengambil ambang batas dari konfigurasi backend.
+func AnomalyDefaults(c config.AnomalyConfig) AnomalyConfig {
+	return AnomalyConfig{
+		BaselineDays:  c.BaselineDays,
+		MinHistory:    c.MinHistory,
+		HourTolerance: c.HourTolerance,
+		SpikeFactor:   c.SpikeFactor,
+		SpikeMin:      c.SpikeMin,
+	}
+}
+
+// userBaseline adalah pola akses normal satu orang.
+type userBaseline struct {
+	Events    int
+	Hours     [24]int
+	Doors     map[string]int
+	Days      int
+	DailyMean float64
+}
+
+func (b *userBaseline) usualHour(hour, tolerance int) bool {
+	for d := -tolerance; d <= tolerance; d++ {
+		if b.Hours[((hour+d)%24+24)%24] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// buildBaselines menyusun baseline dari rollup per jam pada rentang
+// [before - BaselineDays, before).
+func buildBaselines(db *gorm.DB, before time.Time, cfg AnomalyConfig, loc *time.Location) (map[string]*userBaseline, error) {
+	var rows []store.AccessFrequency
+	if err := db.Where("time_frame = ? AND period_start >= ? AND period_start < ?",
+		TimeFrameHour, config.StartOfDay(before, loc).AddDate(0, 0, -cfg.BaselineDays), before).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	baselines := make(map[string]*userBaseline)
+	days := make(map[string]map[string]bool)
+	for _, r := range rows {
+		b, ok := baselines[r.AccessID]
+		if !ok {
+			b = &userBaseline{Doors: make(map[string]int)}
+			baselines[r.AccessID] = b
+			days[r.AccessID] = make(map[string]bool)
+		}
+		start := r.PeriodStart.In(loc)
+		b.Events += r.AccessCount
+		b.Hours[start.Hour()] += r.AccessCount
+		b.Doors[r.DoorID] += r.AccessCount
+		days[r.AccessID][start.Format("2006-01-02")] = true
+	}
+	for id, b := range baselines {
+		b.Days = len(days[id])
+		if b.Days > 0 {
+			b.DailyMean = float64(b.Events) / float64(b.Days)
+		}
+	}
+	return baselines, nil
+}
+
+// Finding adalah satu penyimpangan dari baseline.
+type Finding struct {
+	Type     string    `json:"type"`
+	AccessID string    `json:"access_id"`
+	Username string    `json:"username"`
+	DoorID   string    `json:"door_id,omitempty"`
+	Time     time.Time `json:"time"`
+	Detail   string    `json:"detail"`
+	Count    int       `json:"count,omitempty"`
+	Baseline float64   `json:"baseline,omitempty"`
+	// Key sama untuk temuan yang sama di setiap pemindaian (jenis,
+	// access_id dan pintu atau tanggal), dipakai untuk dedup alarm.
+	Key string `json:"key"`
+}
+
+// Detector membandingkan akses dengan baseline dari rollup per jam; zona
+// waktu situs diambil dari rollups.
+type Detector struct {
+	db      *gorm.DB
+	rollups *Rollups
+	alarms  *alarms.Service
+}
+
+func NewDetector(db *gorm.DB, rollups *Rollups, alarmSvc *alarms.Service) *Detector {
+	return &Detector{db: db, rollups: rollups, alarms: alarmSvc}
+}
+
+// Detect membandingkan akses sukses di [from, to) dengan baseline
+// sebelum from. User dengan riwayat kurang dari MinHistory dilewati.
+func (d *Detector) Detect(from, to time.Time, cfg AnomalyConfig) ([]Finding, error) {
+	db := d.db
+	if err := d.rollups.Pending(); err != nil {
+		return nil, err
+	}
+	baselines, err := buildBaselines(db, from, cfg, d.rollups.loc)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []store.Attendance
+	if err := db.Where("status = ? AND created_at >= ? AND created_at < ?", "success", from, to).
+		Order("created_at").Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	loc := d.rollups.loc
+	findings := []Finding{}
+	seen := make(map[string]bool)
+	type dayKey struct{ accessID, date string }
+	daily := make(map[dayKey][]store.Attendance)
+
+	for _, ev := range events {
+		b := baselines[ev.AccessID]
+		if b == nil || b.Events < cfg.MinHistory {
+			continue
+		}
+		at := ev.CreatedAt.In(loc)
+		date := at.Format("2006-01-02")
+		daily[dayKey{ev.AccessID, date}] = append(daily[dayKey{ev.AccessID, date}], ev)
+
+		if ev.DoorID != "" && b.Doors[ev.DoorID] == 0 {
+			key := findingKey(AnomalyFirstTimeDoor, ev.AccessID, ev.DoorID)
+			if !seen[key] {
+				seen[key] = true
+				findings = append(findings, Finding{Type: AnomalyFirstTimeDoor, AccessID: ev.AccessID,
+					Username: ev.Username, DoorID: ev.DoorID, Time: at, Key: key,
+					Detail: fmt.Sprintf("Akses pertama ke pintu %s", ev.DoorID)})
+			}
+		}
+
+		if !b.usualHour(at.Hour(), cfg.HourTolerance) {
+			key := findingKey(AnomalyOffHours, ev.AccessID, date)
+			if !seen[key] {
+				seen[key] = true
+				findings = append(findings, Finding{Type: AnomalyOffHours, AccessID: ev.AccessID,
+					Username: ev.Username, DoorID: ev.DoorID, Time: at, Key: key,
+					Detail: fmt.Sprintf("Akses di luar jam biasa (%s, %s)", date, at.Format("15:04"))})
+			}
+		}
+	}
+
+	for key, evs := range daily {
+		b := baselines[key.accessID]
+		n := len(evs)
+		if n >= cfg.SpikeMin && float64(n) > cfg.SpikeFactor*b.DailyMean {
+			last := evs[n-1]
+			findings = append(findings, Finding{Type: AnomalySpike, AccessID: key.accessID,
+				Username: last.Username, Time: last.CreatedAt.In(loc), Count: n, Baseline: b.DailyMean,
+				Key:    findingKey(AnomalySpike, key.accessID, key.date),
+				Detail: fmt.Sprintf("Lonjakan akses: %d kali pada %s (rata-rata %.1f/hari)", n, key.date, b.DailyMean)})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Time.Before(findings[j].Time) })
+	return findings, nil
+}
+
+// findingKey membuat Finding.Key; detail seperti jumlah akses sengaja
+// tidak ikut supaya pemindaian berikutnya menghasilkan kunci yang sama.
+func findingKey(kind, accessID, scope string) string {
+	return "anomaly:" + kind + "|" + accessID + "|" + scope
+}
+
+func anomalySeverity(kind string) string {
+	if kind == AnomalyOffHours {
+		return store.SeverityLow
+	}
+	return store.SeverityMedium
+}
+
+// RaiseAlarms membuat alarm untuk temuan yang belum pernah dibuatkan alarm
+// (dicek dari Finding.Key yang disimpan di dedup_key).
+func (d *Detector) RaiseAlarms(findings []Finding) int {
+	raised := 0
+	for _, f := range findings {
+		var exists int64
+		err := d.db.Model(&store.Alarm{}).Where("alarm_type = ? AND dedup_key = ?",
+			store.AlarmTypeAnomaly, f.Key).Count(&exists).Error
+		if err != nil {
+			log.Printf("⚠️ Failed to check anomaly alarm %s: %v", f.Key, err)
+			continue
+		}
+		if exists > 0 {
+			continue
+		}
+		al := store.Alarm{
+			AlarmType: store.AlarmTypeAnomaly,
+			Username:  f.Username,
+			AccessID:  f.AccessID,
+			DoorID:    f.DoorID,
+			Reason:    f.Detail,
+			Severity:  anomalySeverity(f.Type),
+			DedupKey:  f.Key,
+		}
+		if err := d.alarms.Raise(&al); err != nil {
+			log.Printf("⚠️ Failed to raise anomaly alarm: %v", err)
+			continue
+		}
+		raised++
+	}
+	return raised
+}
+
+// Run memindai akses hari ini secara berkala dan membuat alarm bila
+// ANOMALY_RAISE_ALARMS aktif dan ANOMALY_SCAN_INTERVAL > 0.
+func (d *Detector) Run(cfg config.AnomalyConfig) {
+	if !cfg.RaiseAlarms {
+		return
+	}
+	if cfg.ScanInterval <= 0 {
+		log.Printf("⏸️ Anomaly scan dinonaktifkan (ANOMALY_SCAN_INTERVAL=%s)", cfg.ScanInterval)
+		return
+	}
+	ticker := time.NewTicker(cfg.ScanInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		now := time.Now()
+		findings, err := d.Detect(config.StartOfDay(now, d.rollups.loc), now, AnomalyDefaults(cfg))
+		if err != nil {
+			log.Printf("⚠️ Anomaly scan failed: %v", err)
+			continue
+		}
+		if n := d.RaiseAlarms(findings); n > 0 {
+			log.Printf("🔎 %d anomaly alarm(s) raised", n)
+		}
+	}
+}
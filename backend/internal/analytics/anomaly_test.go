@@ -0,0 +1,79 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"smart-door-lock/backend/internal/alarms"
+	"smart-door-lock/backend/internal/notify"
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	db := storetest.Open(t, &store.Attendance{}, &store.AccessFrequency{}, &store.RollupState{}, &store.Alarm{},
+		&store.NotificationRule{}, &store.NotificationOutbox{})
+
+	loc := siteLoc
+	// baseline: Budi masuk D01 setiap pagi jam 08 selama 10 hari
+	for day := 1; day <= 10; day++ {
+		db.Create(&store.Attendance{AccessID: "A001", Username: "Budi", DoorID: "D01", Arrow: "in", Status: "success",
+			CreatedAt: time.Date(2025, 9, 20+day, 8, 0, 0, 0, loc)})
+	}
+
+	today := time.Date(2025, 10, 6, 0, 0, 0, 0, loc)
+	db.Create(&store.Attendance{AccessID: "A001", Username: "Budi", DoorID: "D02", Arrow: "in", Status: "success",
+		CreatedAt: today.Add(8*time.Hour + 30*time.Minute)})
+	db.Create(&store.Attendance{AccessID: "A001", Username: "Budi", DoorID: "D01", Arrow: "in", Status: "success",
+		CreatedAt: today.Add(2 * time.Hour)})
+	for i := 0; i < 4; i++ {
+		db.Create(&store.Attendance{AccessID: "A001", Username: "Budi", DoorID: "D01", Arrow: "in", Status: "success",
+			CreatedAt: today.Add(9*time.Hour + time.Duration(i)*time.Minute)})
+	}
+
+	st := store.NewGorm(db)
+	d := NewDetector(db, NewRollups(db, siteLoc), alarms.NewService(st, notify.NewDispatcher(db, 0, 0), nil, time.Hour, siteLoc))
+
+	cfg := AnomalyConfig{BaselineDays: 30, MinHistory: 5, HourTolerance: 1, SpikeFactor: 3, SpikeMin: 5}
+	findings, err := d.Detect(today, today.AddDate(0, 0, 1), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]int{}
+	for _, f := range findings {
+		got[f.Type]++
+	}
+	if got[AnomalyFirstTimeDoor] != 1 || got[AnomalyOffHours] != 1 || got[AnomalySpike] != 1 {
+		t.Fatalf("findings = %+v", findings)
+	}
+
+	// batas spike lebih longgar: tidak ada lonjakan
+	cfg.SpikeMin = 20
+	findings, _ = d.Detect(today, today.AddDate(0, 0, 1), cfg)
+	for _, f := range findings {
+		if f.Type == AnomalySpike {
+			t.Errorf("unexpected spike with spike_min=20: %+v", f)
+		}
+	}
+
+	if n := d.RaiseAlarms(findings); n != len(findings) {
+		t.Errorf("raised %d alarms, want %d", n, len(findings))
+	}
+	if n := d.RaiseAlarms(findings); n != 0 {
+		t.Errorf("raised %d duplicate alarms", n)
+	}
+
+	// spike dibuat sekali per hari walau jumlahnya terus naik
+	cfg.SpikeMin = 5
+	findings, _ = d.Detect(today, today.AddDate(0, 0, 1), cfg)
+	if n := d.RaiseAlarms(findings); n != 1 {
+		t.Errorf("raised %d spike alarms, want 1", n)
+	}
+	db.Create(&store.Attendance{AccessID: "A001", Username: "Budi", DoorID: "D01", Arrow: "in", Status: "success",
+		CreatedAt: today.Add(9*time.Hour + 30*time.Minute)})
+	findings, _ = d.Detect(today, today.AddDate(0, 0, 1), cfg)
+	if n := d.RaiseAlarms(findings); n != 0 {
+		t.Errorf("raised %d alarms after spike count grew, want 0", n)
+	}
+}
@@ -0,0 +1,258 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/store"
+)
+
+type ReportOptions struct {
+	From       time.Time // inklusif, awal hari
+	To         time.Time // eksklusif, awal hari setelah tanggal akhir
+	GroupBy    string    // user, door
+	ShiftStart time.Duration
+	Grace      time.Duration
+	AccessID   string
+	DoorID     string
+	Location   *time.Location // zona waktu situs untuk batas hari
+}
+
+// AttendanceDay adalah ringkasan satu orang dalam satu hari.
+type AttendanceDay struct {
+	Date            string     `json:"date"`
+	AccessID        string     `json:"access_id"`
+	Username        string     `json:"username"`
+	DoorID          string     `json:"door_id"` // pintu first in
+	FirstIn         *time.Time `json:"first_in"`
+	LastOut         *time.Time `json:"last_out"`
+	TotalSeconds    int64      `json:"total_seconds"`
+	TotalTime       string     `json:"total_time"`
+	Late            bool       `json:"late"`
+	LateMinutes     int        `json:"late_minutes"`
+	MissingCheckout bool       `json:"missing_checkout"`
+}
+
+type AttendanceGroupTotals struct {
+	DaysPresent      int    `json:"days_present"`
+	LateCount        int    `json:"late_count"`
+	MissingCheckouts int    `json:"missing_checkouts"`
+	TotalSeconds     int64  `json:"total_seconds"`
+	TotalTime        string `json:"total_time"`
+}
+
+func (t *AttendanceGroupTotals) add(d AttendanceDay) {
+	if d.FirstIn != nil {
+		t.DaysPresent++
+	}
+	if d.Late {
+		t.LateCount++
+	}
+	if d.MissingCheckout {
+		t.MissingCheckouts++
+	}
+	t.TotalSeconds += d.TotalSeconds
+}
+
+type AttendanceGroup struct {
+	Key    string                `json:"key"`
+	Name   string                `json:"name,omitempty"`
+	Days   []AttendanceDay       `json:"days"`
+	Totals AttendanceGroupTotals `json:"totals"`
+}
+
+type AttendanceReport struct {
+	From       string            `json:"from"`
+	To         string            `json:"to"`
+	GroupBy    string            `json:"group_by"`
+	ShiftStart string            `json:"shift_start"`
+	Timezone   string            `json:"timezone"`
+	Groups     []AttendanceGroup `json:"groups"`
+}
+
+// ParseClock mengubah "HH:MM" menjadi durasi sejak tengah malam.
+func ParseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("format jam harus HH:MM: %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func formatSeconds(sec int64) string {
+	return fmt.Sprintf("%dh%02dm", sec/3600, (sec%3600)/60)
+}
+
+// GenerateAttendanceReport memuat attendance sukses pada rentang tanggal
+// lalu membangun laporan per orang per hari.
+func GenerateAttendanceReport(db *gorm.DB, opts ReportOptions) (AttendanceReport, error) {
+	q := db.Where("status = ? AND created_at >= ? AND created_at < ?", "success", opts.From, opts.To)
+	if opts.AccessID != "" {
+		q = q.Where("access_id = ?", opts.AccessID)
+	}
+
+	var recs []store.Attendance
+	if err := q.Order("created_at").Find(&recs).Error; err != nil {
+		return AttendanceReport{}, err
+	}
+	report := BuildAttendanceReport(recs, opts)
+	if opts.DoorID != "" {
+		report = filterReportByDoor(report, opts.DoorID)
+	}
+	return report, nil
+}
+
+// BuildAttendanceReport memasangkan in/out per orang per hari (zona waktu
+// situs). Waktu di dalam hanya dihitung dari pasangan in -> out yang lengkap.
+func BuildAttendanceReport(recs []store.Attendance, opts ReportOptions) AttendanceReport {
+	loc := opts.Location
+	type dayKey struct{ accessID, date string }
+
+	days := make(map[dayKey]*AttendanceDay)
+	openSince := make(map[dayKey]*time.Time)
+	var order []dayKey
+
+	for _, rec := range recs {
+		at := rec.CreatedAt.In(loc)
+		key := dayKey{rec.AccessID, at.Format("2006-01-02")}
+		day, ok := days[key]
+		if !ok {
+			day = &AttendanceDay{Date: key.date, AccessID: rec.AccessID, Username: rec.Username}
+			days[key] = day
+			order = append(order, key)
+		}
+
+		switch rec.Arrow {
+		case "in":
+			if day.FirstIn == nil {
+				day.FirstIn = &at
+				day.DoorID = rec.DoorID
+			}
+			if openSince[key] == nil {
+				openSince[key] = &at
+			}
+		case "out":
+			day.LastOut = &at
+			if start := openSince[key]; start != nil {
+				day.TotalSeconds += int64(at.Sub(*start).Seconds())
+				openSince[key] = nil
+			}
+		}
+	}
+
+	groups := make(map[string]*AttendanceGroup)
+	var groupOrder []string
+	for _, key := range order {
+		day := days[key]
+		day.MissingCheckout = openSince[key] != nil
+		day.TotalTime = formatSeconds(day.TotalSeconds)
+		if day.FirstIn != nil {
+			dayStart := time.Date(day.FirstIn.Year(), day.FirstIn.Month(), day.FirstIn.Day(), 0, 0, 0, 0, loc)
+			lateBy := day.FirstIn.Sub(dayStart.Add(opts.ShiftStart))
+			if lateBy > opts.Grace {
+				day.Late = true
+				day.LateMinutes = int(lateBy.Minutes())
+			}
+		}
+
+		groupKey, name := day.AccessID, day.Username
+		if opts.GroupBy == "door" {
+			groupKey, name = day.DoorID, ""
+		}
+		g, ok := groups[groupKey]
+		if !ok {
+			g = &AttendanceGroup{Key: groupKey, Name: name}
+			groups[groupKey] = g
+			groupOrder = append(groupOrder, groupKey)
+		}
+		g.Days = append(g.Days, *day)
+		g.Totals.add(*day)
+	}
+
+	sort.Strings(groupOrder)
+	report := AttendanceReport{
+		From:       opts.From.In(loc).Format("2006-01-02"),
+		To:         opts.To.In(loc).AddDate(0, 0, -1).Format("2006-01-02"),
+		GroupBy:    opts.GroupBy,
+		ShiftStart: formatClock(opts.ShiftStart),
+		Timezone:   loc.String(),
+		Groups:     []AttendanceGroup{},
+	}
+	for _, key := range groupOrder {
+		g := groups[key]
+		g.Totals.TotalTime = formatSeconds(g.Totals.TotalSeconds)
+		sort.SliceStable(g.Days, func(i, j int) bool {
+			if g.Days[i].Date != g.Days[j].Date {
+				return g.Days[i].Date < g.Days[j].Date
+			}
+			return g.Days[i].AccessID < g.Days[j].AccessID
+		})
+		report.Groups = append(report.Groups, *g)
+	}
+	return report
+}
+
+// filterReportByDoor menyisakan hari yang first in-nya lewat pintu tsb.
+func filterReportByDoor(report AttendanceReport, doorID string) AttendanceReport {
+	var groups []AttendanceGroup
+	for _, g := range report.Groups {
+		var kept []AttendanceDay
+		totals := AttendanceGroupTotals{}
+		for _, d := range g.Days {
+			if !strings.EqualFold(d.DoorID, doorID) {
+				continue
+			}
+			kept = append(kept, d)
+			totals.add(d)
+		}
+		if len(kept) == 0 {
+			continue
+		}
+		totals.TotalTime = formatSeconds(totals.TotalSeconds)
+		g.Days, g.Totals = kept, totals
+		groups = append(groups, g)
+	}
+	report.Groups = groups
+	if report.Groups == nil {
+		report.Groups = []AttendanceGroup{}
+	}
+	return report
+}
+
+// ParseDateRange membaca from/to (YYYY-MM-DD, zona waktu situs loc).
+// Default 7 hari terakhir termasuk hari ini.
+func ParseDateRange(fromStr, toStr string, now time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	to := config.StartOfDay(now, loc).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -7)
+
+	if toStr != "" {
+		t, err := time.ParseInLocation("2006-01-02", toStr, loc)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid to date: %q", toStr)
+		}
+		to = t.AddDate(0, 0, 1)
+		if fromStr == "" {
+			from = to.AddDate(0, 0, -7)
+		}
+	}
+	if fromStr != "" {
+		f, err := time.ParseInLocation("2006-01-02", fromStr, loc)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid from date: %q", fromStr)
+		}
+		from = f
+	}
+	if !from.Before(to) {
+		return from, to, fmt.Errorf("from must not be after to")
+	}
+	return from, to, nil
+}
@@ -0,0 +1,82 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"smart-door-lock/backend/internal/store"
+)
+
+func TestBuildAttendanceReport(t *testing.T) {
+	loc := siteLoc
+	at := func(day, hour, min int) time.Time { return time.Date(2025, 10, day, hour, min, 0, 0, loc) }
+
+	recs := []store.Attendance{
+		// Budi: tepat waktu, keluar istirahat lalu kembali
+		{AccessID: "A001", Username: "Budi", DoorID: "D01", Arrow: "in", CreatedAt: at(6, 7, 55)},
+		{AccessID: "A001", Username: "Budi", DoorID: "D01", Arrow: "out", CreatedAt: at(6, 12, 0)},
+		{AccessID: "A001", Username: "Budi", DoorID: "D01", Arrow: "in", CreatedAt: at(6, 13, 0)},
+		{AccessID: "A001", Username: "Budi", DoorID: "D01", Arrow: "out", CreatedAt: at(6, 17, 0)},
+		// Dewi: terlambat dan tidak check-out
+		{AccessID: "A003", Username: "Dewi", DoorID: "D02", Arrow: "in", CreatedAt: at(6, 8, 40)},
+	}
+
+	opts := ReportOptions{
+		From:       at(6, 0, 0),
+		To:         at(7, 0, 0),
+		GroupBy:    "user",
+		ShiftStart: 8 * time.Hour,
+		Grace:      10 * time.Minute,
+		Location:   siteLoc,
+	}
+	report := BuildAttendanceReport(recs, opts)
+	if len(report.Groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(report.Groups))
+	}
+
+	budi := report.Groups[0].Days[0]
+	if budi.Late || budi.MissingCheckout {
+		t.Errorf("Budi flagged late=%v missing=%v", budi.Late, budi.MissingCheckout)
+	}
+	if want := int64((4*time.Hour + 5*time.Minute + 4*time.Hour).Seconds()); budi.TotalSeconds != want {
+		t.Errorf("Budi total = %ds, want %ds", budi.TotalSeconds, want)
+	}
+	if !budi.FirstIn.Equal(at(6, 7, 55)) || !budi.LastOut.Equal(at(6, 17, 0)) {
+		t.Errorf("Budi first_in=%v last_out=%v", budi.FirstIn, budi.LastOut)
+	}
+
+	dewi := report.Groups[1].Days[0]
+	if !dewi.Late || dewi.LateMinutes != 40 || !dewi.MissingCheckout || dewi.TotalSeconds != 0 {
+		t.Errorf("Dewi = %+v", dewi)
+	}
+	if report.Groups[1].Totals.LateCount != 1 || report.Groups[1].Totals.MissingCheckouts != 1 {
+		t.Errorf("Dewi totals = %+v", report.Groups[1].Totals)
+	}
+
+	byDoor := BuildAttendanceReport(recs, ReportOptions{From: opts.From, To: opts.To, GroupBy: "door", ShiftStart: opts.ShiftStart, Location: siteLoc})
+	if len(byDoor.Groups) != 2 || byDoor.Groups[0].Key != "D01" || byDoor.Groups[1].Key != "D02" {
+		t.Errorf("door groups = %+v", byDoor.Groups)
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	loc := siteLoc
+	now := time.Date(2025, 10, 20, 10, 0, 0, 0, loc)
+
+	from, to, err := ParseDateRange("2025-10-01", "2025-10-31", now, siteLoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !from.Equal(time.Date(2025, 10, 1, 0, 0, 0, 0, loc)) || !to.Equal(time.Date(2025, 11, 1, 0, 0, 0, 0, loc)) {
+		t.Errorf("range = %v .. %v", from, to)
+	}
+
+	from, to, _ = ParseDateRange("", "", now, siteLoc)
+	if !to.Equal(time.Date(2025, 10, 21, 0, 0, 0, 0, loc)) || to.Sub(from) != 7*24*time.Hour {
+		t.Errorf("default range = %v .. %v", from, to)
+	}
+
+	if _, _, err := ParseDateRange("2025-10-31", "2025-10-01", now, siteLoc); err == nil {
+		t.Error("expected error for reversed range")
+	}
+}
@@ -0,0 +1,286 @@
+package analytics
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/store"
+)
+
+// Granularitas rollup AccessFrequency
+const (
+	TimeFrameHour = "hour"
+	TimeFrameDay  = "day"
+	TimeFrameWeek = "week"
+)
+
+var rollupTimeFrames = []string{TimeFrameHour, TimeFrameDay, TimeFrameWeek}
+
+// rollupBatchSize adalah jumlah attendance baru yang dibaca per langkah.
+const rollupBatchSize = 5000
+
+// rollupRescanWindow adalah jumlah id di bawah watermark yang dibaca ulang
+// setiap kali Pending jalan. Id dibagikan saat insert tetapi baris baru
+// terlihat saat commit, jadi transaksi yang lebih lambat bisa muncul dengan
+// id di bawah LastID setelah watermark lewat. Menghitung ulang periode
+// bersifat idempoten, jadi membaca ulang aman.
+const rollupRescanWindow = 1000
+
+const rollupStateAccess = "access_frequency"
+
+// PeriodStart mengembalikan awal periode yang memuat t (zona waktu situs
+// loc). Minggu dimulai hari Senin.
+func PeriodStart(frame string, t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	switch frame {
+	case TimeFrameHour:
+		return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), 0, 0, 0, loc)
+	case TimeFrameWeek:
+		day := config.StartOfDay(local, loc)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	default:
+		return config.StartOfDay(local, loc)
+	}
+}
+
+// PeriodEnd mengembalikan awal periode berikutnya.
+func PeriodEnd(frame string, start time.Time) time.Time {
+	switch frame {
+	case TimeFrameHour:
+		return start.Add(time.Hour)
+	case TimeFrameWeek:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// recomputePeriod menghitung ulang satu periode dari attendance mentah dan
+// mengganti baris rollup lama periode tsb.
+func recomputePeriod(db *gorm.DB, frame string, start time.Time) error {
+	end := PeriodEnd(frame, start)
+
+	var counts []struct {
+		AccessID    string
+		DoorID      string
+		Username    string
+		AccessCount int
+	}
+	if err := db.Model(&store.Attendance{}).
+		Select("access_id, door_id, MAX(username) AS username, COUNT(*) AS access_count").
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Group("access_id, door_id").
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("time_frame = ? AND period_start = ?", frame, start).Delete(&store.AccessFrequency{}).Error; err != nil {
+			return err
+		}
+		if len(counts) == 0 {
+			return nil
+		}
+		rows := make([]store.AccessFrequency, len(counts))
+		for i, c := range counts {
+			rows[i] = store.AccessFrequency{
+				AccessID:    c.AccessID,
+				DoorID:      c.DoorID,
+				Username:    c.Username,
+				AccessCount: c.AccessCount,
+				TimeFrame:   frame,
+				PeriodStart: start,
+				PeriodEnd:   end,
+			}
+		}
+		return tx.CreateInBatches(rows, 200).Error
+	})
+}
+
+// Rollups memelihara tabel AccessFrequency dari attendance mentah.
+type Rollups struct {
+	db  *gorm.DB
+	loc *time.Location
+	// mu mencegah scheduler dan request trend menghitung bersamaan.
+	mu sync.Mutex
+}
+
+// NewRollups memakai loc (zona waktu situs) untuk batas jam, hari dan
+// minggu.
+func NewRollups(db *gorm.DB, loc *time.Location) *Rollups {
+	return &Rollups{db: db, loc: loc}
+}
+
+// Pending memproses attendance yang belum masuk rollup dan menghitung ulang
+// setiap periode yang tersentuh, termasuk periode lampau bila ada event
+// yang terlambat dikirim perangkat.
+func (r *Rollups) Pending() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	db := r.db
+
+	state := store.RollupState{Name: rollupStateAccess}
+	if err := db.FirstOrCreate(&state, store.RollupState{Name: rollupStateAccess}).Error; err != nil {
+		return err
+	}
+
+	from := uint(0)
+	if state.LastID > rollupRescanWindow {
+		from = state.LastID - rollupRescanWindow
+	}
+	for {
+		var batch []store.Attendance
+		if err := db.Select("id, created_at").Where("id > ?", from).
+			Order("id").Limit(rollupBatchSize).Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		touched := make(map[string]map[int64]time.Time)
+		for _, frame := range rollupTimeFrames {
+			touched[frame] = make(map[int64]time.Time)
+		}
+		for _, rec := range batch {
+			for _, frame := range rollupTimeFrames {
+				start := PeriodStart(frame, rec.CreatedAt, r.loc)
+				touched[frame][start.Unix()] = start
+			}
+		}
+
+		for _, frame := range rollupTimeFrames {
+			starts := make([]time.Time, 0, len(touched[frame]))
+			for _, s := range touched[frame] {
+				starts = append(starts, s)
+			}
+			sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+			for _, start := range starts {
+				if err := recomputePeriod(db, frame, start); err != nil {
+					return err
+				}
+			}
+		}
+
+		from = batch[len(batch)-1].ID
+		if from > state.LastID {
+			state.LastID = from
+			if err := db.Save(&state).Error; err != nil {
+				return err
+			}
+		}
+		if len(batch) < rollupBatchSize {
+			return nil
+		}
+	}
+}
+
+// Run menjalankan rollup berkala di background. Interval <= 0 hanya
+// menjalankan rollup sekali saat startup.
+func (r *Rollups) Run(interval time.Duration) {
+	if err := r.Pending(); err != nil {
+		log.Printf("⚠️ Access frequency rollup failed: %v", err)
+	}
+	if interval <= 0 {
+		log.Printf("⏸️ Access frequency rollup berkala dinonaktifkan (ROLLUP_INTERVAL=%s)", interval)
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := r.Pending(); err != nil {
+			log.Printf("⚠️ Access frequency rollup failed: %v", err)
+		}
+	}
+}
+
+// FrequentAccessRow adalah total akses satu orang dalam rentang waktu.
+type FrequentAccessRow struct {
+	AccessID    string `json:"access_id"`
+	Username    string `json:"username"`
+	AccessCount int    `json:"access_count"`
+}
+
+// FrequentAccess menjumlahkan akses sejak since dan mengembalikan orang
+// dengan akses lebih dari minCount. Periode penuh diambil dari rollup per
+// jam (atau per hari untuk rentang lebih dari seminggu); potongan awal
+// sebelum batas periode pertama dihitung dari attendance mentah supaya
+// rentangnya tepat mulai since.
+func (r *Rollups) FrequentAccess(since time.Time, minCount int) ([]FrequentAccessRow, error) {
+	if err := r.Pending(); err != nil {
+		return nil, err
+	}
+
+	frame := TimeFrameHour
+	if time.Since(since) > 7*24*time.Hour {
+		frame = TimeFrameDay
+	}
+	boundary := PeriodStart(frame, since, r.loc)
+	if boundary.Before(since) {
+		boundary = PeriodEnd(frame, boundary)
+	}
+
+	var parts []FrequentAccessRow
+	if boundary.After(since) {
+		if err := r.db.Model(&store.Attendance{}).
+			Select("access_id, MAX(username) AS username, COUNT(*) AS access_count").
+			Where("created_at >= ? AND created_at < ?", since, boundary).
+			Group("access_id").
+			Scan(&parts).Error; err != nil {
+			return nil, err
+		}
+	}
+	var full []FrequentAccessRow
+	if err := r.db.Model(&store.AccessFrequency{}).
+		Select("access_id, MAX(username) AS username, SUM(access_count) AS access_count").
+		Where("time_frame = ? AND period_start >= ?", frame, boundary).
+		Group("access_id").
+		Scan(&full).Error; err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]*FrequentAccessRow)
+	for _, row := range append(parts, full...) {
+		t, ok := totals[row.AccessID]
+		if !ok {
+			t = &FrequentAccessRow{AccessID: row.AccessID}
+			totals[row.AccessID] = t
+		}
+		t.AccessCount += row.AccessCount
+		if row.Username > t.Username {
+			t.Username = row.Username
+		}
+	}
+	rows := []FrequentAccessRow{}
+	for _, t := range totals {
+		if t.AccessCount > minCount {
+			rows = append(rows, *t)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].AccessCount != rows[j].AccessCount {
+			return rows[i].AccessCount > rows[j].AccessCount
+		}
+		return rows[i].AccessID < rows[j].AccessID
+	})
+	return rows, nil
+}
+
+// LongOpenDoors mengelompokkan pintu yang terbuka lebih lama dari
+// durationThreshold detik per pintu dan orang.
+func LongOpenDoors(db *gorm.DB, durationThreshold int) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+
+	err := db.Model(&store.DoorOpenLog{}).
+		Select("door_id, access_id, username, AVG(duration) as avg_duration, COUNT(*) as occurrence_count").
+		Where("duration > ?", durationThreshold).
+		Group("door_id, access_id, username").
+		Find(&results).Error
+
+	return results, err
+}
@@ -0,0 +1,131 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+func TestRollupRecomputesLateEvents(t *testing.T) {
+	db := storetest.Open(t, &store.Attendance{}, &store.AccessFrequency{}, &store.RollupState{})
+
+	loc := siteLoc
+	at := func(day, hour, min int) time.Time { return time.Date(2025, 10, day, hour, min, 0, 0, loc) }
+	// Senin 6 Okt dan Selasa 7 Okt
+	db.Create(&store.Attendance{AccessID: "A001", Username: "Budi", DoorID: "D01", Arrow: "in", CreatedAt: at(6, 8, 5)})
+	db.Create(&store.Attendance{AccessID: "A001", Username: "Budi", DoorID: "D01", Arrow: "out", CreatedAt: at(6, 8, 40)})
+	db.Create(&store.Attendance{AccessID: "A001", Username: "Budi", DoorID: "D02", Arrow: "in", CreatedAt: at(7, 9, 0)})
+
+	if err := NewRollups(db, siteLoc).Pending(); err != nil {
+		t.Fatal(err)
+	}
+
+	count := func(frame string, start time.Time, doorID string) int {
+		var f store.AccessFrequency
+		db.Where("time_frame = ? AND period_start = ? AND access_id = ? AND door_id = ?", frame, start, "A001", doorID).First(&f)
+		return f.AccessCount
+	}
+	if got := count(TimeFrameHour, at(6, 8, 0), "D01"); got != 2 {
+		t.Errorf("hour 08:00 D01 = %d, want 2", got)
+	}
+	if got := count(TimeFrameWeek, at(6, 0, 0), "D02"); got != 1 {
+		t.Errorf("week D02 = %d, want 1", got)
+	}
+
+	// event terlambat untuk jam yang sudah di-rollup
+	db.Create(&store.Attendance{AccessID: "A001", Username: "Budi", DoorID: "D01", Arrow: "in", CreatedAt: at(6, 8, 55)})
+	if err := NewRollups(db, siteLoc).Pending(); err != nil {
+		t.Fatal(err)
+	}
+	if got := count(TimeFrameHour, at(6, 8, 0), "D01"); got != 3 {
+		t.Errorf("hour after late event = %d, want 3", got)
+	}
+	if got := count(TimeFrameDay, at(6, 0, 0), "D01"); got != 3 {
+		t.Errorf("day after late event = %d, want 3", got)
+	}
+
+	var rows int64
+	db.Model(&store.AccessFrequency{}).Where("time_frame = ?", TimeFrameHour).Count(&rows)
+	if rows != 2 {
+		t.Errorf("hour rollup rows = %d, want 2 (no duplicates)", rows)
+	}
+}
+
+func TestPeriodStartWeekBeginsMonday(t *testing.T) {
+	loc := siteLoc
+	sunday := time.Date(2025, 10, 12, 23, 0, 0, 0, loc)
+	if got := PeriodStart(TimeFrameWeek, sunday, siteLoc); !got.Equal(time.Date(2025, 10, 6, 0, 0, 0, 0, loc)) {
+		t.Errorf("week start of Sunday = %v", got)
+	}
+}
+
+func TestRollupRescansBelowWatermark(t *testing.T) {
+	db := storetest.Open(t, &store.Attendance{}, &store.AccessFrequency{}, &store.RollupState{})
+	at := time.Date(2025, 10, 6, 8, 0, 0, 0, siteLoc)
+
+	// id 2 sudah dibagikan tetapi transaksinya baru commit setelah rollup
+	db.Create(&store.Attendance{ID: 1, AccessID: "A001", DoorID: "D01", CreatedAt: at})
+	db.Create(&store.Attendance{ID: 3, AccessID: "A001", DoorID: "D01", CreatedAt: at})
+	r := NewRollups(db, siteLoc)
+	if err := r.Pending(); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&store.Attendance{ID: 2, AccessID: "A001", DoorID: "D01", CreatedAt: at})
+	if err := r.Pending(); err != nil {
+		t.Fatal(err)
+	}
+
+	var f store.AccessFrequency
+	db.Where("time_frame = ? AND period_start = ?", TimeFrameHour, at).First(&f)
+	if f.AccessCount != 3 {
+		t.Errorf("hour count = %d, want 3 (late commit below watermark)", f.AccessCount)
+	}
+	var state store.RollupState
+	db.First(&state, "name = ?", rollupStateAccess)
+	if state.LastID != 3 {
+		t.Errorf("watermark = %d, want 3", state.LastID)
+	}
+}
+
+func TestFrequentAccessUsesExactRange(t *testing.T) {
+	db := storetest.Open(t, &store.Attendance{}, &store.AccessFrequency{}, &store.RollupState{})
+	since := PeriodStart(TimeFrameHour, time.Now().Add(-3*time.Hour), siteLoc).Add(30 * time.Minute)
+
+	for _, offset := range []time.Duration{-20 * time.Minute, 5 * time.Minute, 40 * time.Minute, 90 * time.Minute} {
+		db.Create(&store.Attendance{AccessID: "A001", Username: "Budi", DoorID: "D01", CreatedAt: since.Add(offset)})
+	}
+
+	rows, err := NewRollups(db, siteLoc).FrequentAccess(since, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// event 20 menit sebelum since satu jam dengan since tetapi di luar rentang
+	if len(rows) != 1 || rows[0].AccessCount != 3 || rows[0].Username != "Budi" {
+		t.Errorf("FrequentAccess = %+v, want 3 accesses", rows)
+	}
+}
+
+func TestRunWithNonPositiveIntervalIsDisabled(t *testing.T) {
+	db := storetest.Open(t, &store.Attendance{}, &store.AccessFrequency{}, &store.RollupState{}, &store.Alarm{})
+	r := NewRollups(db, siteLoc)
+	d := NewDetector(db, r, nil)
+
+	for name, run := range map[string]func(){
+		"rollups":  func() { r.Run(0) },
+		"detector": func() { d.Run(config.AnomalyConfig{RaiseAlarms: true, ScanInterval: -time.Minute}) },
+	} {
+		done := make(chan struct{})
+		go func() {
+			run()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("%s: Run did not return", name)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package analytics
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/store"
+)
+
+// Status device yang dianggap offline
+var deviceOfflineStatuses = map[string]bool{
+	"disconnected": true,
+	"offline":      true,
+	"error":        true,
+}
+
+// Stats menghitung statistik dashboard. Jam, status device dan
+// pintu terbuka diambil lewat fungsi agar bisa diganti di test.
+type Stats struct {
+	db        *gorm.DB
+	loc       *time.Location
+	now       func() time.Time
+	devices   func() map[string]interface{}
+	openDoors func() map[string]time.Time
+}
+
+// NewStats membuat Stats; devices dan openDoors biasanya
+// devices.Registry.Snapshot dan devices.Tracker.OpenSince.
+func NewStats(db *gorm.DB, loc *time.Location, devices func() map[string]interface{}, openDoors func() map[string]time.Time) *Stats {
+	return &Stats{
+		db:        db,
+		loc:       loc,
+		now:       time.Now,
+		devices:   devices,
+		openDoors: openDoors,
+	}
+}
+
+type OpenDoor struct {
+	DoorID      string    `json:"door_id"`
+	OpenedAt    time.Time `json:"opened_at"`
+	OpenSeconds int       `json:"open_seconds"`
+}
+
+type DoorStats struct {
+	DoorID           string `json:"door_id"`
+	Name             string `json:"name"`
+	Entries          int64  `json:"entries"`
+	Exits            int64  `json:"exits"`
+	Denied           int64  `json:"denied"`
+	PeopleInside     int64  `json:"people_inside"`
+	UnresolvedAlarms int64  `json:"unresolved_alarms"`
+	Open             bool   `json:"open"`
+}
+
+// DashboardStats berisi metrik dashboard. "Hari ini" adalah 00:00 sampai
+// 24:00 di zona waktu situs.
+type DashboardStats struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Timezone    string    `json:"timezone"`
+	Day         string    `json:"day"`
+
+	TotalUsers         int64 `json:"total_users"`          // doorlock user aktif
+	TotalDoorlockUsers int64 `json:"total_doorlock_users"` // termasuk yang nonaktif
+	SystemUsers        int64 `json:"system_users"`
+	TotalAttendance    int64 `json:"total_attendance"`
+
+	TodayAttendance int64 `json:"today_attendance"` // semua event hari ini, termasuk ditolak
+	TodayEntries    int64 `json:"today_entries"`    // akses sukses arah in
+	TodayExits      int64 `json:"today_exits"`      // akses sukses arah out
+	TodayDenied     int64 `json:"today_denied"`
+
+	PeopleInside     int64 `json:"people_inside"`     // kunjungan hari ini yang belum keluar
+	UnresolvedAlarms int64 `json:"unresolved_alarms"` // open + acknowledged
+	ActiveAlarms     int64 `json:"active_alarms"`     // alias unresolved_alarms
+
+	OfflineDevices []string    `json:"offline_devices"`
+	OpenDoors      []OpenDoor  `json:"open_doors"`
+	Doors          []DoorStats `json:"doors"`
+}
+
+// Compute menghitung semua metrik pada jam saat ini.
+func (s *Stats) Compute() (DashboardStats, error) {
+	now := s.now()
+	dayStart := config.StartOfDay(now, s.loc)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	stats := DashboardStats{
+		GeneratedAt:    now,
+		Timezone:       s.loc.String(),
+		Day:            dayStart.Format("2006-01-02"),
+		OfflineDevices: []string{},
+		OpenDoors:      []OpenDoor{},
+		Doors:          []DoorStats{},
+	}
+
+	counts := []struct {
+		dst   *int64
+		query *gorm.DB
+	}{
+		{&stats.TotalUsers, s.db.Model(&store.DoorlockUser{}).Where("is_active = ?", true)},
+		{&stats.TotalDoorlockUsers, s.db.Model(&store.DoorlockUser{})},
+		{&stats.SystemUsers, s.db.Model(&store.User{})},
+		{&stats.TotalAttendance, s.db.Model(&store.Attendance{})},
+	}
+	for _, cnt := range counts {
+		if err := cnt.query.Count(cnt.dst).Error; err != nil {
+			return stats, err
+		}
+	}
+
+	doors := make(map[string]*DoorStats)
+	door := func(id string) *DoorStats {
+		d, ok := doors[id]
+		if !ok {
+			d = &DoorStats{DoorID: id}
+			doors[id] = d
+		}
+		return d
+	}
+
+	var configured []store.Door
+	if err := s.db.Find(&configured).Error; err != nil {
+		return stats, err
+	}
+	for _, d := range configured {
+		door(d.DoorID).Name = d.Name
+	}
+
+	var attendance []struct {
+		DoorID string
+		Arrow  string
+		Status string
+		N      int64
+	}
+	if err := s.db.Model(&store.Attendance{}).Select("door_id, arrow, status, COUNT(*) AS n").
+		Where("created_at >= ? AND created_at < ?", dayStart, dayEnd).
+		Group("door_id, arrow, status").Scan(&attendance).Error; err != nil {
+		return stats, err
+	}
+	for _, row := range attendance {
+		d := door(row.DoorID)
+		stats.TodayAttendance += row.N
+		switch {
+		case row.Status != "success":
+			d.Denied += row.N
+			stats.TodayDenied += row.N
+		case row.Arrow == "in":
+			d.Entries += row.N
+			stats.TodayEntries += row.N
+		case row.Arrow == "out":
+			d.Exits += row.N
+			stats.TodayExits += row.N
+		}
+	}
+
+	// kunjungan hari sebelumnya yang belum ditutup expiry tidak dihitung
+	var inside []struct {
+		DoorID string
+		N      int64
+	}
+	if err := s.db.Model(&store.Occupancy{}).Select("door_id, COUNT(*) AS n").
+		Where("exited_at IS NULL AND entered_at >= ? AND entered_at < ?", dayStart, dayEnd).
+		Group("door_id").Scan(&inside).Error; err != nil {
+		return stats, err
+	}
+	for _, row := range inside {
+		door(row.DoorID).PeopleInside += row.N
+		stats.PeopleInside += row.N
+	}
+
+	var alarms []struct {
+		DoorID string
+		N      int64
+	}
+	if err := s.db.Model(&store.Alarm{}).Select("door_id, COUNT(*) AS n").
+		Where("status <> ?", store.AlarmStatusResolved).
+		Group("door_id").Scan(&alarms).Error; err != nil {
+		return stats, err
+	}
+	for _, row := range alarms {
+		stats.UnresolvedAlarms += row.N
+		if row.DoorID != "" {
+			door(row.DoorID).UnresolvedAlarms += row.N
+		}
+	}
+	stats.ActiveAlarms = stats.UnresolvedAlarms
+
+	for id, openedAt := range s.openDoors() {
+		door(id).Open = true
+		stats.OpenDoors = append(stats.OpenDoors, OpenDoor{
+			DoorID:      id,
+			OpenedAt:    openedAt,
+			OpenSeconds: int(now.Sub(openedAt).Seconds()),
+		})
+	}
+	sort.Slice(stats.OpenDoors, func(i, j int) bool { return stats.OpenDoors[i].OpenedAt.Before(stats.OpenDoors[j].OpenedAt) })
+
+	for name, status := range s.devices() {
+		if name == "last_updated" {
+			continue
+		}
+		if str, ok := status.(string); ok && deviceOfflineStatuses[strings.ToLower(str)] {
+			stats.OfflineDevices = append(stats.OfflineDevices, name)
+		}
+	}
+	sort.Strings(stats.OfflineDevices)
+
+	for _, d := range doors {
+		if d.DoorID == "" {
+			continue
+		}
+		stats.Doors = append(stats.Doors, *d)
+	}
+	sort.Slice(stats.Doors, func(i, j int) bool { return stats.Doors[i].DoorID < stats.Doors[j].DoorID })
+	return stats, nil
+}
@@ -0,0 +1,125 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+// 6 Okt 2025 06:30 WIB = 5 Okt 23:30 UTC, sehingga batas hari UTC dan WIB
+// berbeda.
+func statsTestClock() time.Time {
+	return time.Date(2025, 10, 6, 6, 30, 0, 0, siteLoc)
+}
+
+func newTestStats(t *testing.T) (*gorm.DB, *Stats) {
+	db := storetest.Open(t, &store.User{}, &store.DoorlockUser{}, &store.Door{}, &store.Attendance{}, &store.Occupancy{}, &store.Alarm{})
+	s := &Stats{
+		db:        db,
+		loc:       siteLoc,
+		now:       statsTestClock,
+		devices:   func() map[string]interface{} { return map[string]interface{}{} },
+		openDoors: func() map[string]time.Time { return map[string]time.Time{} },
+	}
+	return db, s
+}
+
+func TestStatsTodayEntriesAndExitsUseSiteDay(t *testing.T) {
+	db, s := newTestStats(t)
+	now := statsTestClock()
+
+	// kemarin 23:00 WIB: masih "hari ini" bila dihitung dengan UTC
+	db.Create(&store.Attendance{AccessID: "A001", DoorID: "D01", Arrow: "in", Status: "success", CreatedAt: now.Add(-7*time.Hour - 30*time.Minute)})
+	db.Create(&store.Attendance{AccessID: "A001", DoorID: "D01", Arrow: "in", Status: "success", CreatedAt: now.Add(-5 * time.Hour)})
+	db.Create(&store.Attendance{AccessID: "A002", DoorID: "D02", Arrow: "in", Status: "success", CreatedAt: now.Add(-time.Hour)})
+	db.Create(&store.Attendance{AccessID: "A001", DoorID: "D01", Arrow: "out", Status: "success", CreatedAt: now.Add(-30 * time.Minute)})
+	db.Create(&store.Attendance{AccessID: "X999", DoorID: "D01", Arrow: "in", Status: "denied", CreatedAt: now.Add(-10 * time.Minute)})
+
+	st, err := s.Compute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Day != "2025-10-06" || st.TodayEntries != 2 || st.TodayExits != 1 || st.TodayDenied != 1 || st.TodayAttendance != 4 {
+		t.Errorf("day=%s entries=%d exits=%d denied=%d attendance=%d",
+			st.Day, st.TodayEntries, st.TodayExits, st.TodayDenied, st.TodayAttendance)
+	}
+	if st.TotalAttendance != 5 {
+		t.Errorf("total_attendance = %d, want 5", st.TotalAttendance)
+	}
+}
+
+func TestStatsUsersCountDoorlockUsers(t *testing.T) {
+	db, s := newTestStats(t)
+	db.Create(&store.User{Username: "admin", Role: "admin", IsActive: true})
+	db.Create(&store.DoorlockUser{Name: "Budi", AccessID: "A001", IsActive: true})
+	db.Create(&store.DoorlockUser{Name: "Sari", AccessID: "A002", IsActive: true})
+	db.Create(&store.DoorlockUser{Name: "Lama", AccessID: "A003"})
+
+	st, _ := s.Compute()
+	if st.TotalUsers != 2 || st.TotalDoorlockUsers != 3 || st.SystemUsers != 1 {
+		t.Errorf("total_users=%d doorlock=%d system=%d", st.TotalUsers, st.TotalDoorlockUsers, st.SystemUsers)
+	}
+}
+
+func TestStatsPeopleInside(t *testing.T) {
+	db, s := newTestStats(t)
+	now := statsTestClock()
+	exited := now.Add(-time.Hour)
+	db.Create(&store.Occupancy{AccessID: "A001", DoorID: "D01", EnteredAt: now.Add(-2 * time.Hour)})
+	db.Create(&store.Occupancy{AccessID: "A002", DoorID: "D02", EnteredAt: now.Add(-3 * time.Hour)})
+	db.Create(&store.Occupancy{AccessID: "A003", DoorID: "D01", EnteredAt: now.Add(-4 * time.Hour), ExitedAt: &exited})
+	// belum di-expire dari kemarin
+	db.Create(&store.Occupancy{AccessID: "A004", DoorID: "D01", EnteredAt: now.Add(-10 * time.Hour)})
+
+	st, _ := s.Compute()
+	if st.PeopleInside != 2 {
+		t.Errorf("people_inside = %d, want 2", st.PeopleInside)
+	}
+	if len(st.Doors) != 2 || st.Doors[0].PeopleInside != 1 || st.Doors[1].PeopleInside != 1 {
+		t.Errorf("doors = %+v", st.Doors)
+	}
+}
+
+func TestStatsUnresolvedAlarms(t *testing.T) {
+	db, s := newTestStats(t)
+	now := statsTestClock()
+	// alarm lama yang belum selesai tetap dihitung, tidak dibatasi 24 jam
+	db.Create(&store.Alarm{DoorID: "D01", Status: store.AlarmStatusOpen, CreatedAt: now.AddDate(0, 0, -3)})
+	db.Create(&store.Alarm{DoorID: "D01", Status: store.AlarmStatusAcknowledged, CreatedAt: now.Add(-time.Hour)})
+	db.Create(&store.Alarm{DoorID: "D02", Status: store.AlarmStatusResolved, CreatedAt: now.Add(-time.Hour)})
+
+	st, _ := s.Compute()
+	if st.UnresolvedAlarms != 2 || st.ActiveAlarms != 2 {
+		t.Errorf("unresolved = %d active = %d, want 2", st.UnresolvedAlarms, st.ActiveAlarms)
+	}
+	if len(st.Doors) != 1 || st.Doors[0].DoorID != "D01" || st.Doors[0].UnresolvedAlarms != 2 {
+		t.Errorf("doors = %+v", st.Doors)
+	}
+}
+
+func TestStatsOfflineDevicesAndOpenDoors(t *testing.T) {
+	db, s := newTestStats(t)
+	now := statsTestClock()
+	db.Create(&store.Door{DoorID: "D01", Name: "Lobby"})
+	db.Create(&store.Door{DoorID: "D02", Name: "Gudang"})
+
+	s.devices = func() map[string]interface{} {
+		return map[string]interface{}{"door": "closed", "reader": "disconnected", "pinpad": "connected", "buzzer": false, "last_updated": now}
+	}
+	s.openDoors = func() map[string]time.Time { return map[string]time.Time{"D02": now.Add(-90 * time.Second)} }
+
+	st, _ := s.Compute()
+	if len(st.OfflineDevices) != 1 || st.OfflineDevices[0] != "reader" {
+		t.Errorf("offline_devices = %v", st.OfflineDevices)
+	}
+	if len(st.OpenDoors) != 1 || st.OpenDoors[0].DoorID != "D02" || st.OpenDoors[0].OpenSeconds != 90 {
+		t.Errorf("open_doors = %+v", st.OpenDoors)
+	}
+	if len(st.Doors) != 2 || st.Doors[0].Name != "Lobby" || st.Doors[0].Open || !st.Doors[1].Open {
+		t.Errorf("doors = %+v", st.Doors)
+	}
+}
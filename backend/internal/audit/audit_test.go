@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+func TestAuditChainDetectsTampering(t *testing.T) {
+	db := storetest.Open(t, &store.AuditEvent{})
+	l := New(db)
+
+	for _, target := range []string{"budi", "citra", "dewi"} {
+		if _, err := l.Record(store.AuditEvent{Actor: "admin", Action: UserCreate, Target: target,
+			After: Snapshot(map[string]any{"username": target, "password": "rahasia"})}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var events []store.AuditEvent
+	db.Order("id").Find(&events)
+	if events[0].PrevHash != "" || events[1].PrevHash != events[0].Hash {
+		t.Fatalf("chain not linked: %+v", events)
+	}
+	if events[0].After != `{"password":"***","username":"budi"}` {
+		t.Errorf("after = %s, want password redacted", events[0].After)
+	}
+
+	result, err := l.Verify()
+	if err != nil || !result.Valid || result.Checked != 3 {
+		t.Fatalf("verify = %+v, %v", result, err)
+	}
+
+	db.Model(&store.AuditEvent{}).Where("id = ?", events[1].ID).Update("target", "eko")
+	result, _ = l.Verify()
+	if result.Valid || result.BrokenAt != events[1].ID {
+		t.Fatalf("verify after tampering = %+v, want broken at %d", result, events[1].ID)
+	}
+}
+
+func TestAuditChainRejectsFork(t *testing.T) {
+	db := storetest.Open(t, &store.AuditEvent{})
+	l := New(db)
+	first, err := l.Record(store.AuditEvent{Actor: "admin", Action: UserCreate, Target: "budi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// penulis lain (proses lain, tanpa mutex yang sama) menyambung ke ujung
+	// yang sama: harus ditolak database
+	if _, err := New(db).Record(store.AuditEvent{Actor: "cli", Action: UserCreate, Target: "citra"}); err != nil {
+		t.Fatal(err)
+	}
+	fork := store.AuditEvent{Actor: "cli", Action: UserDelete, Target: "budi", PrevHash: first.Hash, CreatedAt: time.Now().UTC()}
+	fork.Hash = auditHash(fork)
+	if err := db.Create(&fork).Error; err == nil {
+		t.Fatal("forked event stored")
+	}
+	if !l.chainTaken(first.Hash) || l.chainTaken("tidak-ada") {
+		t.Error("chainTaken mismatch")
+	}
+
+	ev, err := l.Record(store.AuditEvent{Actor: "admin", Action: UserDelete, Target: "budi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err := l.Verify(); err != nil || !result.Valid || result.Checked != 3 || ev.PrevHash == first.Hash {
+		t.Fatalf("verify = %+v, %v", result, err)
+	}
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := storetest.Open(t, &store.AuditEvent{})
+	l := New(db)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("username", "admin") }, l.Middleware())
+	r.PUT("/api/notifications/rules/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/api/users/", func(c *gin.Context) {
+		l.Request(c, UserCreate, "budi", nil, nil)
+		c.Status(http.StatusCreated)
+	})
+	r.POST("/api/attendance", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/api/doors", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, req := range []struct{ method, path string }{
+		{http.MethodPut, "/api/notifications/rules/7"},
+		{http.MethodPost, "/api/users/"},
+		{http.MethodPost, "/api/attendance"},
+		{http.MethodGet, "/api/doors"},
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, req.path, nil))
+	}
+
+	var events []store.AuditEvent
+	db.Order("id").Find(&events)
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(events), events)
+	}
+	if events[0].Action != "PUT /api/notifications/rules/:id" || events[0].Target != "id=7" || events[0].Actor != "admin" {
+		t.Errorf("middleware event = %+v", events[0])
+	}
+	if events[1].Action != UserCreate {
+		t.Errorf("explicit event recorded twice or missing: %+v", events[1])
+	}
+}
+
+func TestSnapshotRedactsNestedSecrets(t *testing.T) {
+	got := Snapshot(map[string]any{
+		"user":  map[string]any{"name": "Budi", "pin": "482913"},
+		"users": []any{map[string]any{"password": "rahasia"}},
+		"pin":   "739105",
+	})
+	for _, secret := range []string{"482913", "rahasia", "739105"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("snapshot leaks %q: %s", secret, got)
+		}
+	}
+	if !strings.Contains(got, "Budi") {
+		t.Errorf("snapshot lost data: %s", got)
+	}
+}
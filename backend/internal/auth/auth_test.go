@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+func TestCipherRoundTrip(t *testing.T) {
+	c := NewCipher([]byte("12345678901234567890123456789012"))
+
+	enc, err := c.Encrypt(`{"username":"admin"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := c.Encrypt(`{"username":"admin"}`)
+	if enc == again {
+		t.Error("ciphertext should use a random IV")
+	}
+
+	plain, err := c.Decrypt(enc)
+	if err != nil || plain != `{"username":"admin"}` {
+		t.Fatalf("Decrypt = %q, %v", plain, err)
+	}
+	if _, err := NewCipher([]byte("abcdefghijklmnopqrstuvwxyz123456")).Decrypt(enc); err == nil {
+		t.Error("decrypt with another key should fail")
+	}
+}
+
+func TestTokens(t *testing.T) {
+	tokens := NewTokens([]byte("secret"))
+	tok, err := tokens.Issue("admin", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := tokens.Parse(tok)
+	if err != nil || claims.Username != "admin" {
+		t.Fatalf("Parse = %+v, %v", claims, err)
+	}
+	if _, err := NewTokens([]byte("other")).Parse(tok); err == nil {
+		t.Error("token signed with another secret should be rejected")
+	}
+}
+
+func TestPasswordHash(t *testing.T) {
+	hash, err := HashPassword("Pintu-Kantor-2026")
+	if err != nil || NeedsRehash(hash) {
+		t.Fatalf("HashPassword = %q, %v", hash, err)
+	}
+	if !VerifyPassword(hash, "Pintu-Kantor-2026") || VerifyPassword(hash, "Pintu-Kantor-2027") {
+		t.Error("VerifyPassword mismatch for bcrypt hash")
+	}
+	if !VerifyPassword(hashMD5("admin123"), "admin123") || VerifyPassword(hashMD5("admin123"), "admin124") {
+		t.Error("VerifyPassword mismatch for legacy MD5 hash")
+	}
+	if _, err := HashPassword(strings.Repeat("a", 73)); !errors.Is(err, ErrPasswordTooLong) {
+		t.Errorf("long password: err = %v", err)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	db := storetest.Open(t, &store.User{})
+	// hash MD5 lama dari sebelum bcrypt
+	db.Create(&store.User{Username: "admin", Password: hashMD5("admin123"), Role: "admin", IsActive: true})
+	svc := NewService(store.NewGorm(db), NewTokens([]byte("secret")))
+
+	if _, _, err := svc.Login("admin", "salah"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("wrong password: err = %v", err)
+	}
+	if _, _, err := svc.Login("nobody", "admin123"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("unknown user: err = %v", err)
+	}
+	u, tok, err := svc.Login("admin", "admin123")
+	if err != nil || tok == "" || u.Role != "admin" {
+		t.Fatalf("Login = %+v, %q, %v", u, tok, err)
+	}
+
+	var saved store.User
+	db.First(&saved, "username = ?", "admin")
+	if NeedsRehash(saved.Password) {
+		t.Errorf("legacy hash not upgraded: %q", saved.Password)
+	}
+	if _, _, err := svc.Login("admin", "admin123"); err != nil {
+		t.Errorf("login after upgrade: %v", err)
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	for _, tc := range []struct {
+		password string
+		ok       bool
+	}{
+		{"admin123", false},
+		{"passwordpassword", false}, // satu kelas karakter
+		{"Admin-Pintu-2026", false}, // mengandung username
+		{"Pintu-Kantor-2026", true},
+		{"pintukantor2026!", true},
+	} {
+		err := CheckPassword("admin", tc.password)
+		if (err == nil) != tc.ok {
+			t.Errorf("CheckPassword(%q) = %v", tc.password, err)
+		}
+		if err != nil && !errors.Is(err, ErrWeakPassword) {
+			t.Errorf("CheckPassword(%q) error %v is not ErrWeakPassword", tc.password, err)
+		}
+	}
+}
+
+func TestBootstrap(t *testing.T) {
+	db := storetest.Open(t, &store.User{})
+	st := store.NewGorm(db)
+	b := NewBootstrap(st)
+
+	token, err := b.Token()
+	if err != nil || token == "" {
+		t.Fatalf("Token = %q, %v", token, err)
+	}
+	if again, _ := b.Token(); again != token {
+		t.Error("token should be stable within a process")
+	}
+	if _, err := b.Complete("salah", "root", "Pintu-Kantor-2026"); !errors.Is(err, ErrInvalidSetupToken) {
+		t.Errorf("wrong token: err = %v", err)
+	}
+	if _, err := b.Complete(token, "root", "pendek"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("weak password: err = %v", err)
+	}
+
+	u, err := b.Complete(token, "root", "Pintu-Kantor-2026")
+	if err != nil || u.Role != store.RoleAdmin || !VerifyPassword(u.Password, "Pintu-Kantor-2026") {
+		t.Fatalf("Complete = %+v, %v", u, err)
+	}
+	if _, err := b.Complete(token, "root2", "Pintu-Kantor-2026"); !errors.Is(err, ErrSetupDone) {
+		t.Errorf("second Complete: err = %v", err)
+	}
+	if token, _ := b.Token(); token != "" {
+		t.Errorf("Token after setup = %q", token)
+	}
+	if _, err := CreateAdmin(st, "root", "Pintu-Kantor-2026"); !errors.Is(err, ErrUserExists) {
+		t.Errorf("duplicate admin: err = %v", err)
+	}
+}
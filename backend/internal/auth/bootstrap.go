@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"smart-door-lock/backend/internal/store"
+)
+
+// MinPasswordLength adalah panjang minimal password admin.
+const MinPasswordLength = 12
+
+var (
+	ErrWeakPassword      = errors.New("password too weak")
+	ErrSetupDone         = errors.New("setup already completed")
+	ErrInvalidSetupToken = errors.New("invalid setup token")
+	ErrUserExists        = errors.New("username already exists")
+	ErrUsernameRequired  = errors.New("username is required")
+)
+
+// CheckPassword menolak password admin yang lemah: minimal
+// MinPasswordLength karakter, memakai minimal tiga dari huruf kecil, huruf
+// besar, angka dan simbol, dan tidak mengandung username.
+func CheckPassword(username, password string) error {
+	if len(password) < MinPasswordLength {
+		return fmt.Errorf("%w: minimal %d karakter", ErrWeakPassword, MinPasswordLength)
+	}
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	classes := 0
+	for _, ok := range []bool{lower, upper, digit, symbol} {
+		if ok {
+			classes++
+		}
+	}
+	if classes < 3 {
+		return fmt.Errorf("%w: pakai minimal tiga dari huruf kecil, huruf besar, angka dan simbol", ErrWeakPassword)
+	}
+	if len(username) >= 3 && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return fmt.Errorf("%w: tidak boleh mengandung username", ErrWeakPassword)
+	}
+	return nil
+}
+
+// CreateAdmin membuat user admin baru setelah password lolos CheckPassword.
+func CreateAdmin(st store.Store, username, password string) (store.User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return store.User{}, ErrUsernameRequired
+	}
+	if err := CheckPassword(username, password); err != nil {
+		return store.User{}, err
+	}
+	if _, err := st.UserByUsername(username); err == nil {
+		return store.User{}, ErrUserExists
+	} else if !store.IsNotFound(err) {
+		return store.User{}, err
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return store.User{}, err
+	}
+	u := store.User{
+		Username:  username,
+		Password:  hash,
+		Role:      store.RoleAdmin,
+		IsActive:  true,
+		CreatedAt: time.Now(),
+	}
+	return u, st.CreateUser(&u)
+}
+
+// Bootstrap menangani setup pertama kali: selama belum ada admin aktif,
+// admin pertama hanya bisa dibuat dengan token sekali pakai yang dicetak
+// ke log server.
+type Bootstrap struct {
+	store store.Store
+	mu    sync.Mutex
+	token string
+}
+
+func NewBootstrap(st store.Store) *Bootstrap {
+	return &Bootstrap{store: st}
+}
+
+// Needed bernilai true bila belum ada admin aktif.
+func (b *Bootstrap) Needed() (bool, error) {
+	n, err := b.store.CountActiveAdmins()
+	return n == 0, err
+}
+
+// Token mengembalikan token setup, dibuat sekali per proses. Kosong bila
+// setup tidak diperlukan.
+func (b *Bootstrap) Token() (string, error) {
+	needed, err := b.Needed()
+	if err != nil || !needed {
+		return "", err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.token == "" {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		b.token = hex.EncodeToString(buf)
+	}
+	return b.token, nil
+}
+
+// Complete membuat admin pertama dan menghanguskan token.
+func (b *Bootstrap) Complete(token, username, password string) (store.User, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	needed, err := b.Needed()
+	if err != nil {
+		return store.User{}, err
+	}
+	if !needed {
+		return store.User{}, ErrSetupDone
+	}
+	if b.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(b.token)) != 1 {
+		return store.User{}, ErrInvalidSetupToken
+	}
+
+	u, err := CreateAdmin(b.store, username, password)
+	if err != nil {
+		return u, err
+	}
+	b.token = ""
+	return u, nil
+}
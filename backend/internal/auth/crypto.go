@@ -0,0 +1,139 @@
+// Package auth berisi hashing password, enkripsi payload login, token JWT
+// dan middleware autentikasi.
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrPasswordTooLong dikembalikan HashPassword; bcrypt hanya memakai 72
+// byte pertama.
+var ErrPasswordTooLong = errors.New("password too long (max 72 bytes)")
+
+// ====== HASHING & ENCRYPTION FUNCTIONS ======
+
+// HashPassword meng-hash password dengan bcrypt.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", ErrPasswordTooLong
+	}
+	return string(hash), err
+}
+
+// VerifyPassword membandingkan password dengan hash secara constant time.
+// Hash MD5 lama (sebelum bcrypt) masih diterima; lihat NeedsRehash.
+func VerifyPassword(hash, password string) bool {
+	if NeedsRehash(hash) {
+		return subtle.ConstantTimeCompare([]byte(hash), []byte(hashMD5(password))) == 1
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// NeedsRehash bernilai true untuk hash MD5 lama yang harus diganti bcrypt
+// saat user berhasil login.
+func NeedsRehash(hash string) bool {
+	return !strings.HasPrefix(hash, "$2")
+}
+
+// hashMD5 hanya untuk memverifikasi hash lama yang belum di-upgrade.
+func hashMD5(password string) string {
+	hash := md5.Sum([]byte(password))
+	return hex.EncodeToString(hash[:])
+}
+
+// Cipher mengenkripsi payload login dengan AES-GCM.
+type Cipher struct {
+	key []byte
+}
+
+// NewCipher membuat Cipher dengan kunci 16, 24 atau 32 byte.
+func NewCipher(key []byte) *Cipher {
+	return &Cipher{key: key}
+}
+
+// Encrypt mengenkripsi plaintext; hasilnya base64(nonce || ciphertext).
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	block, err := aes.NewCipher(c.key)
+	if err != nil {
+		return "", fmt.Errorf("cannot create cipher: %v", err)
+	}
+
+	// Use GCM mode
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("cannot create GCM: %v", err)
+	}
+
+	// Create a nonce
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("cannot read random bytes: %v", err)
+	}
+
+	// Encrypt the data
+	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+func (c *Cipher) Decrypt(ciphertext string) (string, error) {
+	// Decode from base64
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("cannot decode base64: %v", err)
+	}
+
+	block, err := aes.NewCipher(c.key)
+	if err != nil {
+		return "", fmt.Errorf("cannot create cipher: %v", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("cannot create GCM: %v", err)
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(data) < nonceSize {
+		return "", errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertextBytes := data[:nonceSize], data[nonceSize:]
+	plaintext, err := gcm.Open(nil, nonce, ciphertextBytes, nil)
+	if err != nil {
+		return "", fmt.Errorf("decryption failed: %v", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// EncryptJSON meng-encode data ke JSON lalu mengenkripsinya ke bentuk
+// respons {"data": "..."}.
+func (c *Cipher) EncryptJSON(data interface{}) (map[string]interface{}, error) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal data: %v", err)
+	}
+
+	encrypted, err := c.Encrypt(string(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("cannot encrypt data: %v", err)
+	}
+
+	return map[string]interface{}{
+		"data": encrypted,
+	}, nil
+}
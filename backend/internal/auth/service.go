@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-door-lock/backend/internal/store"
+)
+
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// dummyHash dipakai saat username tidak ada supaya waktu respons sama
+// dengan password salah.
+var dummyHash, _ = HashPassword("dummy-password-for-timing")
+
+// Service memeriksa kredensial user dashboard.
+type Service struct {
+	store  store.Store
+	tokens *Tokens
+}
+
+func NewService(st store.Store, tokens *Tokens) *Service {
+	return &Service{store: st, tokens: tokens}
+}
+
+// Login memverifikasi username/password lalu menerbitkan token.
+func (s *Service) Login(username, password string) (store.User, string, error) {
+	u, err := s.store.UserByUsername(username)
+	if err != nil {
+		if store.IsNotFound(err) {
+			VerifyPassword(dummyHash, password)
+			return u, "", ErrInvalidCredentials
+		}
+		return u, "", err
+	}
+
+	if !VerifyPassword(u.Password, password) {
+		return u, "", ErrInvalidCredentials
+	}
+	// hash MD5 lama diganti bcrypt begitu password-nya diketahui benar
+	if NeedsRehash(u.Password) {
+		if hash, err := HashPassword(password); err == nil {
+			u.Password = hash
+			if err := s.store.SaveUser(&u); err != nil {
+				log.Printf("⚠️ Gagal meng-upgrade hash password %s: %v", u.Username, err)
+			}
+		}
+	}
+
+	token, err := s.tokens.Issue(u.Username, u.SessionVersion)
+	return u, token, err
+}
+
+// Middleware menolak request tanpa bearer token yang valid, token milik
+// user yang sudah nonaktif atau yang sesinya sudah dicabut, lalu menyimpan
+// username dan role ke context.
+func (s *Service) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tok, ok := bearerToken(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+		claims, err := s.tokens.Parse(tok)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			return
+		}
+		u, err := s.store.UserByUsername(claims.Username)
+		if err != nil && !store.IsNotFound(err) {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check session"})
+			return
+		}
+		if err != nil || !u.IsActive || u.SessionVersion != claims.Version {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "session revoked"})
+			return
+		}
+		c.Set("username", claims.Username)
+		c.Set("role", u.Role)
+		c.Next()
+	}
+}
+
+// RequireAdmin menolak request dari user yang bukan admin. Dipasang
+// setelah Middleware, untuk route yang mengubah data penting atau tidak
+// bisa dibatalkan.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != store.RoleAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin role required"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Masa berlaku token login
+const tokenTTL = 24 * time.Hour
+
+// ====== JWT & AUTH MIDDLEWARE ======
+type Claims struct {
+	Username string `json:"username"`
+	// Version harus sama dengan User.SessionVersion; menaikkan versi itu
+	// mencabut semua token lama.
+	Version int `json:"ver,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Tokens menerbitkan dan memeriksa token JWT HS256.
+type Tokens struct {
+	secret []byte
+}
+
+func NewTokens(secret []byte) *Tokens {
+	return &Tokens{secret: secret}
+}
+
+func (t *Tokens) Issue(username string, version int) (string, error) {
+	claims := Claims{
+		Username: username,
+		Version:  version,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(tokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return tok.SignedString(t.secret)
+}
+
+func (t *Tokens) Parse(tok string) (*Claims, error) {
+	parsed, err := jwt.ParseWithClaims(tok, &Claims{}, func(*jwt.Token) (interface{}, error) {
+		return t.secret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims, ok := parsed.Claims.(*Claims); ok && parsed.Valid {
+		return claims, nil
+	}
+	return nil, errors.New("invalid token")
+}
+
+// bearerToken mengambil token dari header Authorization.
+func bearerToken(c *gin.Context) (string, bool) {
+	h := c.GetHeader("Authorization")
+	if len(h) < 8 || h[:7] != "Bearer " {
+		return "", false
+	}
+	return h[7:], true
+}
@@ -0,0 +1,300 @@
+// Package config memuat konfigurasi backend dari nilai default dan
+// environment variable.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+type TelegramConfig struct {
+	BotToken string
+	ChatID   int64
+	// Target kedua untuk alarm yang belum di-acknowledge (0 = nonaktif)
+	EscalationChatID int64
+}
+
+type MQTTConfig struct {
+	Broker   string
+	ClientID string
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// RetentionConfig berisi masa simpan per tabel dalam hari (0 = simpan
+// selamanya). Baris yang lebih tua diarsipkan lalu dihapus.
+type RetentionConfig struct {
+	AttendanceDays  int
+	AlarmDays       int
+	DoorOpenLogDays int
+	OccupancyDays   int
+	CommandLogDays  int
+	OutboxDays      int
+	Interval        time.Duration
+
+	ArchiveDir    string
+	ArchiveFormat string // jsonl atau csv
+}
+
+// AnomalyConfig berisi ambang batas default deteksi anomali; per request
+// bisa ditimpa lewat query string.
+type AnomalyConfig struct {
+	BaselineDays  int     // panjang riwayat untuk baseline
+	MinHistory    int     // minimal akses di baseline sebelum user dinilai
+	HourTolerance int     // jam di sekitar jam biasa yang masih dianggap normal
+	SpikeFactor   float64 // lonjakan bila akses harian > faktor x rata-rata
+	SpikeMin      int     // dan minimal sebanyak ini
+	RaiseAlarms   bool
+	ScanInterval  time.Duration
+}
+
+// Config adalah seluruh konfigurasi backend.
+type Config struct {
+	HTTPAddr    string
+	CORSOrigins []string
+
+	// DBDriver: sqlite (default), postgres atau mysql. DBPath dipakai
+	// sqlite, DBDSN dipakai postgres dan mysql.
+	DBDriver string
+	DBPath   string
+	DBDSN    string
+	// SeedDemo mengisi data demo saat startup bila database kosong
+	// (khusus development).
+	SeedDemo bool
+
+	// Tidak punya default: serve menolak jalan tanpa JWT_SECRET dan AES_KEY
+	// (lihat CheckSecrets).
+	JWTSecret []byte
+	AESKey    []byte // 32 byte untuk AES-256
+
+	// Zona waktu lokasi, dipakai untuk batas hari dan format waktu.
+	// Location dimuat sekali dari SiteTimezone oleh Default/Load lalu
+	// diteruskan ke setiap service lewat constructor-nya.
+	SiteTimezone string
+	Location     *time.Location
+
+	// Jam mulai shift default untuk menghitung keterlambatan
+	ShiftStart       string
+	LateGraceMinutes int
+
+	Telegram           TelegramConfig
+	AlarmEscalateAfter time.Duration
+	MQTT               MQTTConfig
+	SMTP               SMTPConfig
+	Retention          RetentionConfig
+
+	RollupInterval time.Duration
+	Anomaly        AnomalyConfig
+	// ambang /api/trends/frequent-access
+	FrequentAccessMin int
+}
+
+// Default mengembalikan konfigurasi bawaan.
+func Default() Config {
+	const siteTimezone = "Asia/Jakarta"
+	return Config{
+		HTTPAddr:    ":8090",
+		CORSOrigins: []string{"http://localhost:5173"},
+		DBDriver:    "sqlite",
+		DBPath:      "data.db",
+
+		SiteTimezone: siteTimezone,
+		Location:     defaultLocation(siteTimezone),
+		ShiftStart:   "08:00",
+
+		AlarmEscalateAfter: 10 * time.Minute,
+		MQTT: MQTTConfig{
+			Broker:   "tcp://localhost:1883",
+			ClientID: "doorlock_backend",
+		},
+		SMTP: SMTPConfig{
+			Port: 587,
+			From: "doorlock@localhost",
+		},
+		Retention: RetentionConfig{
+			AttendanceDays:  730,
+			AlarmDays:       1825,
+			DoorOpenLogDays: 90,
+			OccupancyDays:   90,
+			CommandLogDays:  365,
+			OutboxDays:      30,
+			Interval:        24 * time.Hour,
+			ArchiveDir:      "archive",
+			ArchiveFormat:   "jsonl",
+		},
+
+		RollupInterval: 5 * time.Minute,
+		Anomaly: AnomalyConfig{
+			BaselineDays:  30,
+			MinHistory:    10,
+			HourTolerance: 1,
+			SpikeFactor:   3.0,
+			SpikeMin:      10,
+			ScanInterval:  time.Hour,
+		},
+		FrequentAccessMin: 5,
+	}
+}
+
+// Load mengembalikan konfigurasi default yang ditimpa environment variable
+// bila tersedia.
+func Load() Config {
+	cfg := Default()
+
+	cfg.HTTPAddr = envString("HTTP_ADDR", cfg.HTTPAddr)
+	cfg.DBDriver = envString("DB_DRIVER", cfg.DBDriver)
+	cfg.DBPath = envString("DB_PATH", cfg.DBPath)
+	cfg.DBDSN = envString("DB_DSN", cfg.DBDSN)
+	cfg.SeedDemo = envBool("SEED_DEMO", cfg.SeedDemo)
+	cfg.JWTSecret = []byte(envString("JWT_SECRET", string(cfg.JWTSecret)))
+	cfg.AESKey = []byte(envString("AES_KEY", string(cfg.AESKey)))
+
+	if tz := os.Getenv("SITE_TIMEZONE"); tz != "" {
+		if loc, err := time.LoadLocation(tz); err != nil {
+			log.Printf("⚠️ SITE_TIMEZONE tidak valid (%q), memakai default %s", tz, cfg.SiteTimezone)
+		} else {
+			cfg.SiteTimezone, cfg.Location = tz, loc
+		}
+	}
+	cfg.ShiftStart = envString("SHIFT_START", cfg.ShiftStart)
+	cfg.LateGraceMinutes = int(envInt64("LATE_GRACE_MINUTES", int64(cfg.LateGraceMinutes)))
+
+	cfg.Telegram.BotToken = envString("TELEGRAM_BOT_TOKEN", cfg.Telegram.BotToken)
+	cfg.Telegram.ChatID = envInt64("TELEGRAM_CHAT_ID", cfg.Telegram.ChatID)
+	cfg.Telegram.EscalationChatID = envInt64("TELEGRAM_ESCALATION_CHAT_ID", cfg.Telegram.EscalationChatID)
+	cfg.AlarmEscalateAfter = envDuration("ALARM_ESCALATE_AFTER", cfg.AlarmEscalateAfter)
+
+	cfg.MQTT.Broker = envString("MQTT_BROKER", cfg.MQTT.Broker)
+	cfg.MQTT.ClientID = envString("MQTT_CLIENT_ID", cfg.MQTT.ClientID)
+
+	cfg.SMTP.Host = envString("SMTP_HOST", cfg.SMTP.Host)
+	cfg.SMTP.Port = int(envInt64("SMTP_PORT", int64(cfg.SMTP.Port)))
+	cfg.SMTP.Username = envString("SMTP_USERNAME", cfg.SMTP.Username)
+	cfg.SMTP.Password = envString("SMTP_PASSWORD", cfg.SMTP.Password)
+	cfg.SMTP.From = envString("SMTP_FROM", cfg.SMTP.From)
+
+	r := &cfg.Retention
+	r.AttendanceDays = int(envInt64("RETENTION_ATTENDANCE_DAYS", int64(r.AttendanceDays)))
+	r.AlarmDays = int(envInt64("RETENTION_ALARM_DAYS", int64(r.AlarmDays)))
+	r.DoorOpenLogDays = int(envInt64("RETENTION_DOOR_OPEN_LOG_DAYS", int64(r.DoorOpenLogDays)))
+	r.OccupancyDays = int(envInt64("RETENTION_OCCUPANCY_DAYS", int64(r.OccupancyDays)))
+	r.CommandLogDays = int(envInt64("RETENTION_COMMAND_LOG_DAYS", int64(r.CommandLogDays)))
+	r.OutboxDays = int(envInt64("RETENTION_OUTBOX_DAYS", int64(r.OutboxDays)))
+	r.Interval = envDuration("RETENTION_INTERVAL", r.Interval)
+	r.ArchiveDir = envString("ARCHIVE_DIR", r.ArchiveDir)
+	r.ArchiveFormat = envString("ARCHIVE_FORMAT", r.ArchiveFormat)
+	if r.ArchiveFormat != "jsonl" && r.ArchiveFormat != "csv" {
+		log.Printf("⚠️ ARCHIVE_FORMAT tidak valid (%q), memakai jsonl", r.ArchiveFormat)
+		r.ArchiveFormat = "jsonl"
+	}
+
+	cfg.RollupInterval = envDuration("ROLLUP_INTERVAL", cfg.RollupInterval)
+
+	a := &cfg.Anomaly
+	a.BaselineDays = int(envInt64("ANOMALY_BASELINE_DAYS", int64(a.BaselineDays)))
+	a.MinHistory = int(envInt64("ANOMALY_MIN_HISTORY", int64(a.MinHistory)))
+	a.HourTolerance = int(envInt64("ANOMALY_HOUR_TOLERANCE", int64(a.HourTolerance)))
+	a.SpikeFactor = envFloat("ANOMALY_SPIKE_FACTOR", a.SpikeFactor)
+	a.SpikeMin = int(envInt64("ANOMALY_SPIKE_MIN", int64(a.SpikeMin)))
+	a.RaiseAlarms = envBool("ANOMALY_RAISE_ALARMS", a.RaiseAlarms)
+	a.ScanInterval = envDuration("ANOMALY_SCAN_INTERVAL", a.ScanInterval)
+	cfg.FrequentAccessMin = int(envInt64("FREQUENT_ACCESS_MIN", int64(cfg.FrequentAccessMin)))
+
+	return cfg
+}
+
+// Panjang minimal JWT_SECRET; sama dengan ukuran output HMAC-SHA256.
+const minJWTSecretLength = 32
+
+// CheckSecrets memastikan JWT_SECRET dan AES_KEY di-set. Tidak ada nilai
+// default karena secret yang ikut di repository sama dengan tanpa secret.
+func (c Config) CheckSecrets() error {
+	var problems []error
+	if len(c.JWTSecret) < minJWTSecretLength {
+		problems = append(problems, fmt.Errorf("JWT_SECRET wajib di-set, minimal %d karakter (mis. `openssl rand -hex 32`)", minJWTSecretLength))
+	}
+	switch len(c.AESKey) {
+	case 16, 24, 32:
+	default:
+		problems = append(problems, errors.New("AES_KEY wajib di-set, 16, 24 atau 32 karakter (32 untuk AES-256, mis. `openssl rand -hex 16`)"))
+	}
+	return errors.Join(problems...)
+}
+
+// DBSource mengembalikan sumber data untuk store.Open: path file untuk
+// sqlite, DSN untuk driver lain.
+func (c Config) DBSource() string {
+	if c.DBDriver == "" || c.DBDriver == "sqlite" {
+		return c.DBPath
+	}
+	return c.DBDSN
+}
+
+func envString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func envInt64(key string, def int64) int64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		log.Printf("⚠️ %s tidak valid (%q), memakai default %d", key, v, def)
+		return def
+	}
+	return n
+}
+
+func envFloat(key string, def float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		log.Printf("⚠️ %s tidak valid (%q), memakai default %g", key, v, def)
+		return def
+	}
+	return f
+}
+
+func envBool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Printf("⚠️ %s tidak valid (%q), memakai default %t", key, v, def)
+		return def
+	}
+	return b
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Printf("⚠️ %s tidak valid (%q), memakai default %s", key, v, def)
+		return def
+	}
+	return d
+}
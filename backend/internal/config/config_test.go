@@ -0,0 +1,28 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckSecrets(t *testing.T) {
+	cfg := Default()
+	err := cfg.CheckSecrets()
+	if err == nil || !strings.Contains(err.Error(), "JWT_SECRET") || !strings.Contains(err.Error(), "AES_KEY") {
+		t.Fatalf("default config: err = %v, want both secrets reported", err)
+	}
+	if cfg.Telegram.BotToken != "" {
+		t.Error("default config ships a Telegram bot token")
+	}
+
+	cfg.JWTSecret = []byte("pendek")
+	cfg.AESKey = []byte("0123456789abcdef0123456789abcdef")
+	if err := cfg.CheckSecrets(); err == nil || strings.Contains(err.Error(), "AES_KEY") {
+		t.Errorf("short JWT secret: err = %v", err)
+	}
+
+	cfg.JWTSecret = []byte(strings.Repeat("s", minJWTSecretLength))
+	if err := cfg.CheckSecrets(); err != nil {
+		t.Errorf("valid secrets: err = %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"log"
+	"time"
+)
+
+// defaultLocation memuat zona waktu bawaan; bila tzdata tidak tersedia di
+// mesin, jatuh ke zona waktu lokal seperti sebelumnya.
+func defaultLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("⚠️ Zona waktu %q tidak bisa dimuat (%v), memakai zona waktu lokal", name, err)
+		return time.Local
+	}
+	return loc
+}
+
+// StartOfDay mengembalikan jam 00:00 pada hari yang sama di loc.
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
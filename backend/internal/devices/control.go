@@ -0,0 +1,79 @@
+package devices
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"smart-door-lock/backend/internal/mqttbus"
+	"smart-door-lock/backend/internal/store"
+)
+
+const (
+	CommandSourceAPI      = "api"
+	CommandSourceTelegram = "telegram"
+)
+
+// ErrNotConnected dikembalikan ControlDoor bila broker MQTT terputus
+// sehingga perintah tidak pernah sampai ke pintu.
+var ErrNotConnected = errors.New("mqtt not connected, command not sent")
+
+// Controller mengirim perintah ke pintu dan buzzer lalu mencatatnya ke
+// CommandLog, baik dari REST API maupun Telegram. Status buka/tutup pintu
+// tidak diubah di sini: perintah belum tentu dijalankan, jadi status hanya
+// berasal dari laporan pintu (POST /api/device/status/door).
+type Controller struct {
+	store store.Store
+	bus   mqttbus.Bus
+}
+
+func NewController(st store.Store, bus mqttbus.Bus) *Controller {
+	return &Controller{store: st, bus: bus}
+}
+
+func (c *Controller) LogCommand(source, actor, command, target, result string) {
+	entry := store.CommandLog{
+		Source:    source,
+		Actor:     actor,
+		Command:   command,
+		Target:    target,
+		Result:    result,
+		CreatedAt: time.Now(),
+	}
+	if err := c.store.CreateCommandLog(&entry); err != nil {
+		log.Printf("Gagal menyimpan command log: %v", err)
+	}
+}
+
+// ControlDoor mengirim perintah lock/unlock ke pintu lewat MQTT; bila
+// broker tidak terhubung, ErrNotConnected dikembalikan.
+func (c *Controller) ControlDoor(doorID, command, actor, source string) error {
+	err := ErrNotConnected
+	if c.bus.Connected() {
+		topic := fmt.Sprintf("doorlock/%s/control", doorID)
+		message := fmt.Sprintf(`{"command": "%s", "timestamp": "%s"}`,
+			command, time.Now().Format(time.RFC3339))
+		err = c.bus.Publish(topic, message)
+	}
+
+	result := "success"
+	if err != nil {
+		result = "mqtt failed: " + err.Error()
+	}
+	c.LogCommand(source, actor, "door:"+command, doorID, result)
+	log.Printf("Door %s command %s by %s via %s", doorID, command, actor, source)
+	return err
+}
+
+// Buzzer mengirim perintah ke topic buzzer/<id>/control
+func (c *Controller) Buzzer(buzzerID string, command string, duration int) error {
+	topic := fmt.Sprintf("buzzer/%s/control", buzzerID)
+	message := fmt.Sprintf(`{"command": "%s", "duration": %d, "timestamp": "%s"}`,
+		command, duration, time.Now().Format(time.RFC3339))
+	return c.bus.Publish(topic, message)
+}
+
+func (c *Controller) TriggerBuzzer(buzzerID string, duration int) error {
+	return c.Buzzer(buzzerID, "on", duration)
+}
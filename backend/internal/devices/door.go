@@ -0,0 +1,38 @@
+package devices
+
+import "smart-door-lock/backend/internal/store"
+
+// Default konfigurasi pintu, dipakai bila pintu belum dikonfigurasi.
+const (
+	DefaultFailedAttemptLimit  = 3
+	DefaultFailedAttemptWindow = 300 // detik
+	DefaultBuzzerDuration      = 10  // detik
+	DefaultOpenAlarmSeconds    = 60
+)
+
+// LoadDoor mengambil konfigurasi pintu dan mengisi nilai default.
+func LoadDoor(st store.Store, doorID string) store.Door {
+	door := store.Door{DoorID: doorID}
+	if doorID != "" {
+		if d, err := st.Door(doorID); err == nil {
+			door = d
+		}
+	}
+
+	if door.BuzzerID == "" {
+		door.BuzzerID = doorID
+	}
+	if door.BuzzerDuration <= 0 {
+		door.BuzzerDuration = DefaultBuzzerDuration
+	}
+	if door.FailedAttemptLimit <= 0 {
+		door.FailedAttemptLimit = DefaultFailedAttemptLimit
+	}
+	if door.FailedAttemptWindow <= 0 {
+		door.FailedAttemptWindow = DefaultFailedAttemptWindow
+	}
+	if door.OpenAlarmSeconds <= 0 {
+		door.OpenAlarmSeconds = DefaultOpenAlarmSeconds
+	}
+	return door
+}
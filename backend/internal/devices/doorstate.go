@@ -0,0 +1,222 @@
+package devices
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"smart-door-lock/backend/internal/alarms"
+	"smart-door-lock/backend/internal/store"
+)
+
+// akses sukses dianggap membuka pintu bila terjadi dalam rentang ini
+const accessAttributionWindow = 2 * time.Minute
+
+// event notifikasi saat alarm pintu selesai, sama dengan notify.EventResolved
+const eventResolved = "resolved"
+
+// doorState menyimpan kondisi pintu yang sedang terbuka
+type doorState struct {
+	Status   string
+	OpenedAt time.Time
+	AccessID string
+	Username string
+	AlarmID  uint // alarm "terbuka terlalu lama" yang sudah dikirim
+	timer    *time.Timer
+}
+
+// Tracker menurunkan event buka/tutup dari update status pintu dan
+// menghitung durasi ke DoorOpenLog.
+type Tracker struct {
+	store    store.Store
+	alarms   *alarms.Service
+	notifier alarms.Notifier
+	loc      *time.Location // zona waktu situs untuk isi pesan
+
+	mu         sync.Mutex
+	doors      map[string]*doorState
+	lastAccess map[string]store.Attendance
+}
+
+func NewTracker(st store.Store, alarmSvc *alarms.Service, notifier alarms.Notifier, loc *time.Location) *Tracker {
+	return &Tracker{
+		store:      st,
+		alarms:     alarmSvc,
+		notifier:   notifier,
+		loc:        loc,
+		doors:      make(map[string]*doorState),
+		lastAccess: make(map[string]store.Attendance),
+	}
+}
+
+func isDoorOpenStatus(status string) bool {
+	switch strings.ToLower(status) {
+	case "open", "opened", "unlocked":
+		return true
+	}
+	return false
+}
+
+// RecordAccess mengingat akses sukses terakhir per pintu agar event
+// "open" berikutnya bisa dikaitkan ke orang yang membuka.
+func (t *Tracker) RecordAccess(rec store.Attendance) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastAccess[rec.DoorID] = rec
+}
+
+// OpenSince mengembalikan pintu yang sedang terbuka beserta waktu dibuka.
+func (t *Tracker) OpenSince() map[string]time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make(map[string]time.Time, len(t.doors))
+	for id, st := range t.doors {
+		out[id] = st.OpenedAt
+	}
+	return out
+}
+
+// Update dipanggil setiap ada laporan status pintu. State diubah di bawah
+// t.mu; penulisan DB, alarm dan notifikasi dijalankan setelah lock dilepas.
+func (t *Tracker) Update(doorID, status string) {
+	now := time.Now()
+	open := isDoorOpenStatus(status)
+	var door store.Door
+	if open {
+		door = LoadDoor(t.store, doorID)
+	}
+
+	t.mu.Lock()
+	st, ok := t.doors[doorID]
+	wasOpen := ok && isDoorOpenStatus(st.Status)
+
+	var closed *doorState
+	switch {
+	case open && !wasOpen:
+		st = &doorState{Status: status, OpenedAt: now}
+		if acc, ok := t.lastAccess[doorID]; ok && now.Sub(acc.CreatedAt) <= accessAttributionWindow {
+			st.AccessID = acc.AccessID
+			st.Username = acc.Username
+		}
+		threshold := time.Duration(door.OpenAlarmSeconds) * time.Second
+		openedAt := st.OpenedAt
+		st.timer = time.AfterFunc(threshold, func() {
+			t.fireOpenTooLong(doorID, openedAt, door.OpenAlarmSeconds)
+		})
+		t.doors[doorID] = st
+
+	case !open && wasOpen:
+		if st.timer != nil {
+			st.timer.Stop()
+		}
+		delete(t.doors, doorID)
+		copied := *st
+		closed = &copied
+
+	case ok:
+		st.Status = status
+	}
+	t.mu.Unlock()
+
+	switch {
+	case closed != nil:
+		t.closeDoor(doorID, *closed, now)
+	case open && !wasOpen:
+		log.Printf("🚪 Pintu %s terbuka", doorID)
+	}
+}
+
+func (t *Tracker) closeDoor(doorID string, st doorState, closedAt time.Time) {
+	duration := int(closedAt.Sub(st.OpenedAt).Seconds())
+	entry := store.DoorOpenLog{
+		DoorID:    doorID,
+		AccessID:  st.AccessID,
+		Username:  st.Username,
+		Duration:  duration,
+		CreatedAt: st.OpenedAt,
+	}
+	if err := t.store.CreateDoorOpenLog(&entry); err != nil {
+		log.Printf("❌ Gagal menyimpan door open log: %v", err)
+	}
+	log.Printf("🚪 Pintu %s tertutup setelah %d detik", doorID, duration)
+
+	if st.AlarmID != 0 {
+		t.resolveOpenAlarm(doorID, st.AlarmID, duration, closedAt)
+	}
+}
+
+// resolveOpenAlarm menyelesaikan alarm "terbuka terlalu lama" setelah
+// pintu tertutup.
+func (t *Tracker) resolveOpenAlarm(doorID string, alarmID uint, duration int, closedAt time.Time) {
+	al, err := t.alarms.Resolve(alarmID, "system", "pintu sudah tertutup")
+	if errors.Is(err, alarms.ErrResolved) {
+		err = nil
+	}
+	if err != nil {
+		log.Printf("Gagal resolve alarm #%d: %v", alarmID, err)
+		return
+	}
+
+	message := fmt.Sprintf(
+		"✅ ALARM SELESAI ✅\n\nPintu: %s\nAlarm: #%d\nTerbuka selama: %s\nDitutup: %s",
+		doorID,
+		alarmID,
+		(time.Duration(duration) * time.Second).String(),
+		closedAt.In(t.loc).Format("2 Jan 2006, 15:04:05 MST"),
+	)
+	t.notifier.NotifyAlarm(eventResolved, fmt.Sprintf("Alarm #%d selesai: pintu %s tertutup", alarmID, doorID), message, al)
+}
+
+// fireOpenTooLong dijalankan oleh timer ketika pintu masih terbuka
+// melewati batas pintu tersebut. Alarm dibuat tanpa memegang t.mu; bila
+// pintu sudah tertutup selama itu, alarm langsung diselesaikan.
+func (t *Tracker) fireOpenTooLong(doorID string, openedAt time.Time, seconds int) {
+	t.mu.Lock()
+	st, ok := t.doors[doorID]
+	if !ok || !st.OpenedAt.Equal(openedAt) {
+		t.mu.Unlock()
+		return
+	}
+	al := store.Alarm{
+		AlarmType: store.AlarmTypeDoorOpenTooLong,
+		Username:  st.Username,
+		AccessID:  st.AccessID,
+		DoorID:    doorID,
+		Reason:    openTooLongReason(seconds),
+	}
+	t.mu.Unlock()
+
+	if al.Username == "" {
+		al.Username = "Unknown"
+	}
+	if err := t.alarms.Raise(&al); err != nil {
+		log.Printf("❌ %v", err)
+		return
+	}
+
+	t.mu.Lock()
+	st, ok = t.doors[doorID]
+	stillOpen := ok && st.OpenedAt.Equal(openedAt)
+	if stillOpen {
+		st.AlarmID = al.ID
+	}
+	t.mu.Unlock()
+
+	if !stillOpen {
+		now := time.Now()
+		t.resolveOpenAlarm(doorID, al.ID, int(now.Sub(openedAt).Seconds()), now)
+	}
+}
+
+func openTooLongReason(seconds int) string {
+	if seconds == DefaultOpenAlarmSeconds {
+		return alarms.Reason(store.AlarmTypeDoorOpenTooLong)
+	}
+	if seconds%60 == 0 {
+		return fmt.Sprintf("Pintu terbuka > %d menit", seconds/60)
+	}
+	return fmt.Sprintf("Pintu terbuka > %d detik", seconds)
+}
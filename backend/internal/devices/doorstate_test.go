@@ -0,0 +1,62 @@
+package devices
+
+import (
+	"testing"
+	"time"
+
+	"smart-door-lock/backend/internal/alarms"
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+// reentrantNotifier membaca tracker dari dalam notifikasi, seperti channel
+// yang menampilkan pintu terbuka; deadlock bila tracker masih memegang mu.
+type reentrantNotifier struct {
+	tracker *Tracker
+	events  chan string
+}
+
+func (n *reentrantNotifier) NotifyAlarm(event, subject, body string, al store.Alarm) int {
+	n.tracker.OpenSince()
+	n.events <- event
+	return 1
+}
+
+func TestTrackerNotifiesWithoutHoldingLock(t *testing.T) {
+	db := storetest.Open(t, &store.Door{}, &store.Alarm{}, &store.DoorOpenLog{})
+	st := store.NewGorm(db)
+	st.SaveDoor(&store.Door{DoorID: "D01", OpenAlarmSeconds: 1})
+
+	n := &reentrantNotifier{events: make(chan string, 4)}
+	tracker := NewTracker(st, alarms.NewService(st, n, nil, time.Hour, time.UTC), n, time.UTC)
+	n.tracker = tracker
+
+	wait := func(want string) {
+		t.Helper()
+		select {
+		case got := <-n.events:
+			if got != want {
+				t.Fatalf("event = %q, want %q", got, want)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("no %q notification (deadlock?)", want)
+		}
+	}
+
+	tracker.Update("D01", "open")
+	wait("alarm")
+
+	done := make(chan struct{})
+	go func() {
+		tracker.Update("D01", "closed")
+		close(done)
+	}()
+	wait(eventResolved)
+	<-done
+
+	var logs []store.DoorOpenLog
+	db.Find(&logs)
+	if len(logs) != 1 || logs[0].DoorID != "D01" || len(tracker.OpenSince()) != 0 {
+		t.Errorf("door open logs = %+v, open = %v", logs, tracker.OpenSince())
+	}
+}
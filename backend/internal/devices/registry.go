@@ -0,0 +1,45 @@
+// Package devices menyimpan status perangkat, melacak buka/tutup pintu dan
+// mengirim perintah ke pintu dan buzzer lewat MQTT.
+package devices
+
+import (
+	"sync"
+	"time"
+)
+
+// Registry menyimpan status perangkat terakhir yang dilaporkan (door,
+// reader, pinpad, buzzer).
+type Registry struct {
+	mu   sync.RWMutex
+	data map[string]interface{}
+}
+
+// NewRegistry membuat Registry dengan status awal semua perangkat.
+func NewRegistry() *Registry {
+	return &Registry{data: map[string]interface{}{
+		"door":         "closed",
+		"reader":       "disconnected",
+		"pinpad":       "disconnected",
+		"buzzer":       false,
+		"last_updated": time.Now(),
+	}}
+}
+
+// Set memperbarui satu status perangkat beserta last_updated.
+func (r *Registry) Set(key string, value interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.data[key] = value
+	r.data["last_updated"] = time.Now()
+}
+
+// Snapshot mengembalikan salinan status saat ini.
+func (r *Registry) Snapshot() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make(map[string]interface{}, len(r.data))
+	for k, v := range r.data {
+		out[k] = v
+	}
+	return out
+}
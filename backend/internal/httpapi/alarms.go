@@ -0,0 +1,280 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-door-lock/backend/internal/alarms"
+	"smart-door-lock/backend/internal/audit"
+	"smart-door-lock/backend/internal/notify"
+	"smart-door-lock/backend/internal/store"
+)
+
+// ====== ALARM (NO ACCESS_ID FILTER FOR TYPE 1) ======
+
+func (s *Server) raiseAlarm(c *gin.Context) {
+	var req struct {
+		AlarmType int    `json:"alarm_type"`
+		AccessID  string `json:"access_id"`
+		DoorID    string `json:"door_id,omitempty"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": false, "error_code": 1})
+		return
+	}
+
+	var username string
+	var accessID string
+
+	// Untuk alarm type 1 (gagal masuk), skip access_id validation
+	if req.AlarmType == store.AlarmTypeFailedAttempts {
+		username = "Unknown"
+		accessID = req.AccessID
+	} else {
+		// Untuk alarm type lainnya, validasi access_id
+		if req.AccessID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"status": false, "error_code": 1})
+			return
+		}
+
+		doorUser, err := s.Store.DoorlockUser(req.AccessID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"status": false, "error_code": 2, "message": "access_id not found"})
+			return
+		}
+		username = doorUser.Name
+		accessID = req.AccessID
+	}
+
+	al := store.Alarm{
+		AlarmType: req.AlarmType,
+		Username:  username,
+		AccessID:  accessID,
+		DoorID:    req.DoorID,
+		Reason:    alarms.Reason(req.AlarmType),
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.Alarms.Raise(&al); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": false, "error_code": 3, "message": "failed to save alarm"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": true, "error_code": 0})
+}
+
+func (s *Server) listAlarms(c *gin.Context) {
+	params, err := parseListParams(c, map[string]string{
+		"id": "id", "created_at": "created_at", "alarm_type": "alarm_type", "severity": "severity",
+		"status": "status", "door_id": "door_id", "access_id": "access_id",
+	}, "-created_at")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	q := applyEquals(c, s.DB.Model(&store.Alarm{}), map[string]string{
+		"door_id": "door_id", "access_id": "access_id", "alarm_type": "alarm_type",
+		"severity": "severity", "assigned_to": "assigned_to",
+	})
+	if status := c.Query("status"); status == "active" {
+		q = q.Where("status <> ?", store.AlarmStatusResolved)
+	} else if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if r := c.Query("reason"); r != "" {
+		q = q.Where(store.Like("reason"), "%"+r+"%")
+	}
+	if q, err = applyDateRange(c, q, s.cfg.Location); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := paginate(q, params, func(a store.Alarm) (time.Time, uint) { return a.CreatedAt, a.ID })
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch alarms"})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// ====== ALARM LIFECYCLE ======
+
+type alarmActionFunc func(id uint, actor string, c *gin.Context) (store.Alarm, error)
+
+// alarmAction membungkus ack/assign/resolve: mencatat audit dan memetakan
+// error service ke status HTTP.
+func (s *Server) alarmAction(name string, action alarmActionFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := c.GetString("username")
+		id := stringToUint(c.Param("id"))
+		before, _ := s.Store.Alarm(id)
+		al, err := action(id, actor, c)
+		if err == nil {
+			s.Audit.Request(c, audit.AlarmPrefix+name, c.Param("id"), before, al)
+		}
+		switch {
+		case errors.Is(err, alarms.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "alarm not found"})
+		case errors.Is(err, alarms.ErrResolved):
+			c.JSON(http.StatusConflict, gin.H{"error": "alarm already resolved"})
+		case errors.Is(err, alarms.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "alarm changed concurrently, retry"})
+		case errors.Is(err, ErrBadRequest):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update alarm"})
+		default:
+			c.JSON(http.StatusOK, al)
+		}
+	}
+}
+
+func (s *Server) ackAlarm(id uint, actor string, c *gin.Context) (store.Alarm, error) {
+	var req struct {
+		Note string `json:"note"`
+	}
+	c.ShouldBindJSON(&req)
+	return s.Alarms.Acknowledge(id, actor, req.Note)
+}
+
+func (s *Server) assignAlarm(id uint, actor string, c *gin.Context) (store.Alarm, error) {
+	var req struct {
+		AssignedTo string `json:"assigned_to"`
+		Note       string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.AssignedTo == "" {
+		return store.Alarm{}, ErrBadRequest
+	}
+	return s.Alarms.Assign(id, actor, req.AssignedTo, req.Note)
+}
+
+func (s *Server) resolveAlarm(id uint, actor string, c *gin.Context) (store.Alarm, error) {
+	var req struct {
+		Note string `json:"note"`
+	}
+	c.ShouldBindJSON(&req)
+	return s.Alarms.Resolve(id, actor, req.Note)
+}
+
+// ====== NOTIFICATION RULES ======
+
+func (s *Server) listRules(c *gin.Context) {
+	var list []store.NotificationRule
+	if err := s.DB.Order("id").Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch rules"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// ruleRequest adalah body POST/PUT rule. Enabled berupa pointer supaya
+// field yang tidak dikirim bisa dibedakan dari false.
+type ruleRequest struct {
+	store.NotificationRule
+	Enabled *bool `json:"enabled"`
+}
+
+func (s *Server) createRule(c *gin.Context) {
+	var req ruleRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	// rule baru aktif kecuali "enabled": false dikirim eksplisit
+	rule := req.NotificationRule
+	rule.Enabled = req.Enabled == nil || *req.Enabled
+	if msg := s.Notify.ValidateRule(rule); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	rule.ID = 0
+	if err := s.DB.Create(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save rule"})
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (s *Server) updateRule(c *gin.Context) {
+	var rule store.NotificationRule
+	if err := s.DB.First(&rule, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "rule not found"})
+		return
+	}
+	var req ruleRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	// enabled yang tidak dikirim mempertahankan nilai lama
+	updated := req.NotificationRule
+	updated.Enabled = rule.Enabled
+	if req.Enabled != nil {
+		updated.Enabled = *req.Enabled
+	}
+	if msg := s.Notify.ValidateRule(updated); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	updated.ID = rule.ID
+	updated.CreatedAt = rule.CreatedAt
+	if err := s.DB.Save(&updated).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save rule"})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func (s *Server) deleteRule(c *gin.Context) {
+	res := s.DB.Delete(&store.NotificationRule{}, c.Param("id"))
+	if res.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "rule not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "rule deleted successfully"})
+}
+
+func (s *Server) listOutbox(c *gin.Context) {
+	var list []store.NotificationOutbox
+	q := s.DB.Order("created_at desc").Limit(200)
+	if status := c.Query("status"); status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if err := q.Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch outbox"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (s *Server) testNotification(c *gin.Context) {
+	var req struct {
+		Channel string `json:"channel"`
+		Target  string `json:"target"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	n, ok := s.Notify.Notifier(req.Channel)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown or unconfigured channel"})
+		return
+	}
+	err := n.Send(c.Request.Context(), req.Target, notify.Notification{
+		Event:     "test",
+		Subject:   "Test notifikasi Smart Door Lock",
+		Body:      "✅ Test notifikasi dari Smart Door Lock backend",
+		Severity:  store.SeverityLow,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to send notification", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
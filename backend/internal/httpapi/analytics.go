@@ -0,0 +1,358 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-door-lock/backend/internal/analytics"
+	"smart-door-lock/backend/internal/search"
+	"smart-door-lock/backend/internal/store"
+)
+
+// ====== REPORTS ======
+
+func (s *Server) attendanceReport(c *gin.Context) {
+	from, to, err := analytics.ParseDateRange(c.Query("from"), c.Query("to"), time.Now(), s.cfg.Location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	groupBy := c.DefaultQuery("group_by", "user")
+	if groupBy != "user" && groupBy != "door" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by must be user or door"})
+		return
+	}
+
+	shift, err := analytics.ParseClock(c.DefaultQuery("shift_start", s.cfg.ShiftStart))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	grace := s.cfg.LateGraceMinutes
+	if g := c.Query("grace"); g != "" {
+		fmt.Sscanf(g, "%d", &grace)
+	}
+
+	report, err := analytics.GenerateAttendanceReport(s.DB, analytics.ReportOptions{
+		From:       from,
+		To:         to,
+		GroupBy:    groupBy,
+		ShiftStart: shift,
+		Grace:      time.Duration(grace) * time.Minute,
+		AccessID:   c.Query("access_id"),
+		DoorID:     c.Query("door_id"),
+		Location:   s.cfg.Location,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build attendance report"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ====== SEARCH ======
+
+func (s *Server) search(c *gin.Context) {
+	query, err := search.ParseQuery(c.Query("q"), time.Now(), s.cfg.Location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := search.DefaultLimit
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		limit = min(n, search.MaxLimit)
+	}
+	var before *time.Time
+	if b := c.Query("before"); b != "" {
+		t, err := time.Parse(time.RFC3339Nano, b)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "before must be RFC3339"})
+			return
+		}
+		before = &t
+	}
+
+	results, err := s.Search.Search(query, before, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "search failed"})
+		return
+	}
+	resp := gin.H{"query": c.Query("q"), "results": results, "count": len(results), "full_text": s.Search.FullText()}
+	if len(results) == limit {
+		resp["next_before"] = results[len(results)-1].Time.Format(time.RFC3339Nano)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ====== ANALYTICS ======
+
+func analyticsFilterFrom(c *gin.Context, loc *time.Location) (analytics.Filter, error) {
+	from, to, err := analytics.ParseDateRange(c.Query("from"), c.Query("to"), time.Now(), loc)
+	return analytics.Filter{
+		From:     from,
+		To:       to,
+		DoorID:   c.Query("door_id"),
+		AccessID: c.Query("access_id"),
+		Status:   c.Query("status"),
+		Arrow:    c.Query("arrow"),
+	}, err
+}
+
+func (s *Server) timeseries(c *gin.Context) {
+	filter, err := analyticsFilterFrom(c, s.cfg.Location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	bucket := c.DefaultQuery("bucket", analytics.BucketDay)
+	switch bucket {
+	case analytics.BucketMinute, analytics.BucketHour, analytics.BucketDay, analytics.BucketWeek:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bucket must be minute, hour, day or week"})
+		return
+	}
+	split, err := analytics.ParseSplit(c.Query("split"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ts, err := analytics.BuildTimeseries(s.DB, filter, bucket, split, s.cfg.Location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ts)
+}
+
+func (s *Server) heatmap(c *gin.Context) {
+	filter, err := analyticsFilterFrom(c, s.cfg.Location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	top := 5
+	if t := c.Query("top"); t != "" {
+		fmt.Sscanf(t, "%d", &top)
+	}
+
+	hm, err := analytics.BuildHeatmap(s.DB, filter, top, s.cfg.Location)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build heatmap"})
+		return
+	}
+	c.JSON(http.StatusOK, hm)
+}
+
+func (s *Server) peakHours(c *gin.Context) {
+	filter, err := analyticsFilterFrom(c, s.cfg.Location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	top := 5
+	if t := c.Query("top"); t != "" {
+		fmt.Sscanf(t, "%d", &top)
+	}
+
+	hm, err := analytics.BuildHeatmap(s.DB, filter, top, s.cfg.Location)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute peak hours"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"timezone":        hm.Timezone,
+		"total":           hm.Total,
+		"peak_hours":      hm.PeakHours,
+		"peak_by_weekday": hm.PeakByWeekday,
+		"busiest_weekday": hm.BusiestDay,
+	})
+}
+
+// ====== TREND ANALYSIS ======
+
+func (s *Server) frequentAccess(c *gin.Context) {
+	hours := 24
+	if h := c.Query("hours"); h != "" {
+		fmt.Sscanf(h, "%d", &hours)
+	}
+	minCount := s.cfg.FrequentAccessMin
+	if m := c.Query("min"); m != "" {
+		fmt.Sscanf(m, "%d", &minCount)
+	}
+
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+	results, err := s.Rollups.FrequentAccess(since, minCount)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menganalisis data akses"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"time_period_hours": hours,
+		"min_count":         minCount,
+		"frequent_access":   results,
+	})
+}
+
+func (s *Server) accessFrequency(c *gin.Context) {
+	frame := c.DefaultQuery("frame", analytics.TimeFrameDay)
+	if frame != analytics.TimeFrameHour && frame != analytics.TimeFrameDay && frame != analytics.TimeFrameWeek {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "frame must be hour, day or week"})
+		return
+	}
+	from, to, err := analytics.ParseDateRange(c.Query("from"), c.Query("to"), time.Now(), s.cfg.Location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := s.Rollups.Pending(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update rollups"})
+		return
+	}
+
+	q := applyEquals(c, s.DB.Where("time_frame = ? AND period_start >= ? AND period_start < ?", frame, analytics.PeriodStart(frame, from, s.cfg.Location), to),
+		map[string]string{"access_id": "access_id", "door_id": "door_id"})
+	var list []store.AccessFrequency
+	if err := q.Order("period_start, access_id, door_id").Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch access frequency"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"frame": frame, "timezone": s.cfg.Location.String(), "periods": list})
+}
+
+// anomalyConfigFromQuery menimpa default konfigurasi dengan query string
+// bila ada.
+func (s *Server) anomalyConfigFromQuery(c *gin.Context) (analytics.AnomalyConfig, error) {
+	cfg := analytics.AnomalyDefaults(s.cfg.Anomaly)
+	ints := map[string]*int{
+		"baseline_days":  &cfg.BaselineDays,
+		"min_history":    &cfg.MinHistory,
+		"hour_tolerance": &cfg.HourTolerance,
+		"spike_min":      &cfg.SpikeMin,
+	}
+	for key, dst := range ints {
+		if v := c.Query(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return cfg, fmt.Errorf("invalid %s: %q", key, v)
+			}
+			*dst = n
+		}
+	}
+	if v := c.Query("spike_factor"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f <= 0 {
+			return cfg, fmt.Errorf("invalid spike_factor: %q", v)
+		}
+		cfg.SpikeFactor = f
+	}
+	if cfg.BaselineDays == 0 {
+		return cfg, fmt.Errorf("baseline_days must be positive")
+	}
+	return cfg, nil
+}
+
+func (s *Server) anomalies(c *gin.Context) {
+	cfg, err := s.anomalyConfigFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from, to, err := analytics.ParseDateRange(c.Query("from"), c.Query("to"), time.Now(), s.cfg.Location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	findings, err := s.Detector.Detect(from, to, cfg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to detect anomalies"})
+		return
+	}
+	if accessID, kind := c.Query("access_id"), c.Query("type"); accessID != "" || kind != "" {
+		filtered := []analytics.Finding{}
+		for _, f := range findings {
+			if (accessID == "" || f.AccessID == accessID) && (kind == "" || f.Type == kind) {
+				filtered = append(filtered, f)
+			}
+		}
+		findings = filtered
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"from":      from.Format("2006-01-02"),
+		"to":        to.AddDate(0, 0, -1).Format("2006-01-02"),
+		"config":    cfg,
+		"anomalies": findings,
+	})
+}
+
+func (s *Server) longOpenDoors(c *gin.Context) {
+	durationThreshold := 60
+	if d := c.Query("duration"); d != "" {
+		fmt.Sscanf(d, "%d", &durationThreshold)
+	}
+
+	results, err := analytics.LongOpenDoors(s.DB, durationThreshold)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menganalisis data pintu"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"duration_threshold_seconds": durationThreshold,
+		"long_open_doors":            results,
+	})
+}
+
+func (s *Server) createDoorOpenLog(c *gin.Context) {
+	var req struct {
+		DoorID   string `json:"door_id"`
+		AccessID string `json:"access_id"`
+		Username string `json:"username"`
+		Duration int    `json:"duration"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	entry := store.DoorOpenLog{
+		DoorID:    req.DoorID,
+		AccessID:  req.AccessID,
+		Username:  req.Username,
+		Duration:  req.Duration,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.Store.CreateDoorOpenLog(&entry); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save door open log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+// ====== DASHBOARD STATISTICS ======
+
+func (s *Server) dashboardStats(c *gin.Context) {
+	result, err := s.Stats.Compute()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,144 @@
+package httpapi
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-door-lock/backend/internal/access"
+	"smart-door-lock/backend/internal/analytics"
+	"smart-door-lock/backend/internal/store"
+)
+
+// ====== ATTENDANCE ======
+
+func (s *Server) recordAttendance(c *gin.Context) {
+	var req struct {
+		AccessID string `json:"access_id"`
+		Arrow    string `json:"arrow"` // "in" atau "out"
+		DoorID   string `json:"door_id,omitempty"`
+		Pin      string `json:"pin,omitempty"`
+	}
+	if err := c.BindJSON(&req); err != nil || req.AccessID == "" || req.Arrow == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"status": false, "error_code": 1})
+		return
+	}
+
+	rec, err := s.Access.Decide(access.Request{
+		AccessID: req.AccessID,
+		DoorID:   req.DoorID,
+		Pin:      req.Pin,
+		Arrow:    req.Arrow,
+	})
+	if err != nil {
+		log.Printf("Error recording attendance: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"status": false, "error_code": 3, "message": "failed to save attendance"})
+		return
+	}
+
+	switch {
+	case rec.Status == "success":
+		c.JSON(http.StatusOK, gin.H{"status": true, "error_code": 0})
+	case rec.Reason == access.DenyUnknownAccessID:
+		c.JSON(http.StatusNotFound, gin.H{"status": false, "error_code": 2, "message": "access_id not found"})
+	default:
+		c.JSON(http.StatusForbidden, gin.H{"status": false, "error_code": 4, "message": rec.Reason})
+	}
+}
+
+func (s *Server) listAttendance(c *gin.Context) {
+	params, err := parseListParams(c, map[string]string{
+		"id": "id", "created_at": "created_at", "username": "username", "access_id": "access_id",
+		"door_id": "door_id", "status": "status", "arrow": "arrow",
+	}, "-created_at")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	q := applyEquals(c, s.DB.Model(&store.Attendance{}), map[string]string{
+		"door_id": "door_id", "access_id": "access_id", "status": "status", "arrow": "arrow",
+	})
+	if r := c.Query("reason"); r != "" {
+		q = q.Where(store.Like("reason"), "%"+r+"%")
+	}
+	if q, err = applyDateRange(c, q, s.cfg.Location); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := paginate(q, params, func(a store.Attendance) (time.Time, uint) { return a.CreatedAt, a.ID })
+	if err != nil {
+		log.Printf("Error fetching attendance: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch attendance data"})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (s *Server) attendanceSummary(c *gin.Context) {
+	now := time.Now()
+	summary, err := analytics.DailyCounts(s.DB, now.AddDate(0, 0, -7), now, s.cfg.Location)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch summary"})
+		return
+	}
+	if len(summary) > 7 {
+		summary = summary[:7]
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// ====== OCCUPANCY ======
+
+func (s *Server) occupancy(c *gin.Context) {
+	report, err := s.Occupancy.Current(time.Now(), c.Query("zone"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch occupancy"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func (s *Server) headcount(c *gin.Context) {
+	report, err := s.Occupancy.Headcount(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build headcount"})
+		return
+	}
+
+	if c.Query("format") != "text" {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	// Versi teks untuk dicetak di resepsionis
+	loc := s.cfg.Location
+	var b strings.Builder
+	fmt.Fprintf(&b, "EMERGENCY HEADCOUNT - %s\nTotal di dalam: %d\n", report.GeneratedAt.In(loc).Format("2 Jan 2006 15:04:05"), report.Total)
+	for _, z := range report.Zones {
+		zone := z.Zone
+		if zone == "" {
+			zone = "(tanpa zona)"
+		}
+		fmt.Fprintf(&b, "\n== %s (%d) ==\n", zone, z.Count)
+		for _, p := range z.People {
+			fmt.Fprintf(&b, "[ ] %-20s %-8s %-6s masuk %s\n", p.Username, p.AccessID, p.DoorID, p.EnteredAt.In(loc).Format("15:04"))
+		}
+	}
+	c.String(http.StatusOK, b.String())
+}
+
+func (s *Server) doorOccupancy(c *gin.Context) {
+	report, err := s.Occupancy.Current(time.Now(), c.Param("door_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch occupancy"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,71 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-door-lock/backend/internal/store"
+)
+
+// ====== AUDIT LOG ======
+
+func (s *Server) listAudit(c *gin.Context) {
+	params, err := parseListParams(c, map[string]string{"id": "id", "created_at": "created_at"}, "-id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	q := applyEquals(c, s.DB.Model(&store.AuditEvent{}), map[string]string{
+		"actor": "actor", "target": "target", "ip": "ip",
+	})
+	if action := c.Query("action"); strings.HasSuffix(action, "*") {
+		q = q.Where("action LIKE ?", strings.TrimSuffix(action, "*")+"%")
+	} else if action != "" {
+		q = q.Where("action = ?", action)
+	}
+	if q, err = applyDateRange(c, q, s.cfg.Location); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := paginate(q, params, func(e store.AuditEvent) (time.Time, uint) { return e.CreatedAt, e.ID })
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch audit events"})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (s *Server) verifyAudit(c *gin.Context) {
+	result, err := s.Audit.Verify()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify audit chain"})
+		return
+	}
+	status := http.StatusOK
+	if !result.Valid {
+		status = http.StatusConflict
+	}
+	c.JSON(status, result)
+}
+
+// ====== RETENTION ======
+
+func (s *Server) retentionDryRun(c *gin.Context) {
+	preview, err := s.Retention.Preview(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to preview retention"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"archive_dir": s.cfg.Retention.ArchiveDir, "format": s.cfg.Retention.ArchiveFormat, "tables": preview})
+}
+
+func (s *Server) retentionRun(c *gin.Context) {
+	results := s.Retention.Apply(time.Now())
+	s.Audit.Request(c, "retention.run", "", nil, results)
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
@@ -0,0 +1,181 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-door-lock/backend/internal/audit"
+	"smart-door-lock/backend/internal/auth"
+)
+
+// ====== LOGIN (DENGAN ENKRIPSI) ======
+func (s *Server) login(c *gin.Context) {
+	var encryptedReq struct {
+		Data string `json:"data"`
+	}
+
+	if err := c.BindJSON(&encryptedReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad request format"})
+		return
+	}
+
+	if encryptedReq.Data == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "encrypted data is required"})
+		return
+	}
+
+	// Decrypt the request data
+	decryptedData, err := s.Cipher.Decrypt(encryptedReq.Data)
+	if err != nil {
+		log.Printf("Decryption error: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to decrypt data: " + err.Error()})
+		return
+	}
+
+	// Parse decrypted data
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	if err := json.Unmarshal([]byte(decryptedData), &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid decrypted data format"})
+		return
+	}
+
+	u, token, err := s.Auth.Login(req.Username, req.Password)
+	if err != nil {
+		s.Audit.Request(c, audit.LoginFailed, req.Username, nil, nil)
+		// Return encrypted error response
+		encryptedResp, err := s.Cipher.EncryptJSON(gin.H{"error": "invalid username or password"})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to encrypt response"})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, encryptedResp)
+		return
+	}
+
+	c.Set("username", u.Username)
+	s.Audit.Request(c, audit.Login, u.Username, nil, nil)
+	responseData := gin.H{
+		"token":    token,
+		"username": u.Username,
+		"role":     u.Role,
+	}
+
+	encryptedResp, err := s.Cipher.EncryptJSON(responseData)
+	if err != nil {
+		log.Printf("Encryption error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to encrypt response: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, encryptedResp)
+}
+
+// ====== UTILITY ENDPOINT UNTUK ENCRYPT DATA TESTING ======
+func (s *Server) encryptTest(c *gin.Context) {
+	var data map[string]interface{}
+	if err := c.BindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid data format"})
+		return
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot marshal data"})
+		return
+	}
+
+	encrypted, err := s.Cipher.Encrypt(string(jsonData))
+	if err != nil {
+		log.Printf("Encryption error in /encrypt-test: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "encryption failed: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"encrypted": encrypted})
+}
+
+// ====== SIMPLE LOGIN (BACKUP - TANPA ENKRIPSI) ======
+func (s *Server) loginSimple(c *gin.Context) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bad request"})
+		return
+	}
+
+	u, token, err := s.Auth.Login(req.Username, req.Password)
+	if err != nil {
+		s.Audit.Request(c, audit.LoginFailed, req.Username, nil, nil)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid username or password"})
+		return
+	}
+
+	c.Set("username", u.Username)
+	s.Audit.Request(c, audit.Login, u.Username, nil, nil)
+	c.JSON(http.StatusOK, gin.H{
+		"token":    token,
+		"username": u.Username,
+		"role":     u.Role,
+	})
+}
+
+// ====== SETUP PERTAMA KALI ======
+
+func (s *Server) setupStatus(c *gin.Context) {
+	needed, err := s.Bootstrap.Needed()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check setup status"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"setup_required":      needed,
+		"min_password_length": auth.MinPasswordLength,
+	})
+}
+
+// setup membuat admin pertama dengan token setup dari log server.
+func (s *Server) setup(c *gin.Context) {
+	var req struct {
+		Token    string `json:"token"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	u, err := s.Bootstrap.Complete(req.Token, req.Username, req.Password)
+	switch {
+	case errors.Is(err, auth.ErrInvalidSetupToken):
+		s.Audit.Request(c, audit.LoginFailed, "setup", nil, nil)
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, auth.ErrSetupDone), errors.Is(err, auth.ErrUserExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, auth.ErrWeakPassword), errors.Is(err, auth.ErrPasswordTooLong),
+		errors.Is(err, auth.ErrUsernameRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create admin"})
+		return
+	}
+
+	c.Set("username", u.Username)
+	s.Audit.Request(c, audit.SetupAdmin, u.Username, nil, u)
+	log.Printf("✅ Setup complete, admin %q created", u.Username)
+	c.JSON(http.StatusCreated, u)
+}
@@ -0,0 +1,275 @@
+package httpapi
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-door-lock/backend/internal/audit"
+	"smart-door-lock/backend/internal/devices"
+	"smart-door-lock/backend/internal/store"
+)
+
+// ====== DEVICE STATUS ======
+
+// Get all device status
+func (s *Server) deviceStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, s.Devices.Snapshot())
+}
+
+// Update door status
+func (s *Server) updateDoorStatus(c *gin.Context) {
+	var req struct {
+		DoorID string `json:"door_id"`
+		Status string `json:"status"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	s.Devices.Set("door", req.Status)
+	s.Doors.Update(req.DoorID, req.Status)
+
+	log.Printf("Door status updated via REST: %s -> %s", req.DoorID, req.Status)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": fmt.Sprintf("Door %s status updated to %s", req.DoorID, req.Status),
+	})
+}
+
+// Update reader status
+func (s *Server) updateReaderStatus(c *gin.Context) {
+	var req struct {
+		ReaderID string `json:"reader_id"`
+		Status   string `json:"status"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	s.Devices.Set("reader", req.Status)
+
+	log.Printf("Reader status updated via REST: %s -> %s", req.ReaderID, req.Status)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": fmt.Sprintf("Reader %s status updated to %s", req.ReaderID, req.Status),
+	})
+}
+
+// Update pinpad status
+func (s *Server) updatePinpadStatus(c *gin.Context) {
+	var req struct {
+		PinpadID string `json:"pinpad_id"`
+		Status   string `json:"status"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	s.Devices.Set("pinpad", req.Status)
+
+	log.Printf("Pinpad status updated via REST: %s -> %s", req.PinpadID, req.Status)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": fmt.Sprintf("Pinpad %s status updated to %s", req.PinpadID, req.Status),
+	})
+}
+
+// Update buzzer status
+func (s *Server) updateBuzzerStatus(c *gin.Context) {
+	var req struct {
+		BuzzerID string `json:"buzzer_id"`
+		Status   bool   `json:"status"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	s.Devices.Set("buzzer", req.Status)
+
+	statusText := "off"
+	if req.Status {
+		statusText = "on"
+	}
+
+	log.Printf("Buzzer status updated via REST: %s -> %s", req.BuzzerID, statusText)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": fmt.Sprintf("Buzzer %s status updated to %s", req.BuzzerID, statusText),
+	})
+}
+
+// Simulate attendance event
+func (s *Server) simulateAttendance(c *gin.Context) {
+	var req struct {
+		Username string `json:"username"`
+		AccessID string `json:"access_id"`
+		Status   string `json:"status"`
+		Arrow    string `json:"arrow"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	rec := store.Attendance{
+		Username:  req.Username,
+		AccessID:  req.AccessID,
+		Status:    req.Status,
+		Arrow:     req.Arrow,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.Store.CreateAttendance(&rec); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create attendance record"})
+		return
+	}
+
+	log.Printf("Attendance event simulated via REST: %s (%s) - %s", req.Username, req.AccessID, req.Arrow)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "success",
+		"message":   "Attendance event simulated successfully",
+		"record_id": rec.ID,
+	})
+}
+
+// Simulate alarm event
+func (s *Server) simulateAlarm(c *gin.Context) {
+	var req struct {
+		Username string `json:"username"`
+		AccessID string `json:"access_id"`
+		Reason   string `json:"reason"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	al := store.Alarm{
+		Username:  req.Username,
+		AccessID:  req.AccessID,
+		Reason:    req.Reason,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.Store.CreateAlarm(&al); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create alarm record"})
+		return
+	}
+
+	log.Printf("Alarm event simulated via REST: %s (%s) - %s", req.Username, req.AccessID, req.Reason)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "success",
+		"message":   "Alarm event simulated successfully",
+		"record_id": al.ID,
+	})
+}
+
+// ====== MQTT CONTROL ======
+
+func (s *Server) controlDoorlock(c *gin.Context) {
+	var req struct {
+		DoorID  string `json:"door_id"`
+		Command string `json:"command"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	err := s.Control.ControlDoor(req.DoorID, req.Command, c.GetString("username"), devices.CommandSourceAPI)
+	s.Audit.Request(c, audit.DoorControl, req.DoorID, nil, gin.H{"command": req.Command, "published": err == nil})
+	if err != nil {
+		// status pintu hanya berubah lewat laporan pintu, jadi tidak ada
+		// fallback bila perintah tidak terkirim
+		log.Printf("⚠️ Perintah %s pintu %s tidak terkirim: %v", req.Command, req.DoorID, err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": fmt.Sprintf("command %s for door %s not sent: %v", req.Command, req.DoorID, err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": fmt.Sprintf("Perintah %s sent to door %s", req.Command, req.DoorID),
+		"method":  "MQTT",
+	})
+}
+
+func (s *Server) commandLogs(c *gin.Context) {
+	var list []store.CommandLog
+	q := s.DB.Order("created_at desc").Limit(200)
+	if source := c.Query("source"); source != "" {
+		q = q.Where("source = ?", source)
+	}
+	if err := q.Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch command logs"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (s *Server) mqttTest(c *gin.Context) {
+	status := "disconnected"
+	if s.Bus != nil && s.Bus.Connected() {
+		status = "connected"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"mqtt_status": status,
+		"broker":      s.cfg.MQTT.Broker,
+		"client_id":   s.cfg.MQTT.ClientID,
+	})
+}
+
+func (s *Server) controlBuzzer(c *gin.Context) {
+	var req struct {
+		BuzzerID string `json:"buzzer_id"`
+		Command  string `json:"command"`
+		Duration int    `json:"duration,omitempty"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	err := s.Control.Buzzer(req.BuzzerID, req.Command, req.Duration)
+	result := "success"
+	if err != nil {
+		result = "failed: " + err.Error()
+	}
+	s.Control.LogCommand(devices.CommandSourceAPI, c.GetString("username"), "buzzer:"+req.Command, req.BuzzerID, result)
+	s.Audit.Request(c, audit.BuzzerControl, req.BuzzerID, nil, gin.H{"command": req.Command, "duration": req.Duration, "result": result})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Gagal mengirim perintah ke buzzer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": fmt.Sprintf("Perintah %s dikirim ke buzzer %s", req.Command, req.BuzzerID),
+	})
+}
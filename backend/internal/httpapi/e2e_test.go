@@ -0,0 +1,121 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-door-lock/backend/internal/access"
+	"smart-door-lock/backend/internal/store"
+)
+
+// TestLoginAttendanceAlarmFlow menjalankan alur lengkap: operator login,
+// karyawan masuk, seseorang salah PIN sampai alarm berbunyi, lalu operator
+// menangani alarm tersebut.
+func TestLoginAttendanceAlarmFlow(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.login("admin", "admin123")
+
+	w := e.do(http.MethodPut, "/api/doors/D01", token, gin.H{
+		"name": "Lobby", "zone": "lobby", "buzzer_id": "B01", "buzzer_duration": 10,
+		"failed_attempt_limit": 3, "failed_attempt_window": 60, "lockout_minutes": 5,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("configure door: status %d: %s", w.Code, w.Body.String())
+	}
+
+	// akses sukses tercatat di attendance dan occupancy
+	w = e.do(http.MethodPost, "/api/attendance", token, gin.H{"access_id": "A001", "door_id": "D01", "pin": "123456", "arrow": "in"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("attendance: status %d: %s", w.Code, w.Body.String())
+	}
+	var occ access.Report
+	decode(t, e.do(http.MethodGet, "/api/occupancy/D01", token, nil), &occ)
+	if occ.Total != 1 || occ.People[0].AccessID != "A001" {
+		t.Fatalf("occupancy = %+v", occ)
+	}
+
+	if w := e.do(http.MethodPost, "/api/attendance", token, gin.H{"access_id": "X999", "door_id": "D01", "arrow": "in"}); w.Code != http.StatusNotFound {
+		t.Errorf("unknown access_id: status %d", w.Code)
+	}
+
+	// percobaan gagal ketiga (termasuk X999 di atas) memicu alarm
+	for i := 0; i < 2; i++ {
+		w = e.do(http.MethodPost, "/api/attendance", token, gin.H{"access_id": "A001", "door_id": "D01", "pin": "000000", "arrow": "out"})
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("wrong pin %d: status %d", i+1, w.Code)
+		}
+	}
+
+	var alarmPage struct {
+		Data  []store.Alarm `json:"data"`
+		Total int64         `json:"total"`
+	}
+	decode(t, e.do(http.MethodGet, "/api/alarms?status=active", token, nil), &alarmPage)
+	if alarmPage.Total != 1 || alarmPage.Data[0].AlarmType != store.AlarmTypeFailedAttempts || alarmPage.Data[0].DoorID != "D01" {
+		t.Fatalf("active alarms = %+v", alarmPage)
+	}
+	alarmID := alarmPage.Data[0].ID
+
+	// buzzer pintu dinyalakan lewat MQTT
+	if msg, ok := e.mqtt.WaitFor("buzzer/B01/control", time.Second); !ok || !strings.Contains(msg.Payload, `"duration": 10`) {
+		t.Errorf("buzzer message = %+v (found=%v)", msg, ok)
+	}
+	if _, ok := e.mqtt.WaitFor("alarms/new", 2*time.Second); !ok {
+		t.Error("alarms/new not published")
+	}
+
+	// X999 hanya dihitung counter pintu; A001 baru dikunci setelah
+	// kegagalannya sendiri mencapai batas
+	w = e.do(http.MethodPost, "/api/attendance", token, gin.H{"access_id": "A001", "door_id": "D01", "pin": "000000", "arrow": "out"})
+	if w.Code != http.StatusForbidden || strings.Contains(w.Body.String(), access.DenyLockedOut) {
+		t.Fatalf("third wrong pin: status %d: %s", w.Code, w.Body.String())
+	}
+
+	// kredensial terkunci, PIN benar pun ditolak
+	w = e.do(http.MethodPost, "/api/attendance", token, gin.H{"access_id": "A001", "door_id": "D01", "pin": "123456", "arrow": "out"})
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), access.DenyLockedOut) {
+		t.Errorf("after lockout: status %d: %s", w.Code, w.Body.String())
+	}
+
+	e.dispatcher.ProcessOutbox(time.Now())
+	sent := e.telegram.Sent()
+	if len(sent) != 1 || !strings.Contains(sent[0].Notification.Subject, "Alarm #") ||
+		sent[0].Notification.Severity != store.SeverityHigh {
+		t.Fatalf("notifications = %+v", sent)
+	}
+
+	// operator menangani alarm
+	path := fmt.Sprintf("/api/alarms/%d", alarmID)
+	if w := e.do(http.MethodPost, path+"/ack", token, gin.H{"note": "petugas menuju lobby"}); w.Code != http.StatusOK {
+		t.Fatalf("ack: status %d", w.Code)
+	}
+	if w := e.do(http.MethodPost, path+"/resolve", token, gin.H{"note": "salah PIN"}); w.Code != http.StatusOK {
+		t.Fatalf("resolve: status %d", w.Code)
+	}
+	decode(t, e.do(http.MethodGet, "/api/alarms?status=active", token, nil), &alarmPage)
+	if alarmPage.Total != 0 {
+		t.Errorf("active alarms after resolve = %d", alarmPage.Total)
+	}
+
+	var att struct {
+		Total int64 `json:"total"`
+	}
+	decode(t, e.do(http.MethodGet, "/api/attendance?door_id=D01&status=denied", token, nil), &att)
+	if att.Total != 5 {
+		t.Errorf("denied attendance = %d, want 5", att.Total)
+	}
+
+	var verify struct {
+		Valid   bool `json:"valid"`
+		Checked int  `json:"checked"`
+	}
+	decode(t, e.do(http.MethodGet, "/api/audit/verify", token, nil), &verify)
+	if !verify.Valid || verify.Checked == 0 {
+		t.Errorf("audit chain = %+v", verify)
+	}
+}
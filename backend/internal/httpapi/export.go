@@ -0,0 +1,413 @@
+package httpapi
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/analytics"
+	"smart-door-lock/backend/internal/store"
+)
+
+const exportTimeLayout = "2006-01-02 15:04:05"
+
+// maxPDFRows membatasi export PDF karena gofpdf membangun seluruh dokumen
+// di memori. Rentang yang lebih besar ditolak dengan 413; pakai CSV/XLSX
+// yang ditulis bertahap.
+const maxPDFRows = 5000
+
+var errPDFTooLarge = fmt.Errorf("export PDF dibatasi %d baris; persempit rentang tanggal/pintu atau pakai format csv/xlsx", maxPDFRows)
+
+// exportFilter adalah filter yang dipakai export dan dicetak di header PDF.
+type exportFilter struct {
+	From   *time.Time
+	To     *time.Time // eksklusif
+	DoorID string
+	loc    *time.Location // zona waktu situs untuk kolom waktu
+}
+
+// exportSource mendefinisikan kolom dan cara membaca baris per jenis data.
+type exportSource struct {
+	Title   string
+	Columns []string
+	Widths  []float64 // lebar relatif kolom di PDF
+	Model   interface{}
+	// Each memanggil emit untuk setiap baris secara berurutan
+	Each func(db *gorm.DB, f exportFilter, emit func([]string) error) error
+}
+
+var exportSources = map[string]exportSource{
+	"attendance": {
+		Title:   "Attendance",
+		Columns: []string{"ID", "Waktu", "Nama", "Access ID", "Pintu", "Arah", "Status", "Alasan"},
+		Widths:  []float64{1, 3, 3, 2, 1.5, 1, 1.5, 3},
+		Model:   &store.Attendance{},
+		Each: func(db *gorm.DB, f exportFilter, emit func([]string) error) error {
+			return streamRows(db, &store.Attendance{}, f, func(rec *store.Attendance) []string {
+				return []string{fmt.Sprint(rec.ID), formatExportTime(rec.CreatedAt, f.loc), rec.Username,
+					rec.AccessID, rec.DoorID, rec.Arrow, rec.Status, rec.Reason}
+			}, emit)
+		},
+	},
+	"alarms": {
+		Title: "Alarms",
+		Columns: []string{"ID", "Waktu", "Tipe", "Severity", "Status", "Nama", "Access ID", "Pintu",
+			"Alasan", "Acknowledged By", "Resolved At"},
+		Widths: []float64{1, 3, 1, 1.5, 2, 2, 1.5, 1, 4, 2, 3},
+		Model:  &store.Alarm{},
+		Each: func(db *gorm.DB, f exportFilter, emit func([]string) error) error {
+			return streamRows(db, &store.Alarm{}, f, func(al *store.Alarm) []string {
+				resolved := ""
+				if al.ResolvedAt != nil {
+					resolved = formatExportTime(*al.ResolvedAt, f.loc)
+				}
+				return []string{fmt.Sprint(al.ID), formatExportTime(al.CreatedAt, f.loc), fmt.Sprint(al.AlarmType),
+					al.Severity, al.Status, al.Username, al.AccessID, al.DoorID, al.Reason, al.AcknowledgedBy, resolved}
+			}, emit)
+		},
+	},
+	"door-open-logs": {
+		Title:   "Door Open Logs",
+		Columns: []string{"ID", "Waktu", "Pintu", "Access ID", "Nama", "Durasi (detik)"},
+		Widths:  []float64{1, 3, 1.5, 2, 3, 2},
+		Model:   &store.DoorOpenLog{},
+		Each: func(db *gorm.DB, f exportFilter, emit func([]string) error) error {
+			return streamRows(db, &store.DoorOpenLog{}, f, func(l *store.DoorOpenLog) []string {
+				return []string{fmt.Sprint(l.ID), formatExportTime(l.CreatedAt, f.loc), l.DoorID,
+					l.AccessID, l.Username, fmt.Sprint(l.Duration)}
+			}, emit)
+		},
+	},
+}
+
+func formatExportTime(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(exportTimeLayout)
+}
+
+// filtered menerapkan filter export ke query model.
+func filtered(db *gorm.DB, model interface{}, f exportFilter) *gorm.DB {
+	q := db.Model(model)
+	if f.From != nil {
+		q = q.Where("created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("created_at < ?", *f.To)
+	}
+	if f.DoorID != "" {
+		q = q.Where("door_id = ?", f.DoorID)
+	}
+	return q
+}
+
+// streamRows membaca tabel baris per baris dengan cursor sehingga rentang
+// besar tidak dimuat sekaligus ke memori.
+func streamRows[T any](db *gorm.DB, model *T, f exportFilter, toRow func(*T) []string, emit func([]string) error) error {
+	rows, err := filtered(db, model, f).Order("created_at").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rec T
+		if err := db.ScanRows(rows, &rec); err != nil {
+			return err
+		}
+		if err := emit(toRow(&rec)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ====== TABLE WRITERS ======
+
+// escapeFormula mencegah formula injection di spreadsheet: sel yang diawali
+// =, +, -, @, tab atau CR (mis. nama dari input user) diberi prefix ' agar
+// dibaca sebagai teks oleh Excel/LibreOffice.
+func escapeFormula(vals []string) []string {
+	out := make([]string, len(vals))
+	for i, v := range vals {
+		if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			v = "'" + v
+		}
+		out[i] = v
+	}
+	return out
+}
+
+type tableWriter interface {
+	WriteHeader(cols []string) error
+	WriteRow(vals []string) error
+	Close() error
+}
+
+// --- CSV ---
+
+type csvTableWriter struct {
+	w     *csv.Writer
+	flush func()
+	rows  int
+}
+
+func (t *csvTableWriter) WriteHeader(cols []string) error { return t.w.Write(cols) }
+
+func (t *csvTableWriter) WriteRow(vals []string) error {
+	if err := t.w.Write(escapeFormula(vals)); err != nil {
+		return err
+	}
+	t.rows++
+	if t.rows%500 == 0 {
+		t.w.Flush()
+		if t.flush != nil {
+			t.flush()
+		}
+	}
+	return t.w.Error()
+}
+
+func (t *csvTableWriter) Close() error {
+	t.w.Flush()
+	return t.w.Error()
+}
+
+// --- XLSX ---
+
+type xlsxTableWriter struct {
+	out  io.Writer
+	file *excelize.File
+	sw   *excelize.StreamWriter
+	row  int
+}
+
+func newXLSXTableWriter(out io.Writer, sheet string) (*xlsxTableWriter, error) {
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return nil, err
+	}
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxTableWriter{out: out, file: f, sw: sw}, nil
+}
+
+func (t *xlsxTableWriter) write(vals []string) error {
+	t.row++
+	cell, err := excelize.CoordinatesToCellName(1, t.row)
+	if err != nil {
+		return err
+	}
+	row := make([]interface{}, len(vals))
+	for i, v := range vals {
+		row[i] = v
+	}
+	return t.sw.SetRow(cell, row)
+}
+
+func (t *xlsxTableWriter) WriteHeader(cols []string) error { return t.write(cols) }
+func (t *xlsxTableWriter) WriteRow(vals []string) error    { return t.write(escapeFormula(vals)) }
+
+func (t *xlsxTableWriter) Close() error {
+	defer t.file.Close()
+	if err := t.sw.Flush(); err != nil {
+		return err
+	}
+	return t.file.Write(t.out)
+}
+
+// --- PDF ---
+
+// pdfTableWriter menulis tabel sederhana. PDF dibangun di memori oleh
+// gofpdf, jadi jumlah baris dibatasi maxPDFRows.
+type pdfTableWriter struct {
+	out    io.Writer
+	pdf    *gofpdf.Fpdf
+	tr     func(string) string
+	widths []float64
+	cols   []string
+	rows   int
+}
+
+func newPDFTableWriter(out io.Writer, title string, filterLines []string, widths []float64) *pdfTableWriter {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(true, 12)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-10)
+		pdf.SetFont("Helvetica", "I", 7)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Halaman %d/{nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, tr("Smart Door Lock - "+title), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	for _, line := range filterLines {
+		pdf.CellFormat(0, 5, tr(line), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(3)
+
+	pageW, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	total := 0.0
+	for _, w := range widths {
+		total += w
+	}
+	scaled := make([]float64, len(widths))
+	for i, w := range widths {
+		scaled[i] = w / total * (pageW - left - right)
+	}
+
+	return &pdfTableWriter{out: out, pdf: pdf, tr: tr, widths: scaled}
+}
+
+func (t *pdfTableWriter) header() {
+	t.pdf.SetFont("Helvetica", "B", 8)
+	t.pdf.SetFillColor(230, 230, 230)
+	for i, col := range t.cols {
+		t.pdf.CellFormat(t.widths[i], 6, t.tr(col), "1", 0, "L", true, 0, "")
+	}
+	t.pdf.Ln(-1)
+	t.pdf.SetFont("Helvetica", "", 7)
+}
+
+func (t *pdfTableWriter) WriteHeader(cols []string) error {
+	t.cols = cols
+	t.header()
+	return nil
+}
+
+func (t *pdfTableWriter) WriteRow(vals []string) error {
+	// data bisa bertambah setelah dihitung di handler
+	if t.rows++; t.rows > maxPDFRows {
+		return errPDFTooLarge
+	}
+	_, pageH := t.pdf.GetPageSize()
+	_, _, _, bottom := t.pdf.GetMargins()
+	if t.pdf.GetY()+5 > pageH-bottom {
+		t.pdf.AddPage()
+		t.header()
+	}
+	for i, v := range vals {
+		// potong teks agar tidak keluar dari sel
+		text := t.tr(v)
+		for len(text) > 1 && t.pdf.GetStringWidth(text) > t.widths[i]-1 {
+			text = text[:len(text)-1]
+		}
+		t.pdf.CellFormat(t.widths[i], 5, text, "1", 0, "L", false, 0, "")
+	}
+	t.pdf.Ln(-1)
+	return t.pdf.Error()
+}
+
+func (t *pdfTableWriter) Close() error {
+	return t.pdf.Output(t.out)
+}
+
+// ====== HANDLER ======
+
+func exportFilterLines(kind string, f exportFilter) []string {
+	period := "semua"
+	switch {
+	case f.From != nil && f.To != nil:
+		period = fmt.Sprintf("%s s/d %s", f.From.In(f.loc).Format("2006-01-02"),
+			f.To.In(f.loc).AddDate(0, 0, -1).Format("2006-01-02"))
+	}
+	door := "semua"
+	if f.DoorID != "" {
+		door = f.DoorID
+	}
+	return []string{
+		"Data: " + kind,
+		"Periode: " + period,
+		"Pintu: " + door,
+		"Zona waktu: " + f.loc.String(),
+		"Dibuat: " + formatExportTime(time.Now(), f.loc),
+	}
+}
+
+// export menulis attendance, alarm atau door open log sebagai CSV, XLSX
+// atau PDF. CSV dan XLSX ditulis bertahap; PDF ditolak 413 bila melebihi
+// maxPDFRows.
+func (s *Server) export(c *gin.Context) {
+	db, loc := s.DB, s.cfg.Location
+	kind := c.Param("kind")
+	src, ok := exportSources[kind]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown export, use attendance, alarms or door-open-logs"})
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", "csv"))
+	filter := exportFilter{DoorID: c.Query("door_id"), loc: loc}
+	if c.Query("from") != "" || c.Query("to") != "" {
+		from, to, err := analytics.ParseDateRange(c.Query("from"), c.Query("to"), time.Now(), loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filter.From, filter.To = &from, &to
+	}
+
+	filename := kind
+	if filter.From != nil {
+		filename += "_" + filter.From.In(loc).Format("20060102") +
+			"_" + filter.To.In(loc).AddDate(0, 0, -1).Format("20060102")
+	}
+
+	var tw tableWriter
+	switch format {
+	case "csv":
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		tw = &csvTableWriter{w: csv.NewWriter(c.Writer), flush: c.Writer.Flush}
+	case "xlsx":
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		xw, err := newXLSXTableWriter(c.Writer, src.Title)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create spreadsheet"})
+			return
+		}
+		tw = xw
+	case "pdf":
+		var n int64
+		if err := filtered(db, src.Model, filter).Count(&n).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count rows"})
+			return
+		}
+		if n > maxPDFRows {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": errPDFTooLarge.Error(), "rows": n, "max_rows": maxPDFRows})
+			return
+		}
+		c.Header("Content-Type", "application/pdf")
+		tw = newPDFTableWriter(c.Writer, src.Title, exportFilterLines(kind, filter), src.Widths)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv, xlsx or pdf"})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, format))
+	c.Status(http.StatusOK)
+
+	err := tw.WriteHeader(src.Columns)
+	if err == nil {
+		err = src.Each(db, filter, tw.WriteRow)
+	}
+	if err == nil {
+		err = tw.Close()
+	}
+	if err != nil {
+		// header sudah terkirim, hanya bisa dicatat
+		c.Error(err)
+		fmt.Fprintf(gin.DefaultErrorWriter, "export %s (%s) failed: %v\n", kind, format, err)
+	}
+}
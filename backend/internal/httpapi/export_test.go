@@ -0,0 +1,106 @@
+package httpapi
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+func TestExportAttendanceCSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := storetest.Open(t, &store.Attendance{})
+
+	loc := config.Default().Location
+	db.Create(&store.Attendance{AccessID: "A001", Username: "Budi", DoorID: "D01", Arrow: "in", Status: "success",
+		CreatedAt: time.Date(2025, 10, 6, 7, 55, 0, 0, loc)})
+	db.Create(&store.Attendance{AccessID: "A002", Username: "Citra", DoorID: "D02", Arrow: "in", Status: "success",
+		CreatedAt: time.Date(2025, 10, 6, 8, 10, 0, 0, loc)})
+	db.Create(&store.Attendance{AccessID: "A001", Username: "Budi", DoorID: "D01", Arrow: "out", Status: "success",
+		CreatedAt: time.Date(2025, 10, 9, 17, 0, 0, 0, loc)})
+
+	r := gin.New()
+	s := &Server{Deps: Deps{DB: db}, cfg: config.Default()}
+	r.GET("/api/export/:kind", s.export)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/export/attendance?format=csv&from=2025-10-06&to=2025-10-06&door_id=D01", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "attendance_20251006_20251006.csv") {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want header + 1: %v", len(rows), rows)
+	}
+	if rows[1][1] != "2025-10-06 07:55:00" || rows[1][2] != "Budi" {
+		t.Errorf("row = %v", rows[1])
+	}
+
+	for _, format := range []string{"xlsx", "pdf"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/export/attendance?format="+format, nil))
+		if w.Code != http.StatusOK || w.Body.Len() == 0 {
+			t.Errorf("%s: status = %d, %d bytes", format, w.Code, w.Body.Len())
+		}
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/export/users", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown kind status = %d, want 404", w.Code)
+	}
+}
+
+func TestExportEscapesFormulasAndCapsPDF(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := storetest.Open(t, &store.Attendance{})
+	db.Create(&store.Attendance{AccessID: "A001", Username: "=HYPERLINK(\"http://x\")", DoorID: "D01", Status: "denied", Reason: "@SUM(1)"})
+
+	r := gin.New()
+	s := &Server{Deps: Deps{DB: db}, cfg: config.Default()}
+	r.GET("/api/export/:kind", s.export)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/export/attendance?format=csv", nil))
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("rows = %v, %v", rows, err)
+	}
+	if rows[1][2] != `'=HYPERLINK("http://x")` || rows[1][7] != "'@SUM(1)" || rows[1][4] != "D01" {
+		t.Errorf("row = %v, want formula cells prefixed with '", rows[1])
+	}
+
+	extra := make([]store.Attendance, maxPDFRows)
+	for i := range extra {
+		extra[i] = store.Attendance{AccessID: "A002", DoorID: "D02", Status: "success"}
+	}
+	if err := db.CreateInBatches(extra, 500).Error; err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/export/attendance?format=pdf", nil))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("pdf over cap: status = %d, want 413", w.Code)
+	}
+	// filter yang mempersempit hasil tetap boleh PDF
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/export/attendance?format=pdf&door_id=D01", nil))
+	if w.Code != http.StatusOK || w.Body.Len() == 0 {
+		t.Errorf("pdf within cap: status = %d", w.Code)
+	}
+}
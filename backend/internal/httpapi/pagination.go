@@ -0,0 +1,225 @@
+package httpapi
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/analytics"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// listParams adalah parameter paginasi & sort yang sama untuk semua list
+// endpoint: ?page=&page_size= (offset) atau ?cursor= (keyset berdasarkan
+// kolom sort dan id), ?sort=field atau ?sort=-field untuk descending.
+type listParams struct {
+	Page     int
+	PageSize int
+	Cursor   *listCursor
+	Sort     string // nama field publik, mis. "created_at"
+	Column   string // kolom DB hasil whitelist
+	Desc     bool
+}
+
+// listCursor adalah posisi baris terakhir sebuah halaman. Dikirim ke klien
+// sebagai string opaque "<created_at RFC3339Nano>|<id>" dalam base64url;
+// presisi penuh created_at dipertahankan agar baris dengan waktu yang
+// hampir sama tidak terlewat.
+type listCursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+func (k listCursor) String() string {
+	raw := k.CreatedAt.Format(time.RFC3339Nano) + "|" + strconv.FormatUint(uint64(k.ID), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func parseCursor(s string) (*listCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	at, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, fmt.Errorf("missing id")
+	}
+	t, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || n == 0 {
+		return nil, fmt.Errorf("invalid id")
+	}
+	return &listCursor{CreatedAt: t, ID: uint(n)}, nil
+}
+
+// listPage adalah envelope respons list.
+type listPage[T any] struct {
+	Data       []T    `json:"data"`
+	Total      int64  `json:"total"`
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	Sort       string `json:"sort"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// parseListParams membaca parameter list. sortable memetakan nama field
+// publik ke kolom DB; defaultSort memakai format yang sama dengan ?sort=.
+func parseListParams(c *gin.Context, sortable map[string]string, defaultSort string) (listParams, error) {
+	p := listParams{Page: 1, PageSize: defaultPageSize}
+
+	if s := c.Query("page"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return p, fmt.Errorf("invalid page: %q", s)
+		}
+		p.Page = n
+	}
+	if s := c.Query("page_size"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return p, fmt.Errorf("invalid page_size: %q", s)
+		}
+		if n > maxPageSize {
+			n = maxPageSize
+		}
+		p.PageSize = n
+	}
+	if s := c.Query("cursor"); s != "" {
+		k, err := parseCursor(s)
+		if err != nil {
+			return p, fmt.Errorf("invalid cursor: %q", s)
+		}
+		p.Cursor = k
+	}
+
+	sort := c.DefaultQuery("sort", defaultSort)
+	p.Desc = strings.HasPrefix(sort, "-")
+	p.Sort = strings.TrimPrefix(sort, "-")
+	col, ok := sortable[p.Sort]
+	if !ok {
+		return p, fmt.Errorf("cannot sort by %q", p.Sort)
+	}
+	p.Column = col
+
+	// cursor hanya menyimpan created_at dan id
+	if p.Cursor != nil && col != "id" && col != "created_at" {
+		return p, fmt.Errorf("cursor pagination requires sort by id or created_at")
+	}
+	return p, nil
+}
+
+// paginate menghitung total (setelah filter) lalu mengambil satu halaman.
+// keyOf mengembalikan created_at dan id sebuah baris untuk next_cursor.
+func paginate[T any](q *gorm.DB, p listParams, keyOf func(T) (time.Time, uint)) (listPage[T], error) {
+	page := listPage[T]{Data: []T{}, PageSize: p.PageSize, Sort: p.Sort}
+	if p.Desc {
+		page.Sort = "-" + p.Sort
+	}
+
+	if err := q.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return page, err
+	}
+
+	dir := "asc"
+	if p.Desc {
+		dir = "desc"
+	}
+	q = q.Session(&gorm.Session{})
+	if p.Cursor != nil {
+		// created_at bisa diisi ulang (backfill, jam perangkat), jadi
+		// urutannya tidak selalu sama dengan id; id hanya pemecah seri.
+		op := ">"
+		if p.Desc {
+			op = "<"
+		}
+		if p.Column == "id" {
+			q = q.Where("id "+op+" ?", p.Cursor.ID)
+		} else {
+			q = q.Where("(created_at, id) "+op+" (?, ?)", p.Cursor.CreatedAt, p.Cursor.ID)
+			q = q.Order("created_at " + dir)
+		}
+		q = q.Order("id " + dir)
+	} else {
+		q = q.Order(p.Column + " " + dir)
+		if p.Column != "id" {
+			q = q.Order("id " + dir)
+		}
+		q = q.Offset((p.Page - 1) * p.PageSize)
+		page.Page = p.Page
+	}
+
+	// ambil satu baris lebih untuk tahu masih ada halaman berikutnya
+	var rows []T
+	if err := q.Limit(p.PageSize + 1).Find(&rows).Error; err != nil {
+		return page, err
+	}
+	if len(rows) > p.PageSize {
+		rows = rows[:p.PageSize]
+		if p.Column == "id" || p.Column == "created_at" {
+			at, id := keyOf(rows[len(rows)-1])
+			page.NextCursor = listCursor{CreatedAt: at, ID: id}.String()
+		}
+	}
+	page.Data = rows
+	return page, nil
+}
+
+// applyDateRange memfilter created_at bila from/to diberikan (YYYY-MM-DD,
+// zona waktu situs, to inklusif).
+func applyDateRange(c *gin.Context, q *gorm.DB, loc *time.Location) (*gorm.DB, error) {
+	if c.Query("from") == "" && c.Query("to") == "" {
+		return q, nil
+	}
+	from, to, err := analytics.ParseDateRange(c.Query("from"), c.Query("to"), time.Now(), loc)
+	if err != nil {
+		return q, err
+	}
+	return q.Where("created_at >= ? AND created_at < ?", from, to), nil
+}
+
+// applyEquals menambahkan filter kesamaan untuk setiap query param yang diisi.
+func applyEquals(c *gin.Context, q *gorm.DB, params map[string]string) *gorm.DB {
+	for param, col := range params {
+		if v := c.Query(param); v != "" {
+			q = q.Where(col+" = ?", v)
+		}
+	}
+	return q
+}
+
+// applyBool memfilter kolom boolean dari query param "true"/"false".
+func applyBool(c *gin.Context, q *gorm.DB, param, col string) (*gorm.DB, error) {
+	if c.Query(param) == "" {
+		return q, nil
+	}
+	b, err := queryBool(c, param)
+	if err != nil {
+		return q, err
+	}
+	return q.Where(col+" = ?", b), nil
+}
+
+// queryBool membaca query param boolean; kosong berarti false.
+func queryBool(c *gin.Context, param string) (bool, error) {
+	s := c.Query(param)
+	if s == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %q", param, s)
+	}
+	return b, nil
+}
@@ -0,0 +1,109 @@
+package httpapi
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+func listContext(query string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/?"+query, nil)
+	return c
+}
+
+func TestPaginateOffsetAndCursor(t *testing.T) {
+	db := storetest.Open(t, &store.Attendance{})
+	for i := 0; i < 5; i++ {
+		db.Create(&store.Attendance{AccessID: "A001", DoorID: "D01", Arrow: "in", Status: "success"})
+	}
+	db.Create(&store.Attendance{AccessID: "A002", DoorID: "D02", Arrow: "in", Status: "denied"})
+	sortable := map[string]string{"id": "id", "created_at": "created_at"}
+	keyOf := func(a store.Attendance) (time.Time, uint) { return a.CreatedAt, a.ID }
+
+	c := listContext("page=2&page_size=2&door_id=D01")
+	p, err := parseListParams(c, sortable, "-created_at")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := applyEquals(c, db.Model(&store.Attendance{}), map[string]string{"door_id": "door_id"})
+	page, err := paginate(q, p, keyOf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 5 || len(page.Data) != 2 || page.Data[0].ID != 3 || page.NextCursor == "" {
+		t.Fatalf("offset page = total %d, ids %v, cursor %v", page.Total, page.Data, page.NextCursor)
+	}
+
+	// halaman berikutnya via cursor
+	c = listContext("cursor=" + page.NextCursor + "&page_size=2&door_id=D01")
+	p, _ = parseListParams(c, sortable, "-created_at")
+	page, _ = paginate(applyEquals(c, db.Model(&store.Attendance{}), map[string]string{"door_id": "door_id"}), p, keyOf)
+	if len(page.Data) != 1 || page.Data[0].ID != 1 || page.NextCursor != "" {
+		t.Fatalf("cursor page = %+v", page)
+	}
+
+	if _, err := parseListParams(listContext("sort=password"), sortable, "id"); err == nil {
+		t.Error("expected error for unknown sort field")
+	}
+	if _, err := parseListParams(listContext("cursor=2"), sortable, "id"); err == nil {
+		t.Error("expected error for malformed cursor")
+	}
+}
+
+// TestPaginateCursorFollowsCreatedAt memastikan cursor mengikuti kolom sort,
+// bukan id, ketika created_at tidak naik bersama id (mis. data backfill).
+func TestPaginateCursorFollowsCreatedAt(t *testing.T) {
+	db := storetest.Open(t, &store.Attendance{})
+	base := time.Date(2024, 5, 1, 8, 0, 0, 0, time.Local)
+	// id 1..5 dengan created_at +3h, +1h, +4h, +0h, +2h (tanpa seri)
+	for _, h := range []int{3, 1, 4, 0, 2} {
+		db.Create(&store.Attendance{AccessID: "A001", DoorID: "D01", Arrow: "in", Status: "success", CreatedAt: base.Add(time.Duration(h) * time.Hour)})
+	}
+	// id 6 seri dengan id 3 di +4h
+	db.Create(&store.Attendance{AccessID: "A001", DoorID: "D01", Arrow: "in", Status: "success", CreatedAt: base.Add(4 * time.Hour)})
+	sortable := map[string]string{"id": "id", "created_at": "created_at"}
+	keyOf := func(a store.Attendance) (time.Time, uint) { return a.CreatedAt, a.ID }
+
+	for _, tc := range []struct {
+		sort string
+		want []uint
+	}{
+		{"-created_at", []uint{6, 3, 1, 5, 2, 4}},
+		{"created_at", []uint{4, 2, 5, 1, 3, 6}},
+	} {
+		var got []uint
+		cursor := ""
+		for i := 0; i < 10; i++ {
+			c := listContext("page_size=2&sort=" + tc.sort + "&cursor=" + cursor)
+			p, err := parseListParams(c, sortable, "-created_at")
+			if err != nil {
+				t.Fatal(err)
+			}
+			page, err := paginate(db.Model(&store.Attendance{}), p, keyOf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, a := range page.Data {
+				got = append(got, a.ID)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("sort %s: ids %v, want %v", tc.sort, got, tc.want)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Fatalf("sort %s: ids %v, want %v", tc.sort, got, tc.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Package httpapi berisi router gin dan handler REST API dashboard.
+// Handler hanya membaca request dan menulis respons; logika ada di
+// service yang disuntikkan lewat Deps.
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/access"
+	"smart-door-lock/backend/internal/alarms"
+	"smart-door-lock/backend/internal/analytics"
+	"smart-door-lock/backend/internal/audit"
+	"smart-door-lock/backend/internal/auth"
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/devices"
+	"smart-door-lock/backend/internal/mqttbus"
+	"smart-door-lock/backend/internal/notify"
+	"smart-door-lock/backend/internal/retention"
+	"smart-door-lock/backend/internal/search"
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/users"
+)
+
+var ErrBadRequest = errors.New("invalid request")
+
+// Deps adalah semua service yang dipakai handler. Dibangun sekali di
+// main dan bisa diganti fake di test.
+type Deps struct {
+	DB        *gorm.DB
+	Store     store.Store
+	Auth      *auth.Service
+	Bootstrap *auth.Bootstrap
+	Users     *users.Service
+	Cipher    *auth.Cipher
+	Bus       mqttbus.Bus
+
+	Devices   *devices.Registry
+	Doors     *devices.Tracker
+	Control   *devices.Controller
+	Alarms    *alarms.Service
+	Notify    *notify.Dispatcher
+	Access    *access.Service
+	Occupancy *access.Occupancy
+	Audit     *audit.Logger
+
+	Rollups   *analytics.Rollups
+	Detector  *analytics.Detector
+	Stats     *analytics.Stats
+	Search    *search.Index
+	Retention *retention.Service
+}
+
+// Server menyimpan konfigurasi dan dependency handler.
+type Server struct {
+	Deps
+	cfg config.Config
+}
+
+func NewServer(cfg config.Config, deps Deps) *Server {
+	return &Server{Deps: deps, cfg: cfg}
+}
+
+// Router mendaftarkan semua route. Login dan health tidak memerlukan token;
+// sisanya melewati auth dan audit middleware.
+func (s *Server) Router() *gin.Engine {
+	r := gin.Default()
+	r.Use(cors.New(cors.Config{
+		AllowOrigins: s.cfg.CORSOrigins,
+		AllowMethods: []string{"GET", "POST", "DELETE", "PUT", "PATCH", "OPTIONS"},
+		AllowHeaders: []string{"Authorization", "Content-Type"},
+	}))
+
+	r.GET("/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Doorlock Backend API",
+			"version": "1.0.0",
+		})
+	})
+
+	api := r.Group("/api")
+
+	// ====== LOGIN ======
+	api.POST("/login", s.login)
+	api.POST("/encrypt-test", s.encryptTest)
+	api.POST("/login-simple", s.loginSimple)
+
+	// ====== SETUP PERTAMA KALI ======
+	api.GET("/setup", s.setupStatus)
+	api.POST("/setup", s.setup)
+
+	// Protected routes
+	api.Use(s.Auth.Middleware(), s.Audit.Middleware())
+	adminOnly := auth.RequireAdmin()
+
+	// ====== DEVICE STATUS ENDPOINTS (REST API BYPASS MQTT) ======
+	api.GET("/device/status", s.deviceStatus)
+	api.POST("/device/status/door", s.updateDoorStatus)
+	api.POST("/device/status/reader", s.updateReaderStatus)
+	api.POST("/device/status/pinpad", s.updatePinpadStatus)
+	api.POST("/device/status/buzzer", s.updateBuzzerStatus)
+	api.POST("/device/events/attendance", s.simulateAttendance)
+	api.POST("/device/events/alarm", s.simulateAlarm)
+
+	// ====== USER MANAGEMENT ======
+	userGroup := api.Group("/users", adminOnly)
+	userGroup.GET("/", s.listUsers)
+	userGroup.POST("/", s.createUser)
+	userGroup.PUT("/:id", s.updateUser)
+	userGroup.DELETE("/:id", s.deleteUser)
+
+	// ====== DOORLOCK USERS ======
+	doorlock := api.Group("/doorlock")
+	doorlock.GET("/users", s.listDoorlockUsers)
+	doorlock.POST("/users", s.createDoorlockUser)
+	doorlock.POST("/users/import", s.importDoorlockUsers)
+	doorlock.GET("/users/export", s.exportDoorlockUsers)
+	doorlock.PUT("/users/:access_id", s.updateDoorlockUser(true))
+	doorlock.PATCH("/users/:access_id", s.updateDoorlockUser(false))
+	doorlock.DELETE("/users/:access_id", s.deleteDoorlockUser)
+	doorlock.POST("/users/:access_id/pin", s.rotateDoorlockPin)
+	doorlock.POST("/users/:access_id/suspend", s.suspendDoorlockUser)
+	doorlock.POST("/users/:access_id/resume", s.resumeDoorlockUser)
+
+	// ====== DOOR CONFIGURATION ======
+	api.GET("/doors", s.listDoors)
+	api.GET("/doors/:door_id", s.getDoor)
+	api.PUT("/doors/:door_id", s.updateDoor)
+
+	// ====== ATTENDANCE & OCCUPANCY ======
+	api.POST("/attendance", s.recordAttendance)
+	api.GET("/attendance", s.listAttendance)
+	api.GET("/attendance/summary", s.attendanceSummary)
+	api.GET("/occupancy", s.occupancy)
+	api.GET("/occupancy/headcount", s.headcount)
+	api.GET("/occupancy/:door_id", s.doorOccupancy)
+
+	// ====== REPORTS, SEARCH & ANALYTICS ======
+	api.GET("/reports/attendance", s.attendanceReport)
+	api.GET("/search", s.search)
+	api.GET("/analytics/timeseries", s.timeseries)
+	api.GET("/analytics/heatmap", s.heatmap)
+	api.GET("/analytics/peak-hours", s.peakHours)
+	api.GET("/export/:kind", s.export)
+
+	// ====== ALARM ======
+	api.POST("/alarm", s.raiseAlarm)
+	api.GET("/alarms", s.listAlarms)
+	api.POST("/alarms/:id/ack", s.alarmAction("ack", s.ackAlarm))
+	api.POST("/alarms/:id/assign", s.alarmAction("assign", s.assignAlarm))
+	api.POST("/alarms/:id/resolve", s.alarmAction("resolve", s.resolveAlarm))
+
+	// ====== NOTIFICATION RULES ======
+	// rule dan test mengirim request ke URL pilihan pemanggil (webhook,
+	// ntfy, gotify), jadi hanya admin
+	notif := api.Group("/notifications", adminOnly)
+	notif.GET("/rules", s.listRules)
+	notif.POST("/rules", s.createRule)
+	notif.PUT("/rules/:id", s.updateRule)
+	notif.DELETE("/rules/:id", s.deleteRule)
+	notif.GET("/outbox", s.listOutbox)
+	notif.POST("/test", s.testNotification)
+
+	api.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":    "healthy",
+			"timestamp": time.Now(),
+			"service":   "doorlock-backend",
+		})
+	})
+
+	// ====== TREND ANALYSIS ENDPOINTS ======
+	api.GET("/trends/frequent-access", s.frequentAccess)
+	api.GET("/trends/access-frequency", s.accessFrequency)
+	api.GET("/trends/anomalies", s.anomalies)
+	api.GET("/trends/long-open-doors", s.longOpenDoors)
+	api.POST("/trends/door-open-log", s.createDoorOpenLog)
+
+	// ====== AUDIT LOG & RETENTION ======
+	api.GET("/audit", s.listAudit)
+	api.GET("/audit/verify", s.verifyAudit)
+	api.GET("/retention/dry-run", adminOnly, s.retentionDryRun)
+	api.POST("/retention/run", adminOnly, s.retentionRun)
+
+	// ====== MQTT CONTROL ENDPOINTS ======
+	api.POST("/control/doorlock", s.controlDoorlock)
+	api.GET("/control/logs", s.commandLogs)
+	api.GET("/mqtt-test", s.mqttTest)
+	api.POST("/control/buzzer", s.controlBuzzer)
+
+	// ====== DASHBOARD STATISTICS ======
+	api.GET("/dashboard/stats", s.dashboardStats)
+
+	return r
+}
+
+func stringToUint(s string) uint {
+	var i uint
+	fmt.Sscanf(s, "%d", &i)
+	return i
+}
@@ -0,0 +1,730 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/access"
+	"smart-door-lock/backend/internal/alarms"
+	"smart-door-lock/backend/internal/analytics"
+	"smart-door-lock/backend/internal/audit"
+	"smart-door-lock/backend/internal/auth"
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/devices"
+	"smart-door-lock/backend/internal/mqttbus"
+	"smart-door-lock/backend/internal/mqttbus/mqtttest"
+	"smart-door-lock/backend/internal/notify"
+	"smart-door-lock/backend/internal/notify/notifytest"
+	"smart-door-lock/backend/internal/retention"
+	"smart-door-lock/backend/internal/search"
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+	"smart-door-lock/backend/internal/users"
+)
+
+// Chat Telegram bawaan di test; notifikasi tanpa aturan dikirim ke sini.
+const testChatID = 42
+
+// testEnv adalah server lengkap di atas SQLite in-memory, fake MQTT dan
+// fake channel Telegram.
+type testEnv struct {
+	t          *testing.T
+	db         *gorm.DB
+	cfg        config.Config
+	router     *gin.Engine
+	mqtt       *mqtttest.Client
+	telegram   *notifytest.Recorder
+	dispatcher *notify.Dispatcher
+	bootstrap  *auth.Bootstrap
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db := storetest.Open(t, store.Models()...)
+	adminHash, err := auth.HashPassword("admin123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&store.User{Username: "admin", Password: adminHash, Role: "admin", IsActive: true})
+	db.Create(&store.DoorlockUser{Name: "Budi", AccessID: "A001", DoorID: "D01", Pin: "123456", IsActive: true})
+
+	cfg := config.Default()
+	cfg.JWTSecret = []byte("test-jwt-secret-0123456789abcdef")
+	cfg.AESKey = []byte("0123456789abcdef0123456789abcdef")
+	cfg.Retention.ArchiveDir = t.TempDir()
+
+	fake := mqtttest.New()
+	bus := mqttbus.New(fake)
+	recorder := &notifytest.Recorder{}
+	dispatcher := notify.NewDispatcher(db, testChatID, 0)
+	dispatcher.Register(notify.ChannelTelegram, recorder)
+
+	st := store.NewGorm(db)
+	alarmSvc := alarms.NewService(st, dispatcher, bus, cfg.AlarmEscalateAfter, cfg.Location)
+	registry := devices.NewRegistry()
+	doors := devices.NewTracker(st, alarmSvc, dispatcher, cfg.Location)
+	control := devices.NewController(st, bus)
+	occupancy := access.NewOccupancy(db, st, cfg.Location)
+	tokens := auth.NewTokens(cfg.JWTSecret)
+	rollups := analytics.NewRollups(db, cfg.Location)
+	bootstrap := auth.NewBootstrap(st)
+
+	srv := NewServer(cfg, Deps{
+		DB:        db,
+		Store:     st,
+		Auth:      auth.NewService(st, tokens),
+		Bootstrap: bootstrap,
+		Users:     users.NewService(db, st),
+		Cipher:    auth.NewCipher(cfg.AESKey),
+		Bus:       bus,
+		Devices:   registry,
+		Doors:     doors,
+		Control:   control,
+		Alarms:    alarmSvc,
+		Notify:    dispatcher,
+		Access:    access.NewService(st, alarmSvc, control, doors, occupancy),
+		Occupancy: occupancy,
+		Audit:     audit.New(db),
+		Rollups:   rollups,
+		Detector:  analytics.NewDetector(db, rollups, alarmSvc),
+		Stats:     analytics.NewStats(db, cfg.Location, registry.Snapshot, doors.OpenSince),
+		Search:    search.NewIndex(db, cfg.Location),
+		Retention: retention.New(db, cfg.Retention, cfg.Location),
+	})
+
+	return &testEnv{
+		t:          t,
+		db:         db,
+		cfg:        cfg,
+		router:     srv.Router(),
+		mqtt:       fake,
+		telegram:   recorder,
+		dispatcher: dispatcher,
+		bootstrap:  bootstrap,
+	}
+}
+
+// do mengirim request JSON; token kosong berarti tanpa Authorization.
+func (e *testEnv) do(method, path, token string, body any) *httptest.ResponseRecorder {
+	e.t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			e.t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	e.router.ServeHTTP(w, req)
+	return w
+}
+
+// login memakai /api/login-simple dan mengembalikan token.
+func (e *testEnv) login(username, password string) string {
+	e.t.Helper()
+	w := e.do(http.MethodPost, "/api/login-simple", "", gin.H{"username": username, "password": password})
+	if w.Code != http.StatusOK {
+		e.t.Fatalf("login %s: status %d: %s", username, w.Code, w.Body.String())
+	}
+	var resp struct {
+		Token string `json:"token"`
+	}
+	decode(e.t, w, &resp)
+	return resp.Token
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+}
+
+func TestLoginSimple(t *testing.T) {
+	e := newTestEnv(t)
+
+	w := e.do(http.MethodPost, "/api/login-simple", "", gin.H{"username": "admin", "password": "salah"})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password: status %d", w.Code)
+	}
+
+	var resp struct {
+		Token    string `json:"token"`
+		Username string `json:"username"`
+		Role     string `json:"role"`
+	}
+	w = e.do(http.MethodPost, "/api/login-simple", "", gin.H{"username": "admin", "password": "admin123"})
+	decode(t, w, &resp)
+	if w.Code != http.StatusOK || resp.Token == "" || resp.Role != "admin" {
+		t.Fatalf("login: status %d, resp %+v", w.Code, resp)
+	}
+
+	var actions []string
+	e.db.Model(&store.AuditEvent{}).Order("id").Pluck("action", &actions)
+	if strings.Join(actions, ",") != audit.LoginFailed+","+audit.Login {
+		t.Errorf("audit actions = %v", actions)
+	}
+}
+
+func TestLoginEncrypted(t *testing.T) {
+	e := newTestEnv(t)
+	cipher := auth.NewCipher(e.cfg.AESKey)
+
+	payload, _ := cipher.Encrypt(`{"username":"admin","password":"admin123"}`)
+	w := e.do(http.MethodPost, "/api/login", "", gin.H{"data": payload})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+
+	var enc struct {
+		Data string `json:"data"`
+	}
+	decode(t, w, &enc)
+	plain, err := cipher.Decrypt(enc.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp struct {
+		Token    string `json:"token"`
+		Username string `json:"username"`
+	}
+	if err := json.Unmarshal([]byte(plain), &resp); err != nil || resp.Token == "" || resp.Username != "admin" {
+		t.Fatalf("decrypted response %q (%v)", plain, err)
+	}
+
+	if w := e.do(http.MethodPost, "/api/login", "", gin.H{"data": "bukan-base64"}); w.Code != http.StatusBadRequest {
+		t.Errorf("garbage payload: status %d", w.Code)
+	}
+}
+
+func TestFirstRunSetup(t *testing.T) {
+	e := newTestEnv(t)
+	e.db.Where("username = ?", "admin").Delete(&store.User{})
+
+	var status struct {
+		SetupRequired bool `json:"setup_required"`
+	}
+	decode(t, e.do(http.MethodGet, "/api/setup", "", nil), &status)
+	if !status.SetupRequired {
+		t.Fatal("setup should be required without an admin")
+	}
+
+	token, err := e.bootstrap.Token()
+	if err != nil || token == "" {
+		t.Fatalf("Token = %q, %v", token, err)
+	}
+	strong := "Pintu-Kantor-2026"
+	if w := e.do(http.MethodPost, "/api/setup", "", gin.H{"token": "salah", "username": "root", "password": strong}); w.Code != http.StatusForbidden {
+		t.Errorf("wrong token: status %d", w.Code)
+	}
+	if w := e.do(http.MethodPost, "/api/setup", "", gin.H{"token": token, "username": "root", "password": "admin123"}); w.Code != http.StatusBadRequest {
+		t.Errorf("weak password: status %d", w.Code)
+	}
+	if w := e.do(http.MethodPost, "/api/setup", "", gin.H{"token": token, "username": "root", "password": strong}); w.Code != http.StatusCreated {
+		t.Fatalf("setup: status %d: %s", w.Code, w.Body.String())
+	}
+
+	// token sekali pakai dan setup tertutup setelah ada admin
+	if w := e.do(http.MethodPost, "/api/setup", "", gin.H{"token": token, "username": "root2", "password": strong}); w.Code != http.StatusConflict {
+		t.Errorf("second setup: status %d", w.Code)
+	}
+	if w := e.do(http.MethodGet, "/api/doors", e.login("root", strong), nil); w.Code != http.StatusOK {
+		t.Errorf("new admin cannot use API: status %d", w.Code)
+	}
+
+	var ev store.AuditEvent
+	e.db.Where("action = ?", audit.SetupAdmin).First(&ev)
+	if ev.Actor != "root" {
+		t.Errorf("setup audit event = %+v", ev)
+	}
+}
+
+func TestProtectedRoutesRequireToken(t *testing.T) {
+	e := newTestEnv(t)
+
+	if w := e.do(http.MethodGet, "/api/doors", "", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("no token: status %d", w.Code)
+	}
+	if w := e.do(http.MethodGet, "/api/doors", "invalid", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("bad token: status %d", w.Code)
+	}
+	if w := e.do(http.MethodGet, "/api/health", "", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("health without token: status %d", w.Code)
+	}
+	if w := e.do(http.MethodGet, "/api/doors", e.login("admin", "admin123"), nil); w.Code != http.StatusOK {
+		t.Errorf("with token: status %d", w.Code)
+	}
+}
+
+// userToken membuat user dashboard biasa (role=user) lalu login.
+func (e *testEnv) userToken(username string) string {
+	e.t.Helper()
+	svc := users.NewService(e.db, store.NewGorm(e.db))
+	if _, err := svc.Create(username, "rahasia", store.RoleUser); err != nil {
+		e.t.Fatal(err)
+	}
+	return e.login(username, "rahasia")
+}
+
+func TestAdminOnlyRoutes(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.userToken("budi")
+
+	for _, r := range []struct{ method, path string }{
+		{http.MethodGet, "/api/retention/dry-run"},
+		{http.MethodPost, "/api/retention/run"},
+		{http.MethodGet, "/api/users/"},
+		{http.MethodPost, "/api/users/"},
+		{http.MethodPut, "/api/users/2"},
+		{http.MethodDelete, "/api/users/1"},
+		{http.MethodPost, "/api/notifications/test"},
+		{http.MethodPost, "/api/notifications/rules"},
+		{http.MethodGet, "/api/notifications/outbox"},
+	} {
+		if w := e.do(r.method, r.path, token, nil); w.Code != http.StatusForbidden {
+			t.Errorf("%s %s as user: status %d", r.method, r.path, w.Code)
+		}
+	}
+	// user tidak bisa menaikkan role-nya sendiri
+	e.do(http.MethodPut, "/api/users/2", token, gin.H{"username": "budi", "role": "admin", "is_active": true})
+	var budi store.User
+	e.db.First(&budi, "username = ?", "budi")
+	if budi.Role != store.RoleUser {
+		t.Errorf("budi role after self-promotion = %q", budi.Role)
+	}
+	if w := e.do(http.MethodGet, "/api/retention/dry-run", e.login("admin", "admin123"), nil); w.Code != http.StatusOK {
+		t.Errorf("dry-run as admin: status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCreateRuleDefaultsToEnabled(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.login("admin", "admin123")
+
+	var rule store.NotificationRule
+	w := e.do(http.MethodPost, "/api/notifications/rules", token, gin.H{"name": "ops", "event": "alarm", "channel": "telegram"})
+	decode(t, w, &rule)
+	if w.Code != http.StatusCreated || !rule.Enabled {
+		t.Fatalf("create without enabled: status %d, rule %+v", w.Code, rule)
+	}
+
+	// PUT tanpa enabled mempertahankan nilai lama
+	w = e.do(http.MethodPut, fmt.Sprintf("/api/notifications/rules/%d", rule.ID), token, gin.H{"name": "ops 2", "event": "alarm", "channel": "telegram"})
+	decode(t, w, &rule)
+	if w.Code != http.StatusOK || !rule.Enabled || rule.Name != "ops 2" {
+		t.Errorf("update without enabled: status %d, rule %+v", w.Code, rule)
+	}
+
+	w = e.do(http.MethodPost, "/api/notifications/rules", token, gin.H{"name": "off", "event": "alarm", "channel": "telegram", "enabled": false})
+	decode(t, w, &rule)
+	if w.Code != http.StatusCreated || rule.Enabled {
+		t.Errorf("create with enabled=false: status %d, rule %+v", w.Code, rule)
+	}
+}
+
+func TestControlDoorlockPublishesCommand(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.login("admin", "admin123")
+
+	w := e.do(http.MethodPost, "/api/control/doorlock", token, gin.H{"door_id": "D01", "command": "unlock"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+
+	msgs := e.mqtt.Published()
+	if len(msgs) != 1 || msgs[0].Topic != "doorlock/D01/control" {
+		t.Fatalf("published %+v, want one message on doorlock/D01/control", msgs)
+	}
+	var payload struct {
+		Command   string `json:"command"`
+		Timestamp string `json:"timestamp"`
+	}
+	if err := json.Unmarshal([]byte(msgs[0].Payload), &payload); err != nil || payload.Command != "unlock" || payload.Timestamp == "" {
+		t.Fatalf("payload %q (%v)", msgs[0].Payload, err)
+	}
+
+	// perintah tidak mengubah status; pintu yang melaporkan terbuka
+	var status map[string]any
+	decode(t, e.do(http.MethodGet, "/api/device/status", token, nil), &status)
+	if status["door"] != "closed" {
+		t.Errorf("device door status after command = %v, want closed until reported", status["door"])
+	}
+	e.do(http.MethodPost, "/api/device/status/door", token, gin.H{"door_id": "D01", "status": "open"})
+	decode(t, e.do(http.MethodGet, "/api/device/status", token, nil), &status)
+	if status["door"] != "open" {
+		t.Errorf("device door status after report = %v, want open", status["door"])
+	}
+
+	var logs []store.CommandLog
+	e.db.Find(&logs)
+	if len(logs) != 1 || logs[0].Actor != "admin" || logs[0].Command != "door:unlock" || logs[0].Result != "success" {
+		t.Errorf("command logs = %+v", logs)
+	}
+}
+
+func TestControlDoorlockWithoutBroker(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.login("admin", "admin123")
+	e.mqtt.SetConnected(false)
+
+	// perintah yang tidak terkirim dilaporkan sebagai gagal
+	w := e.do(http.MethodPost, "/api/control/doorlock", token, gin.H{"door_id": "D01", "command": "lock"})
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	if msgs := e.mqtt.Published(); len(msgs) != 0 {
+		t.Errorf("published %+v while disconnected", msgs)
+	}
+}
+
+func TestControlBuzzer(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.login("admin", "admin123")
+
+	w := e.do(http.MethodPost, "/api/control/buzzer", token, gin.H{"buzzer_id": "B01", "command": "on", "duration": 5})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	msgs := e.mqtt.Published()
+	if len(msgs) != 1 || msgs[0].Topic != "buzzer/B01/control" {
+		t.Fatalf("published %+v", msgs)
+	}
+	var payload struct {
+		Command  string `json:"command"`
+		Duration int    `json:"duration"`
+	}
+	if err := json.Unmarshal([]byte(msgs[0].Payload), &payload); err != nil || payload.Command != "on" || payload.Duration != 5 {
+		t.Fatalf("payload %q (%v)", msgs[0].Payload, err)
+	}
+
+	e.mqtt.FailPublish(errors.New("broker menolak"))
+	w = e.do(http.MethodPost, "/api/control/buzzer", token, gin.H{"buzzer_id": "B01", "command": "off"})
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("failed publish: status %d", w.Code)
+	}
+
+	var logs []store.CommandLog
+	e.db.Order("id").Find(&logs)
+	if len(logs) != 2 || logs[0].Result != "success" || !strings.HasPrefix(logs[1].Result, "failed: ") {
+		t.Errorf("command logs = %+v", logs)
+	}
+}
+
+func TestAlarmEndpointNotifiesAndPublishes(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.login("admin", "admin123")
+
+	w := e.do(http.MethodPost, "/api/alarm", token, gin.H{"alarm_type": store.AlarmTypeDoorOpenTooLong, "access_id": "A001", "door_id": "D01"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := e.do(http.MethodPost, "/api/alarm", token, gin.H{"alarm_type": store.AlarmTypeDoorOpenTooLong, "access_id": "X999"}); w.Code != http.StatusNotFound {
+		t.Errorf("unknown access_id: status %d", w.Code)
+	}
+
+	e.dispatcher.ProcessOutbox(time.Now())
+	sent := e.telegram.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(sent))
+	}
+	n := sent[0]
+	if n.Target != "42" || n.Notification.DoorID != "D01" || n.Notification.Severity != store.SeverityMedium {
+		t.Errorf("notification = %+v", n)
+	}
+	for _, want := range []string{"ALARM TERDETEKSI", "Nama: Budi", "Access ID: A001", "Pintu: D01"} {
+		if !strings.Contains(n.Notification.Body, want) {
+			t.Errorf("body missing %q:\n%s", want, n.Notification.Body)
+		}
+	}
+
+	msg, ok := e.mqtt.WaitFor("alarms/new", 2*time.Second)
+	if !ok {
+		t.Fatal("alarms/new not published")
+	}
+	var al store.Alarm
+	if err := json.Unmarshal([]byte(msg.Payload), &al); err != nil || al.AccessID != "A001" || al.Status != store.AlarmStatusOpen {
+		t.Errorf("alarms/new payload %q (%v)", msg.Payload, err)
+	}
+}
+
+func TestAlarmLifecycle(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.login("admin", "admin123")
+	e.db.Create(&store.Alarm{AlarmType: store.AlarmTypeFailedAttempts, Status: store.AlarmStatusOpen, Severity: store.SeverityHigh})
+
+	if w := e.do(http.MethodPost, "/api/alarms/1/assign", token, gin.H{}); w.Code != http.StatusBadRequest {
+		t.Errorf("assign without assignee: status %d", w.Code)
+	}
+
+	var al store.Alarm
+	w := e.do(http.MethodPost, "/api/alarms/1/ack", token, gin.H{"note": "dicek"})
+	decode(t, w, &al)
+	if w.Code != http.StatusOK || al.Status != store.AlarmStatusAcknowledged || al.AcknowledgedBy != "admin" {
+		t.Fatalf("ack: status %d, alarm %+v", w.Code, al)
+	}
+
+	if w := e.do(http.MethodPost, "/api/alarms/1/resolve", token, nil); w.Code != http.StatusOK {
+		t.Fatalf("resolve: status %d", w.Code)
+	}
+	if w := e.do(http.MethodPost, "/api/alarms/1/resolve", token, nil); w.Code != http.StatusConflict {
+		t.Errorf("second resolve: status %d", w.Code)
+	}
+	if w := e.do(http.MethodPost, "/api/alarms/99/ack", token, nil); w.Code != http.StatusNotFound {
+		t.Errorf("unknown alarm: status %d", w.Code)
+	}
+
+	var count int64
+	e.db.Model(&store.AuditEvent{}).Where("action LIKE ?", audit.AlarmPrefix+"%").Count(&count)
+	if count != 2 {
+		t.Errorf("alarm audit events = %d, want 2", count)
+	}
+}
+
+func TestUserManagement(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.login("admin", "admin123")
+
+	w := e.do(http.MethodPost, "/api/users/", token, gin.H{"username": "budi", "password": "rahasia", "role": "user"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "$2a$") {
+		t.Error("password hash leaked in response")
+	}
+	if w := e.do(http.MethodPost, "/api/users/", token, gin.H{"username": "budi", "password": "x"}); w.Code != http.StatusConflict {
+		t.Errorf("duplicate: status %d", w.Code)
+	}
+	e.login("budi", "rahasia")
+
+	var page struct {
+		Data  []store.User `json:"data"`
+		Total int64        `json:"total"`
+	}
+	decode(t, e.do(http.MethodGet, "/api/users/?role=user", token, nil), &page)
+	if page.Total != 1 || page.Data[0].Username != "budi" {
+		t.Errorf("list users = %+v", page)
+	}
+
+	if w := e.do(http.MethodDelete, "/api/users/1", token, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("delete own account: status %d", w.Code)
+	}
+	if w := e.do(http.MethodDelete, "/api/users/2", token, nil); w.Code != http.StatusOK {
+		t.Errorf("delete budi: status %d", w.Code)
+	}
+}
+
+func TestRevokedSessionsRejected(t *testing.T) {
+	e := newTestEnv(t)
+	svc := users.NewService(e.db, store.NewGorm(e.db))
+	if _, err := svc.Create("budi", "rahasia", store.RoleUser); err != nil {
+		t.Fatal(err)
+	}
+	admin := e.login("admin", "admin123")
+	budi := e.login("budi", "rahasia")
+
+	if _, err := svc.RevokeSessions("admin"); err != nil {
+		t.Fatal(err)
+	}
+	if w := e.do(http.MethodGet, "/api/users/", admin, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked token: status %d", w.Code)
+	}
+	if w := e.do(http.MethodGet, "/api/users/", e.login("admin", "admin123"), nil); w.Code != http.StatusOK {
+		t.Errorf("fresh token: status %d", w.Code)
+	}
+
+	if _, err := svc.SetActive("budi", false); err != nil {
+		t.Fatal(err)
+	}
+	if w := e.do(http.MethodGet, "/api/users/", budi, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("disabled user token: status %d", w.Code)
+	}
+}
+
+func TestDoorlockImportExport(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.login("admin", "admin123")
+
+	rows := []gin.H{
+		{"name": "Ani", "access_id": "B001", "door_id": "D01", "pin": "2580"},
+		{"name": "Budi", "access_id": "A001", "door_id": "D01", "pin": "1111"},
+		{"name": "Citra", "access_id": "B002", "door_id": "D77", "pin": "4827"},
+	}
+	var resp struct {
+		Report users.ImportReport `json:"report"`
+	}
+	w := e.do(http.MethodPost, "/api/doorlock/users/import?dry_run=true", token, rows)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid atomic import: status %d: %s", w.Code, w.Body.String())
+	}
+	decode(t, w, &resp)
+	if resp.Report.Failed != 2 || resp.Report.Rows[0].Action != users.ImportCreate || len(resp.Report.Rows[1].Errors) != 2 {
+		t.Errorf("report = %+v", resp.Report)
+	}
+
+	w = e.do(http.MethodPost, "/api/doorlock/users/import?mode=partial", token, rows)
+	decode(t, w, &resp)
+	if w.Code != http.StatusOK || !resp.Report.Applied || resp.Report.Created != 1 {
+		t.Fatalf("partial import: status %d: %+v", w.Code, resp.Report)
+	}
+
+	csvBody := "name,access_id,door_id,pin,expires_at\nDedi,B003,D01,4827,2099-12-31\n"
+	req := httptest.NewRequest(http.MethodPost, "/api/doorlock/users/import", strings.NewReader(csvBody))
+	req.Header.Set("Content-Type", "text/csv")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	e.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("csv import: status %d: %s", w.Code, w.Body.String())
+	}
+
+	var count int64
+	e.db.Model(&store.DoorlockUser{}).Count(&count)
+	if count != 3 {
+		t.Errorf("doorlock users = %d, want 3", count)
+	}
+	e.db.Model(&store.AuditEvent{}).Where("action = ?", audit.DoorlockImport).Count(&count)
+	if count != 2 {
+		t.Errorf("import audit events = %d, want 2", count)
+	}
+
+	w = e.do(http.MethodGet, "/api/doorlock/users/export", token, nil)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), "name,access_id,door_id,is_active,expires_at\n") ||
+		strings.Contains(w.Body.String(), "2580") {
+		t.Errorf("export: status %d: %s", w.Code, w.Body.String())
+	}
+	w = e.do(http.MethodGet, "/api/doorlock/users/export?format=json&with_pins=true", token, nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"pin":"2580"`) || !strings.Contains(w.Body.String(), `"expires_at":"2099-12-31T17:00:00Z"`) {
+		t.Errorf("export with pins: status %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := e.do(http.MethodGet, "/api/doorlock/users/export?with_pins=true", e.userToken("budi"), nil); w.Code != http.StatusForbidden {
+		t.Errorf("non-admin export with pins: status %d", w.Code)
+	}
+}
+
+func TestDoorlockUserLifecycle(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.login("admin", "admin123")
+
+	if w := e.do(http.MethodPut, "/api/doorlock/users/A001", token, gin.H{"name": "Budi"}); w.Code != http.StatusBadRequest {
+		t.Errorf("PUT without door_id: status %d", w.Code)
+	}
+	if w := e.do(http.MethodPatch, "/api/doorlock/users/A001", token, gin.H{"door_id": "D02"}); w.Code != http.StatusBadRequest {
+		t.Errorf("PATCH to unknown door: status %d", w.Code)
+	}
+	for _, door := range []string{"D01", "D02"} {
+		if w := e.do(http.MethodPut, "/api/doors/"+door, token, gin.H{"name": "Pintu " + door}); w.Code != http.StatusOK {
+			t.Fatalf("configure %s: status %d", door, w.Code)
+		}
+	}
+	expires := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	var u store.DoorlockUser
+	w := e.do(http.MethodPut, "/api/doorlock/users/A001", token, gin.H{"name": "Budi S.", "door_id": "D02", "expires_at": expires})
+	decode(t, w, &u)
+	if w.Code != http.StatusOK || u.Name != "Budi S." || u.DoorID != "D02" || u.ExpiresAt == nil {
+		t.Fatalf("PUT: status %d: %s", w.Code, w.Body.String())
+	}
+	w = e.do(http.MethodPatch, "/api/doorlock/users/A001", token, gin.H{"door_id": "D01"})
+	decode(t, w, &u)
+	if w.Code != http.StatusOK || u.Name != "Budi S." || u.DoorID != "D01" || u.ExpiresAt == nil {
+		t.Fatalf("PATCH: status %d: %s", w.Code, w.Body.String())
+	}
+	// PIN hanya keluar lewat export admin dan respons rotasi
+	for _, body := range []string{w.Body.String(), e.do(http.MethodGet, "/api/doorlock/users", token, nil).Body.String()} {
+		if strings.Contains(body, "123456") || strings.Contains(body, `"pin"`) {
+			t.Errorf("PIN leaked: %s", body)
+		}
+	}
+
+	var rotated struct {
+		Pin string `json:"pin"`
+	}
+	w = e.do(http.MethodPost, "/api/doorlock/users/A001/pin", token, nil)
+	decode(t, w, &rotated)
+	if w.Code != http.StatusOK || len(rotated.Pin) != users.MaxPinLength {
+		t.Fatalf("rotate pin: status %d: %s", w.Code, w.Body.String())
+	}
+	if w := e.do(http.MethodPost, "/api/doorlock/users/A001/pin", token, gin.H{"pin": "1111"}); w.Code != http.StatusBadRequest {
+		t.Errorf("weak pin: status %d", w.Code)
+	}
+
+	if w := e.do(http.MethodPost, "/api/doorlock/users/A001/suspend", token, gin.H{}); w.Code != http.StatusBadRequest {
+		t.Errorf("suspend without reason: status %d", w.Code)
+	}
+	if w := e.do(http.MethodPost, "/api/doorlock/users/A001/suspend", token, gin.H{"reason": "kartu hilang"}); w.Code != http.StatusOK {
+		t.Fatalf("suspend: status %d: %s", w.Code, w.Body.String())
+	}
+	w = e.do(http.MethodPost, "/api/attendance", token, gin.H{"access_id": "A001", "door_id": "D01", "pin": rotated.Pin, "arrow": "in"})
+	if !strings.Contains(w.Body.String(), access.DenySuspended) {
+		t.Errorf("suspended access: %s", w.Body.String())
+	}
+	if w := e.do(http.MethodPost, "/api/doorlock/users/A001/resume", token, gin.H{"reason": "kartu ditemukan"}); w.Code != http.StatusOK {
+		t.Errorf("resume: status %d: %s", w.Code, w.Body.String())
+	}
+	var resumed store.AuditEvent
+	e.db.Where("action = ?", audit.DoorlockResume).First(&resumed)
+	if strings.Contains(resumed.Before+resumed.After, rotated.Pin) || !strings.Contains(resumed.After, "kartu ditemukan") {
+		t.Errorf("resume audit snapshot = %s / %s", resumed.Before, resumed.After)
+	}
+
+	if w := e.do(http.MethodDelete, "/api/doorlock/users/A001", token, nil); w.Code != http.StatusOK {
+		t.Fatalf("delete: status %d", w.Code)
+	}
+	var page struct {
+		Total int64 `json:"total"`
+	}
+	decode(t, e.do(http.MethodGet, "/api/doorlock/users?deleted=true", token, nil), &page)
+	if page.Total != 1 {
+		t.Errorf("deleted users = %d, want 1", page.Total)
+	}
+	if w := e.do(http.MethodPatch, "/api/doorlock/users/A001", token, gin.H{"name": "x"}); w.Code != http.StatusNotFound {
+		t.Errorf("PATCH deleted user: status %d", w.Code)
+	}
+
+	var count int64
+	e.db.Model(&store.AuditEvent{}).Where("action LIKE ?", "doorlock_user.%").Count(&count)
+	if count != 6 {
+		t.Errorf("doorlock audit events = %d, want 6", count)
+	}
+}
+
+func TestUpdateDoorValidatesThresholds(t *testing.T) {
+	e := newTestEnv(t)
+	token := e.login("admin", "admin123")
+
+	if w := e.do(http.MethodPut, "/api/doors/D01", token, gin.H{"failed_attempt_limit": -1}); w.Code != http.StatusBadRequest {
+		t.Errorf("negative limit: status %d", w.Code)
+	}
+	if w := e.do(http.MethodPut, "/api/doors/D01", token, gin.H{"anti_passback": "strict"}); w.Code != http.StatusBadRequest {
+		t.Errorf("bad anti_passback: status %d", w.Code)
+	}
+
+	var door store.Door
+	w := e.do(http.MethodPut, "/api/doors/D01", token, gin.H{"name": "Lobby", "zone": "lantai-1", "failed_attempt_limit": 3})
+	decode(t, w, &door)
+	if w.Code != http.StatusOK || door.Name != "Lobby" || door.AntiPassback != access.AntiPassbackOff {
+		t.Fatalf("update: status %d, door %+v", w.Code, door)
+	}
+
+	decode(t, e.do(http.MethodGet, "/api/doors/D01", token, nil), &door)
+	if door.Zone != "lantai-1" || door.FailedAttemptLimit != 3 {
+		t.Errorf("stored door = %+v", door)
+	}
+}
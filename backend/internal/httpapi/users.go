@@ -0,0 +1,504 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"smart-door-lock/backend/internal/access"
+	"smart-door-lock/backend/internal/audit"
+	"smart-door-lock/backend/internal/auth"
+	"smart-door-lock/backend/internal/devices"
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/users"
+)
+
+// ====== USER MANAGEMENT ======
+
+func (s *Server) listUsers(c *gin.Context) {
+	params, err := parseListParams(c, map[string]string{
+		"id": "id", "username": "username", "role": "role", "created_at": "created_at",
+	}, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	q := applyEquals(c, s.DB.Model(&store.User{}), map[string]string{"role": "role"})
+	if q, err = applyBool(c, q, "is_active", "is_active"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if term := c.Query("q"); term != "" {
+		q = q.Where(store.Like("username"), "%"+term+"%")
+	}
+
+	page, err := paginate(q, params, func(u store.User) (time.Time, uint) { return u.CreatedAt, u.ID })
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch users"})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// userError memetakan error users.Service ke status HTTP.
+func userError(err error) int {
+	switch {
+	case errors.Is(err, users.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, users.ErrExists):
+		return http.StatusConflict
+	case errors.Is(err, users.ErrInvalid), errors.Is(err, users.ErrSelfDelete),
+		errors.Is(err, auth.ErrWeakPassword), errors.Is(err, auth.ErrPasswordTooLong),
+		errors.Is(err, auth.ErrUsernameRequired):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (s *Server) createUser(c *gin.Context) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Role     string `json:"role"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	user, err := s.Users.Create(req.Username, req.Password, req.Role)
+	if err != nil {
+		c.JSON(userError(err), gin.H{"error": err.Error()})
+		return
+	}
+	s.Audit.Request(c, audit.UserCreate, user.Username, nil, user)
+
+	c.JSON(http.StatusCreated, user)
+}
+
+func (s *Server) updateUser(c *gin.Context) {
+	var req struct {
+		Username       string `json:"username"`
+		Password       string `json:"password,omitempty"`
+		Role           string `json:"role"`
+		IsActive       bool   `json:"is_active"`
+		TelegramChatID *int64 `json:"telegram_chat_id,omitempty"`
+	}
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	before, user, err := s.Users.Update(stringToUint(c.Param("id")), users.UserUpdate{
+		Username:       req.Username,
+		Password:       req.Password,
+		Role:           req.Role,
+		IsActive:       req.IsActive,
+		TelegramChatID: req.TelegramChatID,
+	})
+	if err != nil {
+		c.JSON(userError(err), gin.H{"error": err.Error()})
+		return
+	}
+	s.Audit.Request(c, audit.UserUpdate, user.Username, before, user)
+	if req.Password != "" {
+		s.Audit.Request(c, audit.UserPassword, user.Username, nil, nil)
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (s *Server) deleteUser(c *gin.Context) {
+	target, err := s.Users.Delete(stringToUint(c.Param("id")), c.GetString("username"))
+	if err != nil {
+		c.JSON(userError(err), gin.H{"error": err.Error()})
+		return
+	}
+	s.Audit.Request(c, audit.UserDelete, target.Username, target, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "user deleted successfully"})
+}
+
+// ====== DOORLOCK USERS ======
+
+func (s *Server) listDoorlockUsers(c *gin.Context) {
+	params, err := parseListParams(c, map[string]string{
+		"id": "id", "name": "name", "access_id": "access_id", "door_id": "door_id", "created_at": "created_at",
+	}, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	deleted, err := queryBool(c, "deleted")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// deleted=true menampilkan user yang sudah di-soft-delete
+	base := s.DB.Model(&store.DoorlockUser{})
+	if deleted {
+		base = s.DB.Unscoped().Model(&store.DoorlockUser{}).Where("deleted_at IS NOT NULL")
+	}
+	q := applyEquals(c, base, map[string]string{
+		"door_id": "door_id", "access_id": "access_id",
+	})
+	if q, err = applyBool(c, q, "is_active", "is_active"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if term := c.Query("q"); term != "" {
+		q = q.Where(store.Like("name")+" OR "+store.Like("access_id"), "%"+term+"%", "%"+term+"%")
+	}
+
+	page, err := paginate(q, params, func(u store.DoorlockUser) (time.Time, uint) { return u.CreatedAt, u.ID })
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch doorlock users"})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (s *Server) createDoorlockUser(c *gin.Context) {
+	var req users.DoorlockInput
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": false, "error_code": 1})
+		return
+	}
+
+	u, err := s.Users.CreateDoorlockUser(req)
+	switch {
+	case errors.Is(err, users.ErrInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"status": false, "error_code": 1, "message": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusConflict, gin.H{"status": false, "error_code": 2, "message": err.Error()})
+		return
+	}
+	s.Audit.Request(c, audit.DoorlockCreate, u.AccessID, nil, u)
+	c.JSON(http.StatusOK, gin.H{"status": true, "error_code": 0})
+}
+
+// doorlockError memetakan error users.Service untuk endpoint doorlock.
+func doorlockError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, users.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, users.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, users.ErrExpired), errors.Is(err, users.ErrAccessIDTaken):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// updateDoorlockUser menangani PUT (full=true: name dan door_id wajib,
+// expires_at yang tidak dikirim berarti tanpa kedaluwarsa) dan PATCH
+// (hanya field yang dikirim). PIN diganti lewat /pin.
+func (s *Server) updateDoorlockUser(full bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req users.DoorlockPatch
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+			return
+		}
+		if full {
+			if req.Name == nil || req.DoorID == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "name and door_id are required"})
+				return
+			}
+			req.ExpiresAt.Set = true
+		}
+
+		before, u, err := s.Users.UpdateDoorlockUser(c.Param("access_id"), req)
+		if err != nil {
+			doorlockError(c, err)
+			return
+		}
+		s.Audit.Request(c, audit.DoorlockUpdate, u.AccessID, before, u)
+		c.JSON(http.StatusOK, u)
+	}
+}
+
+// rotateDoorlockPin mengganti PIN. Tanpa pin di body server membuat PIN
+// acak dan mengembalikannya sekali di respons.
+func (s *Server) rotateDoorlockPin(c *gin.Context) {
+	var req struct {
+		Pin string `json:"pin"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+			return
+		}
+	}
+
+	u, pin, err := s.Users.RotatePin(c.Param("access_id"), req.Pin)
+	if err != nil {
+		doorlockError(c, err)
+		return
+	}
+	s.Audit.Request(c, audit.DoorlockPin, u.AccessID, nil, gin.H{"generated": req.Pin == ""})
+
+	resp := gin.H{"access_id": u.AccessID, "pin_changed_at": u.PinChangedAt}
+	if req.Pin == "" {
+		resp["pin"] = pin
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) suspendDoorlockUser(c *gin.Context) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	before, u, err := s.Users.Suspend(c.Param("access_id"), req.Reason)
+	if err != nil {
+		doorlockError(c, err)
+		return
+	}
+	s.Audit.Request(c, audit.DoorlockSuspend, u.AccessID, before, u)
+	c.JSON(http.StatusOK, u)
+}
+
+func (s *Server) resumeDoorlockUser(c *gin.Context) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+			return
+		}
+	}
+	before, u, err := s.Users.Resume(c.Param("access_id"))
+	if err != nil {
+		doorlockError(c, err)
+		return
+	}
+	s.Audit.Request(c, audit.DoorlockResume, u.AccessID, before, struct {
+		store.DoorlockUser
+		ResumeReason string `json:"resume_reason,omitempty"`
+	}{u, req.Reason})
+	c.JSON(http.StatusOK, u)
+}
+
+func (s *Server) deleteDoorlockUser(c *gin.Context) {
+	accessID := c.Param("access_id")
+	u, err := s.Users.DeleteDoorlockUser(accessID)
+	switch {
+	case errors.Is(err, users.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"status": false, "error_code": 3, "message": "user not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"status": false, "error_code": 4, "message": "failed to delete"})
+		return
+	}
+	s.Audit.Request(c, audit.DoorlockDelete, accessID, u, nil)
+	c.JSON(http.StatusOK, gin.H{"status": true, "error_code": 0, "message": "deleted successfully"})
+}
+
+// Batas ukuran file import user doorlock
+const maxImportBytes = 5 << 20
+
+// readDoorlockImport membaca baris import dari CSV (body text/csv atau
+// field "file" multipart) atau JSON array. Tanggal di CSV dibaca di zona
+// waktu situs loc.
+func readDoorlockImport(c *gin.Context, loc *time.Location) ([]users.DoorlockInput, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+	switch c.ContentType() {
+	case "text/csv":
+		return users.ReadDoorlockCSV(c.Request.Body, loc)
+	case "multipart/form-data":
+		fh, err := c.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("%w: field file wajib berisi CSV", users.ErrInvalid)
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return users.ReadDoorlockCSV(f, loc)
+	default:
+		var rows []users.DoorlockInput
+		if err := json.NewDecoder(c.Request.Body).Decode(&rows); err != nil {
+			return nil, fmt.Errorf("%w: body harus JSON array atau CSV: %v", users.ErrInvalid, err)
+		}
+		return rows, nil
+	}
+}
+
+// importDoorlockUsers menerima banyak user doorlock sekaligus. Query:
+// dry_run=true hanya validasi, mode=partial menyimpan baris yang valid
+// saja (default atomic), update=true menimpa access_id yang sudah ada.
+func (s *Server) importDoorlockUsers(c *gin.Context) {
+	var opt users.ImportOptions
+	var err error
+	if opt.DryRun, err = queryBool(c, "dry_run"); err == nil {
+		opt.Update, err = queryBool(c, "update")
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	switch c.DefaultQuery("mode", "atomic") {
+	case "atomic":
+	case "partial":
+		opt.Partial = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be atomic or partial"})
+		return
+	}
+
+	rows, err := readDoorlockImport(c, s.cfg.Location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(rows) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no rows to import"})
+		return
+	}
+
+	report, err := s.Users.ImportDoorlockUsers(rows, opt)
+	switch {
+	case errors.Is(err, users.ErrImportInvalid):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "report": report})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import doorlock users"})
+		return
+	}
+	if report.Applied {
+		s.Audit.Request(c, audit.DoorlockImport, "", nil, gin.H{
+			"total": report.Total, "created": report.Created, "updated": report.Updated, "failed": report.Failed,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// exportDoorlockUsers mengunduh semua user doorlock sebagai CSV (format
+// yang sama dengan import) atau JSON. PIN hanya ikut bila with_pins=true
+// dan pemintanya admin.
+func (s *Server) exportDoorlockUsers(c *gin.Context) {
+	withPins, err := queryBool(c, "with_pins")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or json"})
+		return
+	}
+	if withPins && c.GetString("role") != store.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins may export PINs"})
+		return
+	}
+
+	list, err := s.Users.DoorlockUsers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch doorlock users"})
+		return
+	}
+	if withPins {
+		s.Audit.Request(c, audit.DoorlockExport, "", nil, gin.H{"count": len(list), "with_pins": true})
+	}
+
+	if format == "json" {
+		rows := make([]users.DoorlockInput, len(list))
+		for i, u := range list {
+			active := u.IsActive
+			rows[i] = users.DoorlockInput{Name: u.Name, AccessID: u.AccessID, DoorID: u.DoorID, IsActive: &active, ExpiresAt: u.ExpiresAt}
+			if withPins {
+				rows[i].Pin = u.Pin
+			}
+		}
+		c.JSON(http.StatusOK, rows)
+		return
+	}
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="doorlock_users.csv"`)
+	c.Status(http.StatusOK)
+	if err := users.WriteDoorlockCSV(c.Writer, list, withPins); err != nil {
+		c.Error(err)
+	}
+}
+
+// ====== DOOR CONFIGURATION ======
+
+func (s *Server) listDoors(c *gin.Context) {
+	list, err := s.Store.Doors()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch doors"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (s *Server) getDoor(c *gin.Context) {
+	// Pintu yang belum dikonfigurasi dikembalikan dengan nilai default
+	c.JSON(http.StatusOK, devices.LoadDoor(s.Store, c.Param("door_id")))
+}
+
+func (s *Server) updateDoor(c *gin.Context) {
+	var req store.Door
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if req.FailedAttemptLimit < 0 || req.FailedAttemptWindow < 0 || req.LockoutMinutes < 0 || req.BuzzerDuration < 0 || req.OpenAlarmSeconds < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "thresholds must not be negative"})
+		return
+	}
+	switch req.AntiPassback {
+	case "":
+		req.AntiPassback = access.AntiPassbackOff
+	case access.AntiPassbackOff, access.AntiPassbackSoft, access.AntiPassbackHard:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "anti_passback must be off, soft or hard"})
+		return
+	}
+	if req.AntiPassbackReset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "thresholds must not be negative"})
+		return
+	}
+
+	door, _ := s.Store.Door(c.Param("door_id"))
+	before := door
+	door.DoorID = c.Param("door_id")
+	door.Name = req.Name
+	door.Zone = req.Zone
+	door.BuzzerID = req.BuzzerID
+	door.BuzzerDuration = req.BuzzerDuration
+	door.FailedAttemptLimit = req.FailedAttemptLimit
+	door.FailedAttemptWindow = req.FailedAttemptWindow
+	door.LockoutMinutes = req.LockoutMinutes
+	door.OpenAlarmSeconds = req.OpenAlarmSeconds
+	door.AntiPassback = req.AntiPassback
+	door.AntiPassbackReset = req.AntiPassbackReset
+
+	if err := s.Store.SaveDoor(&door); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save door"})
+		return
+	}
+	if before.ID == 0 {
+		s.Audit.Request(c, audit.DoorUpdate, door.DoorID, nil, door)
+	} else {
+		s.Audit.Request(c, audit.DoorUpdate, door.DoorID, before, door)
+	}
+	c.JSON(http.StatusOK, door)
+}
@@ -0,0 +1,83 @@
+// Package mqttbus membungkus klien MQTT sebagai message bus untuk perintah
+// ke perangkat dan event ke dashboard.
+package mqttbus
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// Bus mengirim pesan ke topic. Service bergantung pada interface ini
+// sehingga test bisa memakai fake.
+type Bus interface {
+	Publish(topic, payload string) error
+	Connected() bool
+}
+
+var ErrNotConnected = errors.New("MQTT client tidak terhubung")
+
+// Client adalah Bus di atas mqtt.Client.
+type Client struct {
+	client mqtt.Client
+}
+
+// New membungkus mqtt.Client yang sudah dibuat (atau fake di test).
+func New(client mqtt.Client) *Client {
+	return &Client{client: client}
+}
+
+// Connect membuat klien ke broker dan mencoba terhubung. Bila gagal, klien
+// tetap dikembalikan dan reconnect berjalan di background.
+func Connect(broker, clientID string) *Client {
+	opts := mqtt.NewClientOptions()
+	opts.AddBroker(broker)
+	opts.SetClientID(clientID)
+	opts.SetKeepAlive(60 * time.Second)
+	opts.SetPingTimeout(1 * time.Second)
+	opts.SetAutoReconnect(true)
+	opts.SetConnectRetry(true)
+	opts.SetMaxReconnectInterval(10 * time.Second)
+
+	opts.OnConnect = func(client mqtt.Client) {
+		log.Println("✅ MQTT Client successfully connected")
+	}
+
+	opts.OnConnectionLost = func(client mqtt.Client, err error) {
+		log.Printf("❌ MQTT Connection lost: %v", err)
+	}
+
+	client := mqtt.NewClient(opts)
+
+	// Connect to MQTT broker
+	if token := client.Connect(); token.Wait() && token.Error() != nil {
+		log.Printf("⚠️ MQTT Connection failed: %v", token.Error())
+		log.Printf("⚠️ Attempting to reconnect in background...")
+		// Biarkan client mencoba reconnect secara otomatis
+	} else {
+		log.Println("✅ MQTT Client connected successfully")
+	}
+	return New(client)
+}
+
+func (c *Client) Connected() bool {
+	return c != nil && c.client != nil && c.client.IsConnected()
+}
+
+func (c *Client) Publish(topic, payload string) error {
+	if !c.Connected() {
+		return ErrNotConnected
+	}
+
+	token := c.client.Publish(topic, 0, false, payload)
+	token.Wait()
+	if token.Error() != nil {
+		return fmt.Errorf("gagal publish MQTT: %w", token.Error())
+	}
+
+	log.Printf("MQTT message published: %s -> %s", topic, payload)
+	return nil
+}
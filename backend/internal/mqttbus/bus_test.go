@@ -0,0 +1,38 @@
+package mqttbus
+
+import (
+	"errors"
+	"testing"
+
+	"smart-door-lock/backend/internal/mqttbus/mqtttest"
+)
+
+func TestPublish(t *testing.T) {
+	fake := mqtttest.New()
+	bus := New(fake)
+
+	if err := bus.Publish("doorlock/D01/control", `{"command": "lock"}`); err != nil {
+		t.Fatal(err)
+	}
+	msgs := fake.Published()
+	if len(msgs) != 1 || msgs[0].Topic != "doorlock/D01/control" || msgs[0].Payload != `{"command": "lock"}` {
+		t.Fatalf("published %+v", msgs)
+	}
+
+	fake.FailPublish(errors.New("timeout"))
+	if err := bus.Publish("x", "y"); err == nil {
+		t.Error("expected broker error")
+	}
+}
+
+func TestPublishWhileDisconnected(t *testing.T) {
+	fake := mqtttest.New()
+	fake.SetConnected(false)
+
+	if err := New(fake).Publish("x", "y"); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("err = %v, want ErrNotConnected", err)
+	}
+	if err := New(nil).Publish("x", "y"); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("nil client: err = %v, want ErrNotConnected", err)
+	}
+}
@@ -0,0 +1,154 @@
+// Package mqtttest berisi fake mqtt.Client untuk test paket lain. Pesan
+// yang di-publish disimpan sehingga test bisa memeriksa topic dan payload.
+package mqtttest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+var errNotConnected = errors.New("fake MQTT client tidak terhubung")
+
+// Message adalah satu pesan yang di-publish.
+type Message struct {
+	Topic   string
+	Payload string
+}
+
+// Client adalah mqtt.Client palsu tanpa broker.
+type Client struct {
+	mu         sync.Mutex
+	connected  bool
+	publishErr error
+	published  []Message
+	changed    chan struct{}
+}
+
+var _ mqtt.Client = (*Client)(nil)
+
+// New membuat fake yang sudah terhubung.
+func New() *Client {
+	return &Client{connected: true, changed: make(chan struct{})}
+}
+
+// SetConnected mengatur hasil IsConnected.
+func (c *Client) SetConnected(connected bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.connected = connected
+}
+
+// FailPublish membuat Publish berikutnya gagal dengan err (nil = normal).
+func (c *Client) FailPublish(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.publishErr = err
+}
+
+// Published mengembalikan salinan semua pesan yang sudah di-publish.
+func (c *Client) Published() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Message(nil), c.published...)
+}
+
+// WaitFor menunggu pesan pertama pada topic, untuk publish yang berjalan
+// di goroutine.
+func (c *Client) WaitFor(topic string, timeout time.Duration) (Message, bool) {
+	deadline := time.After(timeout)
+	for {
+		c.mu.Lock()
+		for _, m := range c.published {
+			if m.Topic == topic {
+				c.mu.Unlock()
+				return m, true
+			}
+		}
+		changed := c.changed
+		c.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return Message{}, false
+		}
+	}
+}
+
+func (c *Client) IsConnected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.connected
+}
+
+func (c *Client) IsConnectionOpen() bool { return c.IsConnected() }
+
+func (c *Client) Connect() mqtt.Token {
+	c.SetConnected(true)
+	return token{}
+}
+
+func (c *Client) Disconnect(quiesce uint) { c.SetConnected(false) }
+
+func (c *Client) Publish(topic string, qos byte, retained bool, payload interface{}) mqtt.Token {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.connected {
+		return token{err: errNotConnected}
+	}
+	if c.publishErr != nil {
+		return token{err: c.publishErr}
+	}
+
+	var body string
+	switch p := payload.(type) {
+	case string:
+		body = p
+	case []byte:
+		body = string(p)
+	default:
+		body = fmt.Sprint(p)
+	}
+	c.published = append(c.published, Message{Topic: topic, Payload: body})
+
+	// bangunkan WaitFor
+	close(c.changed)
+	c.changed = make(chan struct{})
+	return token{}
+}
+
+func (c *Client) Subscribe(topic string, qos byte, callback mqtt.MessageHandler) mqtt.Token {
+	return token{}
+}
+
+func (c *Client) SubscribeMultiple(filters map[string]byte, callback mqtt.MessageHandler) mqtt.Token {
+	return token{}
+}
+
+func (c *Client) Unsubscribe(topics ...string) mqtt.Token { return token{} }
+
+func (c *Client) AddRoute(topic string, callback mqtt.MessageHandler) {}
+
+func (c *Client) OptionsReader() mqtt.ClientOptionsReader {
+	return mqtt.NewOptionsReader(mqtt.NewClientOptions())
+}
+
+// token adalah mqtt.Token yang langsung selesai.
+type token struct {
+	err error
+}
+
+var done = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+func (t token) Wait() bool                     { return true }
+func (t token) WaitTimeout(time.Duration) bool { return true }
+func (t token) Done() <-chan struct{}          { return done }
+func (t token) Error() error                   { return t.err }
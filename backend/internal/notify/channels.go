@@ -0,0 +1,269 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/store"
+)
+
+const (
+	ChannelTelegram = "telegram"
+	ChannelWebhook  = "webhook"
+	ChannelEmail    = "email"
+	ChannelNtfy     = "ntfy"
+	ChannelGotify   = "gotify"
+)
+
+// RegisterDefaults mendaftarkan semua channel yang tersedia. Email hanya
+// didaftarkan bila SMTP dikonfigurasi.
+func RegisterDefaults(d *Dispatcher, smtpCfg config.SMTPConfig, sender MessageSender, chatID int64) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	d.Register(ChannelTelegram, TelegramNotifier{Sender: sender, DefaultChatID: chatID})
+	d.Register(ChannelWebhook, WebhookNotifier{Client: client})
+	d.Register(ChannelNtfy, PushNotifier{Client: client, Style: ChannelNtfy})
+	d.Register(ChannelGotify, PushNotifier{Client: client, Style: ChannelGotify})
+	if smtpCfg.Host != "" {
+		d.Register(ChannelEmail, EmailNotifier{
+			Addr:     net.JoinHostPort(smtpCfg.Host, strconv.Itoa(smtpCfg.Port)),
+			Username: smtpCfg.Username,
+			Password: smtpCfg.Password,
+			From:     smtpCfg.From,
+		})
+	}
+}
+
+// --- TELEGRAM ---
+
+// MessageSender mengirim teks ke chat Telegram (dipenuhi telegram.Client).
+type MessageSender interface {
+	SendMessage(chatID int64, text string) error
+}
+
+// TelegramNotifier mengirim ke chat ID pada target (default: DefaultChatID).
+type TelegramNotifier struct {
+	Sender        MessageSender
+	DefaultChatID int64
+}
+
+func (t TelegramNotifier) Send(ctx context.Context, target string, n Notification) error {
+	chatID := t.DefaultChatID
+	if target != "" {
+		id, err := strconv.ParseInt(target, 10, 64)
+		if err != nil {
+			return fmt.Errorf("chat ID tidak valid: %q", target)
+		}
+		chatID = id
+	}
+	if t.Sender == nil {
+		return errors.New("Telegram bot tidak terinisialisasi")
+	}
+	return t.Sender.SendMessage(chatID, n.Body)
+}
+
+// --- WEBHOOK ---
+
+// WebhookNotifier mengirim Notification sebagai JSON ke URL target.
+type WebhookNotifier struct {
+	Client *http.Client
+}
+
+func (w WebhookNotifier) Send(ctx context.Context, target string, n Notification) error {
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("cannot marshal notification: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doNotifyRequest(w.Client, req)
+}
+
+// --- PUSH (ntfy / Gotify) ---
+
+// PushNotifier mendukung ntfy (POST teks ke URL topic) dan Gotify
+// (POST JSON ke /message?token=...).
+type PushNotifier struct {
+	Client *http.Client
+	Style  string
+}
+
+// pushPriority memetakan severity ke prioritas 1-5 (ntfy) / 0-10 (Gotify).
+func pushPriority(severity string) int {
+	switch severity {
+	case store.SeverityCritical:
+		return 5
+	case store.SeverityHigh:
+		return 4
+	case store.SeverityLow:
+		return 2
+	}
+	return 3
+}
+
+func (p PushNotifier) Send(ctx context.Context, target string, n Notification) error {
+	var req *http.Request
+	var err error
+
+	if p.Style == ChannelGotify {
+		payload, _ := json.Marshal(map[string]interface{}{
+			"title":    n.Subject,
+			"message":  n.Body,
+			"priority": pushPriority(n.Severity) * 2,
+		})
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, target, strings.NewReader(n.Body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Title", n.Subject)
+		req.Header.Set("Priority", strconv.Itoa(pushPriority(n.Severity)))
+		req.Header.Set("Tags", "rotating_light")
+	}
+	return doNotifyRequest(p.Client, req)
+}
+
+func doNotifyRequest(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// --- EMAIL ---
+
+// emailTimeout membatasi satu pengiriman SMTP bila ctx tidak punya deadline
+// yang lebih awal; server yang diam tidak boleh menahan worker outbox.
+const emailTimeout = 30 * time.Second
+
+// EmailNotifier mengirim lewat SMTP. Target berisi satu atau beberapa
+// alamat dipisah koma.
+type EmailNotifier struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+}
+
+// encodeSubject membuang CR/LF (mencegah header tambahan disisipkan lewat
+// subject) lalu meng-encode karakter non-ASCII sesuai RFC 2047.
+func encodeSubject(subject string) string {
+	subject = strings.Join(strings.FieldsFunc(subject, func(r rune) bool { return r == '\r' || r == '\n' }), " ")
+	return mime.QEncoding.Encode("utf-8", subject)
+}
+
+func (e EmailNotifier) Send(ctx context.Context, target string, n Notification) error {
+	var to []string
+	for _, addr := range strings.Split(target, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+	if len(to) == 0 {
+		return errors.New("alamat email kosong")
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", encodeSubject(n.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(n.Body, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+
+	return e.sendMail(ctx, to, msg.Bytes())
+}
+
+// sendMail sama dengan smtp.SendMail (STARTTLS bila didukung, AUTH bila
+// ada username) tetapi dial dan seluruh percakapan SMTP mengikuti ctx.
+func (e EmailNotifier) sendMail(ctx context.Context, to []string, msg []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, emailTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", e.Addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	// pembatalan ctx memutus I/O yang sedang menunggu
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	host, _, _ := net.SplitHostPort(e.Addr)
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if e.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := c.Auth(smtp.PlainAuth("", e.Username, e.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(e.From); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
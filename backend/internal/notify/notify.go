@@ -0,0 +1,307 @@
+// Package notify mencocokkan alarm dengan aturan notifikasi, menyimpan
+// pesan ke outbox dan mengirimkannya lewat channel yang terdaftar.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/store"
+)
+
+const (
+	EventAlarm      = "alarm"
+	EventEscalation = "escalation"
+	EventResolved   = "resolved"
+)
+
+const (
+	OutboxPending = "pending"
+	OutboxSent    = "sent"
+	OutboxFailed  = "failed"
+)
+
+const (
+	outboxMaxAttempts = 8
+	outboxBaseBackoff = 30 * time.Second
+	outboxMaxBackoff  = time.Hour
+	outboxBatchSize   = 50
+)
+
+// ====== NOTIFIER INTERFACE ======
+
+// Notification adalah isi pesan yang sama untuk semua channel.
+type Notification struct {
+	Event     string    `json:"event"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	Severity  string    `json:"severity"`
+	AlarmID   uint      `json:"alarm_id"`
+	AlarmType int       `json:"alarm_type"`
+	DoorID    string    `json:"door_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Notifier mengirim satu notifikasi ke target pada channel tertentu
+// (chat ID, URL webhook, alamat email, topic push).
+type Notifier interface {
+	Send(ctx context.Context, target string, n Notification) error
+}
+
+// Dispatcher menyimpan daftar channel dan menjalankan outbox.
+type Dispatcher struct {
+	db *gorm.DB
+
+	mu        sync.RWMutex
+	byChannel map[string]Notifier
+
+	// target bawaan selama belum ada aturan di database
+	chatID           int64
+	escalationChatID int64
+
+	wake chan struct{}
+}
+
+// NewDispatcher membuat dispatcher tanpa channel; daftarkan channel dengan
+// Register atau RegisterDefaults.
+func NewDispatcher(db *gorm.DB, chatID, escalationChatID int64) *Dispatcher {
+	return &Dispatcher{
+		db:               db,
+		byChannel:        make(map[string]Notifier),
+		chatID:           chatID,
+		escalationChatID: escalationChatID,
+		wake:             make(chan struct{}, 1),
+	}
+}
+
+func (d *Dispatcher) Register(channel string, n Notifier) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.byChannel[channel] = n
+}
+
+func (d *Dispatcher) Notifier(channel string) (Notifier, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	n, ok := d.byChannel[channel]
+	return n, ok
+}
+
+// ====== ROUTING ======
+
+func ruleMatches(rule store.NotificationRule, event string, al store.Alarm) bool {
+	if !rule.Enabled {
+		return false
+	}
+	ruleEvent := rule.Event
+	if ruleEvent == "" {
+		ruleEvent = EventAlarm
+	}
+	if ruleEvent != event {
+		return false
+	}
+	if rule.AlarmType != 0 && rule.AlarmType != al.AlarmType {
+		return false
+	}
+	if rule.DoorID != "" && rule.DoorID != al.DoorID {
+		return false
+	}
+	if rule.MinSeverity != "" && store.SeverityRank[al.Severity] < store.SeverityRank[rule.MinSeverity] {
+		return false
+	}
+	return true
+}
+
+// ValidateRule mengembalikan pesan kesalahan aturan, atau "" bila valid.
+func (d *Dispatcher) ValidateRule(rule store.NotificationRule) string {
+	if _, ok := d.Notifier(rule.Channel); !ok {
+		return "unknown or unconfigured channel"
+	}
+	if rule.Target == "" && rule.Channel != ChannelTelegram {
+		return "target is required"
+	}
+	switch rule.Event {
+	case "", EventAlarm, EventEscalation, EventResolved:
+	default:
+		return "event must be alarm, escalation or resolved"
+	}
+	if _, ok := store.SeverityRank[rule.MinSeverity]; rule.MinSeverity != "" && !ok {
+		return "invalid min_severity"
+	}
+	return ""
+}
+
+// defaultRoutes dipakai bila tidak ada aturan aktif yang cocok (termasuk
+// selama belum ada aturan sama sekali), supaya alarm tidak hilang diam-diam
+// dan perilaku lama (semua ke chat Telegram utama) tetap berjalan.
+func (d *Dispatcher) defaultRoutes(event string) []store.NotificationRule {
+	if event == EventEscalation {
+		if d.escalationChatID == 0 {
+			return nil
+		}
+		return []store.NotificationRule{{Channel: ChannelTelegram, Target: fmt.Sprint(d.escalationChatID)}}
+	}
+	if d.chatID == 0 {
+		return nil
+	}
+	return []store.NotificationRule{{Channel: ChannelTelegram, Target: fmt.Sprint(d.chatID)}}
+}
+
+// Notify mencocokkan aturan lalu memasukkan pesan ke outbox. Pengiriman
+// sebenarnya dilakukan oleh Run.
+func (d *Dispatcher) Notify(n Notification, al store.Alarm) int {
+	var rules []store.NotificationRule
+	if err := d.db.Find(&rules).Error; err != nil {
+		log.Printf("Gagal memuat aturan notifikasi: %v", err)
+		return 0
+	}
+
+	var routes []store.NotificationRule
+	for _, rule := range rules {
+		if ruleMatches(rule, n.Event, al) {
+			routes = append(routes, rule)
+		}
+	}
+	if len(routes) == 0 {
+		routes = d.defaultRoutes(n.Event)
+	}
+
+	now := time.Now()
+	queued := 0
+	for _, rule := range routes {
+		entry := store.NotificationOutbox{
+			RuleID:        rule.ID,
+			AlarmID:       n.AlarmID,
+			AlarmType:     n.AlarmType,
+			DoorID:        n.DoorID,
+			Event:         n.Event,
+			Channel:       rule.Channel,
+			Target:        rule.Target,
+			Subject:       n.Subject,
+			Body:          n.Body,
+			Severity:      n.Severity,
+			Status:        OutboxPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+		if err := d.db.Create(&entry).Error; err != nil {
+			log.Printf("Gagal menyimpan outbox notifikasi: %v", err)
+			continue
+		}
+		queued++
+	}
+
+	if queued > 0 {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}
+	return queued
+}
+
+// NotifyAlarm membuat Notification dari alarm dan mengirimkannya ke outbox.
+func (d *Dispatcher) NotifyAlarm(event, subject, body string, al store.Alarm) int {
+	return d.Notify(Notification{
+		Event:     event,
+		Subject:   subject,
+		Body:      body,
+		Severity:  al.Severity,
+		AlarmID:   al.ID,
+		AlarmType: al.AlarmType,
+		DoorID:    al.DoorID,
+		Timestamp: time.Now(),
+	}, al)
+}
+
+// ====== OUTBOX WORKER ======
+
+func outboxBackoff(attempts int) time.Duration {
+	d := outboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+	return d
+}
+
+// Run mengirim outbox secara berkala dan segera setelah ada pesan baru.
+func (d *Dispatcher) Run() {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for {
+		d.ProcessOutbox(time.Now())
+		select {
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// ProcessOutbox mengirim semua pesan pending yang sudah jatuh tempo.
+func (d *Dispatcher) ProcessOutbox(now time.Time) {
+	var batch []store.NotificationOutbox
+	err := d.db.Where("status = ? AND next_attempt_at <= ?", OutboxPending, now).
+		Order("next_attempt_at").
+		Limit(outboxBatchSize).
+		Find(&batch).Error
+	if err != nil {
+		log.Printf("Gagal memuat outbox notifikasi: %v", err)
+		return
+	}
+
+	for _, entry := range batch {
+		d.deliver(entry, now)
+	}
+}
+
+func (d *Dispatcher) deliver(entry store.NotificationOutbox, now time.Time) {
+	entry.Attempts++
+
+	err := func() error {
+		n, ok := d.Notifier(entry.Channel)
+		if !ok {
+			return fmt.Errorf("channel %q tidak dikenal", entry.Channel)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		return n.Send(ctx, entry.Target, Notification{
+			Event:     entry.Event,
+			Subject:   entry.Subject,
+			Body:      entry.Body,
+			Severity:  entry.Severity,
+			AlarmID:   entry.AlarmID,
+			AlarmType: entry.AlarmType,
+			DoorID:    entry.DoorID,
+			Timestamp: entry.CreatedAt,
+		})
+	}()
+
+	if err == nil {
+		entry.Status = OutboxSent
+		entry.SentAt = &now
+		entry.LastError = ""
+		log.Printf("Notifikasi #%d terkirim via %s", entry.ID, entry.Channel)
+	} else {
+		entry.LastError = err.Error()
+		if entry.Attempts >= outboxMaxAttempts {
+			entry.Status = OutboxFailed
+			log.Printf("❌ Notifikasi #%d gagal permanen via %s: %v", entry.ID, entry.Channel, err)
+		} else {
+			entry.NextAttemptAt = now.Add(outboxBackoff(entry.Attempts))
+			log.Printf("⚠️ Notifikasi #%d gagal via %s (percobaan %d), retry %s: %v",
+				entry.ID, entry.Channel, entry.Attempts, entry.NextAttemptAt.Format(time.RFC3339), err)
+		}
+	}
+
+	if err := d.db.Save(&entry).Error; err != nil {
+		log.Printf("Gagal memperbarui outbox #%d: %v", entry.ID, err)
+	}
+}
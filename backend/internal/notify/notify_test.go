@@ -0,0 +1,304 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+func TestRuleMatches(t *testing.T) {
+	al := store.Alarm{AlarmType: store.AlarmTypeFailedAttempts, DoorID: "D01", Severity: store.SeverityHigh}
+
+	cases := []struct {
+		name  string
+		rule  store.NotificationRule
+		event string
+		want  bool
+	}{
+		{"catch all", store.NotificationRule{Enabled: true}, EventAlarm, true},
+		{"disabled", store.NotificationRule{}, EventAlarm, false},
+		{"other event", store.NotificationRule{Enabled: true, Event: EventEscalation}, EventAlarm, false},
+		{"alarm type", store.NotificationRule{Enabled: true, AlarmType: store.AlarmTypeDoorOpenTooLong}, EventAlarm, false},
+		{"door", store.NotificationRule{Enabled: true, DoorID: "D01"}, EventAlarm, true},
+		{"other door", store.NotificationRule{Enabled: true, DoorID: "D02"}, EventAlarm, false},
+		{"severity met", store.NotificationRule{Enabled: true, MinSeverity: store.SeverityMedium}, EventAlarm, true},
+		{"severity too low", store.NotificationRule{Enabled: true, MinSeverity: store.SeverityCritical}, EventAlarm, false},
+	}
+	for _, tc := range cases {
+		if got := ruleMatches(tc.rule, tc.event, al); got != tc.want {
+			t.Errorf("%s: ruleMatches = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got Notification
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	n := Notification{Event: EventAlarm, Subject: "s", Body: "b", AlarmID: 7, DoorID: "D01"}
+	if err := (WebhookNotifier{Client: srv.Client()}).Send(context.Background(), srv.URL, n); err != nil {
+		t.Fatal(err)
+	}
+	if got.AlarmID != 7 || got.DoorID != "D01" || got.Body != "b" {
+		t.Errorf("webhook received %+v", got)
+	}
+}
+
+func TestWebhookNotifierRejectsNon2xx(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	err := (WebhookNotifier{Client: srv.Client()}).Send(context.Background(), srv.URL, Notification{})
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("expected HTTP 502 error, got %v", err)
+	}
+}
+
+func TestPushNotifierNtfy(t *testing.T) {
+	var title, priority, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		title = r.Header.Get("Title")
+		priority = r.Header.Get("Priority")
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer srv.Close()
+
+	n := Notification{Subject: "Alarm", Body: "Pintu D01", Severity: store.SeverityCritical}
+	if err := (PushNotifier{Client: srv.Client(), Style: ChannelNtfy}).Send(context.Background(), srv.URL+"/doorlock", n); err != nil {
+		t.Fatal(err)
+	}
+	if title != "Alarm" || priority != "5" || body != "Pintu D01" {
+		t.Errorf("got title=%q priority=%q body=%q", title, priority, body)
+	}
+}
+
+// fakeSMTPServer menerima satu sesi SMTP sederhana tanpa auth/TLS.
+func fakeSMTPServer(t *testing.T) (addr string, received <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make(chan string, 1)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+
+		reply("220 fake.smtp ready")
+		var data strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 fake.smtp")
+			case strings.HasPrefix(cmd, "MAIL FROM"), strings.HasPrefix(cmd, "RCPT TO"):
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				out <- data.String()
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return ln.Addr().String(), out
+}
+
+func TestEmailNotifier(t *testing.T) {
+	addr, received := fakeSMTPServer(t)
+
+	n := Notification{Subject: "Alarm #1", Body: "Pintu D01\nterbuka"}
+	err := EmailNotifier{Addr: addr, From: "doorlock@test"}.Send(context.Background(), "guard@test, hr@test", n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-received:
+		for _, want := range []string{"Subject: Alarm #1", "To: guard@test, hr@test", "Pintu D01\r\nterbuka"} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("message missing %q:\n%s", want, msg)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake SMTP server did not receive a message")
+	}
+}
+
+func TestEmailNotifierSanitizesSubject(t *testing.T) {
+	addr, received := fakeSMTPServer(t)
+
+	n := Notification{Subject: "Pintu terbuka\r\nBcc: attacker@evil — D01", Body: "isi"}
+	if err := (EmailNotifier{Addr: addr, From: "doorlock@test"}).Send(context.Background(), "guard@test", n); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-received:
+		if strings.Contains(msg, "\r\nBcc:") {
+			t.Errorf("header injected via subject:\n%s", msg)
+		}
+		if !strings.Contains(msg, "Subject: =?utf-8?q?Pintu_terbuka_Bcc:_attacker@evil_=E2=80=94_D01?=") {
+			t.Errorf("subject not encoded:\n%s", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake SMTP server did not receive a message")
+	}
+}
+
+func TestEmailNotifierHonoursContext(t *testing.T) {
+	// server menerima koneksi tetapi tidak pernah mengirim greeting
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = EmailNotifier{Addr: ln.Addr().String(), From: "doorlock@test"}.Send(ctx, "guard@test", Notification{Subject: "x"})
+	if err == nil || time.Since(start) > 5*time.Second {
+		t.Fatalf("Send = %v after %v, want timeout", err, time.Since(start))
+	}
+}
+
+func TestNotifyFallsBackToDefaultRoute(t *testing.T) {
+	db := storetest.Open(t, &store.Alarm{}, &store.NotificationRule{}, &store.NotificationOutbox{})
+	d := NewDispatcher(db, 42, 0)
+	d.Register(ChannelWebhook, WebhookNotifier{})
+
+	// aturan nonaktif dan aturan yang tidak cocok tidak boleh menelan alarm
+	db.Create(&store.NotificationRule{Name: "off", Channel: ChannelWebhook, Target: "http://x", Enabled: false})
+	db.Create(&store.NotificationRule{Name: "critical", Channel: ChannelWebhook, Target: "http://y", Enabled: true, MinSeverity: store.SeverityCritical})
+	al := store.Alarm{ID: 1, AlarmType: store.AlarmTypeFailedAttempts, Severity: store.SeverityHigh}
+	if n := d.NotifyAlarm(EventAlarm, "subject", "body", al); n != 1 {
+		t.Fatalf("queued %d notifications, want 1", n)
+	}
+	var entry store.NotificationOutbox
+	db.First(&entry)
+	if entry.Channel != ChannelTelegram || entry.Target != "42" {
+		t.Errorf("fallback route = %s %s, want telegram 42", entry.Channel, entry.Target)
+	}
+}
+
+type flakyNotifier struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+}
+
+func (f *flakyNotifier) Send(ctx context.Context, target string, n Notification) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls <= f.failures {
+		return errors.New("temporary failure")
+	}
+	return nil
+}
+
+func TestOutboxRetriesWithBackoff(t *testing.T) {
+	db := storetest.Open(t, &store.Alarm{}, &store.NotificationRule{}, &store.NotificationOutbox{})
+	fake := &flakyNotifier{failures: 2}
+	d := NewDispatcher(db, 0, 0)
+	d.Register("flaky", fake)
+
+	db.Create(&store.NotificationRule{Name: "flaky", Channel: "flaky", Target: "x", Enabled: true})
+	al := store.Alarm{ID: 1, AlarmType: store.AlarmTypeFailedAttempts, Severity: store.SeverityHigh}
+	if n := d.NotifyAlarm(EventAlarm, "subject", "body", al); n != 1 {
+		t.Fatalf("queued %d notifications, want 1", n)
+	}
+
+	start := time.Now()
+	d.ProcessOutbox(start)
+
+	var entry store.NotificationOutbox
+	db.First(&entry)
+	if entry.Status != OutboxPending || entry.Attempts != 1 {
+		t.Fatalf("after first attempt: status=%s attempts=%d", entry.Status, entry.Attempts)
+	}
+	if want := start.Add(outboxBaseBackoff); !entry.NextAttemptAt.Equal(want) {
+		t.Fatalf("next attempt at %v, want %v", entry.NextAttemptAt, want)
+	}
+
+	// belum jatuh tempo, tidak boleh dikirim ulang
+	d.ProcessOutbox(start.Add(time.Second))
+	if fake.calls != 1 {
+		t.Fatalf("delivered before backoff expired (calls=%d)", fake.calls)
+	}
+
+	d.ProcessOutbox(start.Add(outboxBaseBackoff))
+	d.ProcessOutbox(start.Add(outboxBaseBackoff + outboxBackoff(2)))
+
+	db.First(&entry)
+	if entry.Status != OutboxSent || entry.Attempts != 3 || entry.SentAt == nil {
+		t.Fatalf("after retries: status=%s attempts=%d sent_at=%v", entry.Status, entry.Attempts, entry.SentAt)
+	}
+}
+
+func TestOutboxBackoffIsCapped(t *testing.T) {
+	if got := outboxBackoff(1); got != outboxBaseBackoff {
+		t.Errorf("backoff(1) = %v", got)
+	}
+	if got := outboxBackoff(2); got != 2*outboxBaseBackoff {
+		t.Errorf("backoff(2) = %v", got)
+	}
+	if got := outboxBackoff(50); got != outboxMaxBackoff {
+		t.Errorf("backoff(50) = %v", got)
+	}
+}
@@ -0,0 +1,42 @@
+// Package notifytest berisi fake Notifier untuk test paket lain.
+package notifytest
+
+import (
+	"context"
+	"sync"
+
+	"smart-door-lock/backend/internal/notify"
+)
+
+// Sent adalah satu notifikasi yang diterima Recorder.
+type Sent struct {
+	Target       string
+	Notification notify.Notification
+}
+
+// Recorder mencatat setiap notifikasi alih-alih mengirimkannya. Isi Err
+// untuk mensimulasikan channel yang gagal.
+type Recorder struct {
+	mu   sync.Mutex
+	sent []Sent
+	Err  error
+}
+
+var _ notify.Notifier = (*Recorder)(nil)
+
+func (r *Recorder) Send(ctx context.Context, target string, n notify.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	r.sent = append(r.sent, Sent{Target: target, Notification: n})
+	return nil
+}
+
+// Sent mengembalikan salinan notifikasi yang sudah diterima.
+func (r *Recorder) Sent() []Sent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Sent(nil), r.sent...)
+}
@@ -0,0 +1,287 @@
+// Package retention mengarsipkan lalu menghapus baris kedaluwarsa sesuai
+// masa simpan per tabel.
+package retention
+
+import (
+	"compress/gzip"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/config"
+)
+
+// Service menjalankan retensi sesuai config.RetentionConfig.
+type Service struct {
+	db  *gorm.DB
+	cfg config.RetentionConfig
+	loc *time.Location // batas hari dihitung di zona waktu situs
+	// mu mencegah job terjadwal dan trigger manual berjalan bersamaan.
+	mu sync.Mutex
+}
+
+func New(db *gorm.DB, cfg config.RetentionConfig, loc *time.Location) *Service {
+	return &Service{db: db, cfg: cfg, loc: loc}
+}
+
+// retentionPolicy mendeskripsikan aturan retensi satu tabel.
+type retentionPolicy struct {
+	Table  string
+	Days   int
+	Column string // kolom waktu acuan
+	Where  string // syarat tambahan, mis. alarm harus sudah resolved
+}
+
+// policies menyusun aturan retensi semua tabel dari konfigurasi.
+// Tabel audit sengaja tidak dipurge karena rantai hash-nya harus utuh.
+func (s *Service) policies() []retentionPolicy {
+	return []retentionPolicy{
+		{Table: "attendances", Days: s.cfg.AttendanceDays, Column: "created_at"},
+		{Table: "alarms", Days: s.cfg.AlarmDays, Column: "created_at", Where: "status = 'resolved'"},
+		{Table: "door_open_logs", Days: s.cfg.DoorOpenLogDays, Column: "created_at"},
+		{Table: "occupancies", Days: s.cfg.OccupancyDays, Column: "entered_at", Where: "exited_at IS NOT NULL"},
+		{Table: "command_logs", Days: s.cfg.CommandLogDays, Column: "created_at"},
+		{Table: "notification_outboxes", Days: s.cfg.OutboxDays, Column: "created_at", Where: "status <> 'pending'"},
+	}
+}
+
+func (p retentionPolicy) cutoff(now time.Time, loc *time.Location) time.Time {
+	return config.StartOfDay(now, loc).AddDate(0, 0, -p.Days)
+}
+
+func (p retentionPolicy) condition() string {
+	cond := p.Column + " < ?"
+	if p.Where != "" {
+		cond += " AND " + p.Where
+	}
+	return cond
+}
+
+// Preview adalah hasil dry-run satu tabel.
+type Preview struct {
+	Table         string     `json:"table"`
+	RetentionDays int        `json:"retention_days"`
+	Cutoff        *time.Time `json:"cutoff,omitempty"`
+	Rows          int64      `json:"rows"`
+	Oldest        *time.Time `json:"oldest,omitempty"`
+}
+
+// Preview menghitung baris yang akan diarsip & dihapus tanpa mengubah apa
+// pun.
+func (s *Service) Preview(now time.Time) ([]Preview, error) {
+	var out []Preview
+	for _, p := range s.policies() {
+		pv := Preview{Table: p.Table, RetentionDays: p.Days}
+		if p.Days <= 0 {
+			out = append(out, pv)
+			continue
+		}
+		cutoff := p.cutoff(now, s.loc)
+		pv.Cutoff = &cutoff
+
+		q := s.db.Table(p.Table).Where(p.condition(), cutoff)
+		if err := q.Session(&gorm.Session{}).Count(&pv.Rows).Error; err != nil {
+			return nil, err
+		}
+		if pv.Rows > 0 {
+			var oldest sql.NullTime
+			if err := q.Select(p.Column).Order(p.Column).Limit(1).Row().Scan(&oldest); err == nil && oldest.Valid {
+				pv.Oldest = &oldest.Time
+			}
+		}
+		out = append(out, pv)
+	}
+	return out, nil
+}
+
+// Result adalah hasil purge satu tabel.
+type Result struct {
+	Table    string `json:"table"`
+	Archived int64  `json:"archived"`
+	File     string `json:"file,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Apply mengarsip lalu menghapus baris kedaluwarsa di semua tabel.
+func (s *Service) Apply(now time.Time) []Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []Result
+	for _, p := range s.policies() {
+		if p.Days <= 0 {
+			continue
+		}
+		n, file, err := s.archiveAndPurge(p, p.cutoff(now, s.loc), now)
+		res := Result{Table: p.Table, Archived: n, File: file}
+		if err != nil {
+			res.Error = err.Error()
+			log.Printf("⚠️ Retention %s failed: %v", p.Table, err)
+		} else if n > 0 {
+			log.Printf("🗄️ Retention: %d baris %s diarsipkan ke %s", n, p.Table, file)
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// archiveAndPurge menulis baris kedaluwarsa ke file gzip, lalu setelah file
+// tersimpan utuh menghapus baris yang sama (id <= id terakhir yang ditulis).
+func (s *Service) archiveAndPurge(p retentionPolicy, cutoff, now time.Time) (int64, string, error) {
+	var count int64
+	if err := s.db.Table(p.Table).Where(p.condition(), cutoff).Count(&count).Error; err != nil || count == 0 {
+		return 0, "", err
+	}
+
+	if err := os.MkdirAll(s.cfg.ArchiveDir, 0o755); err != nil {
+		return 0, "", err
+	}
+	name := fmt.Sprintf("%s_before_%s_%s.%s.gz", p.Table, cutoff.In(s.loc).Format("20060102"),
+		now.UTC().Format("20060102T150405Z"), s.cfg.ArchiveFormat)
+	path := filepath.Join(s.cfg.ArchiveDir, name)
+	tmp := path + ".tmp"
+
+	f, err := os.Create(tmp)
+	if err != nil {
+		return 0, "", err
+	}
+	written, maxID, err := writeArchive(f, s.db.Table(p.Table).Where(p.condition(), cutoff).Order("id"), s.cfg.ArchiveFormat)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return 0, "", err
+	}
+
+	res := s.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s AND id <= ?", p.Table, p.condition()), cutoff, maxID)
+	if res.Error != nil {
+		return 0, path, fmt.Errorf("archived to %s but delete failed: %w", path, res.Error)
+	}
+	if res.RowsAffected != written {
+		log.Printf("⚠️ Retention %s: %d ditulis, %d dihapus", p.Table, written, res.RowsAffected)
+	}
+	return written, path, nil
+}
+
+// writeArchive men-stream hasil query ke w sebagai JSONL atau CSV ber-gzip.
+func writeArchive(w io.Writer, q *gorm.DB, format string) (int64, int64, error) {
+	rows, err := q.Rows()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return 0, 0, err
+	}
+	idIdx := -1
+	for i, c := range cols {
+		if c == "id" {
+			idIdx = i
+		}
+	}
+	if idIdx < 0 {
+		return 0, 0, fmt.Errorf("table has no id column")
+	}
+
+	gz := gzip.NewWriter(w)
+	var cw *csv.Writer
+	enc := json.NewEncoder(gz)
+	if format == "csv" {
+		cw = csv.NewWriter(gz)
+		if err := cw.Write(cols); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	var written, maxID int64
+	vals := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return written, maxID, err
+		}
+		if id, ok := vals[idIdx].(int64); ok && id > maxID {
+			maxID = id
+		}
+
+		if cw != nil {
+			record := make([]string, len(cols))
+			for i, v := range vals {
+				record[i] = archiveString(v)
+			}
+			err = cw.Write(record)
+		} else {
+			obj := make(map[string]any, len(cols))
+			for i, c := range cols {
+				obj[c] = archiveValue(vals[i])
+			}
+			err = enc.Encode(obj)
+		}
+		if err != nil {
+			return written, maxID, err
+		}
+		written++
+	}
+	if err := rows.Err(); err != nil {
+		return written, maxID, err
+	}
+	if cw != nil {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return written, maxID, err
+		}
+	}
+	return written, maxID, gz.Close()
+}
+
+func archiveValue(v any) any {
+	switch t := v.(type) {
+	case []byte:
+		return string(t)
+	case time.Time:
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return v
+}
+
+func archiveString(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(archiveValue(v))
+}
+
+// Run menjalankan purge berkala di background. Interval <= 0 berarti job
+// dinonaktifkan (time.NewTicker panic untuk nilai itu).
+func (s *Service) Run() {
+	if s.cfg.Interval <= 0 {
+		log.Printf("⏸️ Retention job dinonaktifkan (RETENTION_INTERVAL=%s)", s.cfg.Interval)
+		return
+	}
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.Apply(time.Now())
+	}
+}
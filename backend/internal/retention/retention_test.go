@@ -0,0 +1,106 @@
+package retention
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+func TestRetentionArchivesThenPurges(t *testing.T) {
+	db := storetest.Open(t, &store.Attendance{}, &store.Alarm{}, &store.DoorOpenLog{}, &store.Occupancy{}, &store.CommandLog{}, &store.NotificationOutbox{})
+	cfg := config.Default().Retention
+	cfg.ArchiveDir = t.TempDir()
+	cfg.ArchiveFormat = "jsonl"
+	loc := config.Default().Location
+	svc := New(db, cfg, loc)
+
+	now := time.Date(2025, 10, 6, 12, 0, 0, 0, loc)
+	old := now.AddDate(-3, 0, 0)
+	db.Create(&store.Attendance{AccessID: "A001", Username: "Budi", Arrow: "in", Status: "success", CreatedAt: old})
+	db.Create(&store.Attendance{AccessID: "A002", Username: "Citra", Arrow: "in", Status: "success", CreatedAt: old.Add(time.Hour)})
+	db.Create(&store.Attendance{AccessID: "A001", Username: "Budi", Arrow: "in", Status: "success", CreatedAt: now})
+	db.Create(&store.Alarm{Reason: "lama tapi belum selesai", Status: store.AlarmStatusOpen, CreatedAt: now.AddDate(-6, 0, 0)})
+
+	preview, err := svc.Preview(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := map[string]int64{}
+	for _, p := range preview {
+		rows[p.Table] = p.Rows
+	}
+	if rows["attendances"] != 2 || rows["alarms"] != 0 {
+		t.Fatalf("preview = %+v", preview)
+	}
+	var count int64
+	db.Model(&store.Attendance{}).Count(&count)
+	if count != 3 {
+		t.Fatalf("dry-run changed data: %d rows", count)
+	}
+
+	var file string
+	for _, res := range svc.Apply(now) {
+		if res.Error != "" {
+			t.Fatalf("%s: %s", res.Table, res.Error)
+		}
+		if res.Table == "attendances" {
+			file = res.File
+		}
+	}
+
+	db.Model(&store.Attendance{}).Count(&count)
+	if count != 1 {
+		t.Errorf("attendance rows after purge = %d, want 1", count)
+	}
+	db.Model(&store.Alarm{}).Count(&count)
+	if count != 1 {
+		t.Errorf("unresolved alarm purged")
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	sc := bufio.NewScanner(gz)
+	for sc.Scan() {
+		var row map[string]any
+		if err := json.Unmarshal(sc.Bytes(), &row); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, row["username"].(string))
+	}
+	if len(names) != 2 || names[0] != "Budi" || names[1] != "Citra" {
+		t.Errorf("archived rows = %v", names)
+	}
+}
+
+func TestRunWithNonPositiveIntervalIsDisabled(t *testing.T) {
+	db := storetest.Open(t)
+	cfg := config.Default().Retention
+	for _, interval := range []time.Duration{0, -time.Hour} {
+		cfg.Interval = interval
+		done := make(chan struct{})
+		go func() {
+			New(db, cfg, time.UTC).Run()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("Run with interval %s did not return", interval)
+		}
+	}
+}
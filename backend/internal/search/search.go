@@ -0,0 +1,425 @@
+// Package search menjalankan pencarian event lintas tabel untuk /api/search.
+package search
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/store"
+)
+
+// Jenis event yang bisa dicari lewat /api/search
+const (
+	SearchTypeAttendance = "attendance"
+	SearchTypeAlarm      = "alarm"
+	SearchTypeDoorOpen   = "door_open"
+	SearchTypeCommand    = "command"
+)
+
+const (
+	DefaultLimit = 100
+	MaxLimit     = 500
+)
+
+// searchSource mendeskripsikan satu tabel yang ikut dicari.
+type searchSource struct {
+	Type  string
+	Table string
+	Slot  int               // rowid FTS = id*searchSlots + Slot
+	Field map[string]string // field query -> kolom
+	Text  []string          // kolom untuk free text; sama dengan migrasi search_fts
+}
+
+const searchSlots = 4
+
+var searchSources = []searchSource{
+	{
+		Type: SearchTypeAttendance, Table: "attendances", Slot: 0,
+		Field: map[string]string{"access_id": "access_id", "name": "username", "door": "door_id",
+			"status": "status", "arrow": "arrow", "reason": "reason"},
+		Text: []string{"username", "access_id", "reason"},
+	},
+	{
+		Type: SearchTypeAlarm, Table: "alarms", Slot: 1,
+		Field: map[string]string{"access_id": "access_id", "name": "username", "door": "door_id",
+			"status": "status", "severity": "severity", "reason": "reason"},
+		Text: []string{"username", "access_id", "reason", "notes"},
+	},
+	{
+		Type: SearchTypeDoorOpen, Table: "door_open_logs", Slot: 2,
+		Field: map[string]string{"access_id": "access_id", "name": "username", "door": "door_id"},
+		Text:  []string{"username", "access_id"},
+	},
+	{
+		Type: SearchTypeCommand, Table: "command_logs", Slot: 3,
+		Field: map[string]string{"name": "actor", "door": "target", "status": "result", "source": "source"},
+		Text:  []string{"actor", "command", "target"},
+	},
+}
+
+// alias field yang diterima di query
+var searchFieldAliases = map[string]string{
+	"access_id": "access_id", "access": "access_id", "id": "access_id",
+	"name": "name", "user": "name", "username": "name",
+	"door": "door", "door_id": "door",
+	"status": "status", "arrow": "arrow", "severity": "severity",
+	"reason": "reason", "source": "source",
+}
+
+// searchTerm adalah satu predikat: field:value atau free text (Field kosong).
+type searchTerm struct {
+	Field string
+	Value string
+}
+
+// Query adalah hasil parse query. Groups di-AND, term di dalam satu
+// group di-OR (dipisah kata kunci OR).
+type Query struct {
+	Groups [][]searchTerm
+	Types  map[string]bool
+	From   *time.Time
+	To     *time.Time // eksklusif
+}
+
+// Result adalah satu baris hasil yang diseragamkan lintas tabel.
+type Result struct {
+	Type      string    `json:"type"`
+	ID        uint      `json:"id"`
+	Time      time.Time `json:"time"`
+	AccessID  string    `json:"access_id,omitempty"`
+	Username  string    `json:"username,omitempty"`
+	DoorID    string    `json:"door_id,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	Summary   string    `json:"summary"`
+	Record    any       `json:"record"`
+	createdAt time.Time // untuk sort, Time sudah dikonversi ke zona situs
+}
+
+// tokenizeSearch memecah query berdasarkan spasi dengan dukungan tanda kutip,
+// mis. name:"Dewi Lestari" atau "pintu belakang".
+func tokenizeSearch(s string) []string {
+	var tokens []string
+	var cur strings.Builder
+	inQuote := false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case unicode.IsSpace(r) && !inQuote:
+			if cur.Len() > 0 {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if cur.Len() > 0 {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens
+}
+
+var lastDaysPattern = regexp.MustCompile(`^last-(\d+)d$`)
+
+// parseSearchDate menerjemahkan nilai date: menjadi rentang [from, to).
+func parseSearchDate(v string, now time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	today := config.StartOfDay(now, loc)
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, loc)
+
+	switch v {
+	case "today":
+		return today, today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), today, nil
+	case "this-month":
+		return monthStart, monthStart.AddDate(0, 1, 0), nil
+	case "last-month":
+		return monthStart.AddDate(0, -1, 0), monthStart, nil
+	}
+	if m := lastDaysPattern.FindStringSubmatch(v); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return today.AddDate(0, 0, 1-n), today.AddDate(0, 0, 1), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", v, loc); err == nil {
+		return t, t.AddDate(0, 0, 1), nil
+	}
+	if t, err := time.ParseInLocation("2006-01", v, loc); err == nil {
+		return t, t.AddDate(0, 1, 0), nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("invalid date: %q", v)
+}
+
+// ParseQuery membaca syntax:
+//
+//	access_id:A003 OR name:Dewi door:D02 date:last-month
+//	type:alarm,attendance from:2025-10-01 to:2025-10-31 "pintu belakang"
+//
+// Nilai dipisah koma berarti OR untuk field yang sama.
+func ParseQuery(s string, now time.Time, loc *time.Location) (Query, error) {
+	q := Query{}
+	orNext := false
+
+	for _, tok := range tokenizeSearch(s) {
+		if tok == "OR" {
+			if len(q.Groups) == 0 {
+				return q, fmt.Errorf("OR must follow a term")
+			}
+			orNext = true
+			continue
+		}
+
+		field, value, hasField := strings.Cut(tok, ":")
+		var terms []searchTerm
+		if !hasField || value == "" {
+			terms = []searchTerm{{Value: tok}}
+		} else {
+			field = strings.ToLower(field)
+			switch field {
+			case "type":
+				if q.Types == nil {
+					q.Types = map[string]bool{}
+				}
+				for _, t := range strings.Split(value, ",") {
+					if !isSearchType(t) {
+						return q, fmt.Errorf("unknown type: %q", t)
+					}
+					q.Types[t] = true
+				}
+				continue
+			case "date", "from", "to":
+				from, to, err := parseSearchDate(value, now, loc)
+				if err != nil {
+					return q, err
+				}
+				if field != "to" {
+					q.From = &from
+				}
+				if field != "from" {
+					q.To = &to
+				}
+				continue
+			}
+
+			name, ok := searchFieldAliases[field]
+			if !ok {
+				return q, fmt.Errorf("unknown field: %q", field)
+			}
+			for _, v := range strings.Split(value, ",") {
+				terms = append(terms, searchTerm{Field: name, Value: v})
+			}
+		}
+
+		if orNext {
+			last := len(q.Groups) - 1
+			q.Groups[last] = append(q.Groups[last], terms...)
+			orNext = false
+		} else {
+			q.Groups = append(q.Groups, terms)
+		}
+	}
+	if orNext {
+		return q, fmt.Errorf("OR must be followed by a term")
+	}
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return q, fmt.Errorf("from must not be after to")
+	}
+	return q, nil
+}
+
+func isSearchType(t string) bool {
+	for _, src := range searchSources {
+		if src.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// termCondition membuat kondisi SQL satu term untuk satu sumber. ok=false
+// bila field tidak ada di sumber tsb.
+func (x *Index) termCondition(src searchSource, t searchTerm) (string, []any, bool) {
+	if t.Field != "" {
+		col, ok := src.Field[t.Field]
+		if !ok {
+			return "", nil, false
+		}
+		if t.Field == "reason" {
+			return store.Like(col), []any{"%" + t.Value + "%"}, true
+		}
+		return col + " = ?", []any{t.Value}, true
+	}
+
+	if x.fts {
+		return "id IN (SELECT rowid / ? FROM search_fts WHERE search_fts MATCH ? AND rowid % ? = ?)",
+			[]any{searchSlots, ftsPhrase(t.Value), searchSlots, src.Slot}, true
+	}
+	var conds []string
+	var args []any
+	for _, col := range src.Text {
+		conds = append(conds, store.Like(col))
+		args = append(args, "%"+t.Value+"%")
+	}
+	return "(" + strings.Join(conds, " OR ") + ")", args, true
+}
+
+// ftsPhrase meng-escape term menjadi prefix phrase FTS5.
+func ftsPhrase(v string) string {
+	return `"` + strings.ReplaceAll(v, `"`, `""`) + `"*`
+}
+
+// buildSourceQuery menyusun query satu sumber. ok=false bila sumber pasti
+// tidak punya hasil (mis. arrow:in untuk alarm).
+func (x *Index) buildSourceQuery(src searchSource, q Query, before *time.Time) (*gorm.DB, bool) {
+	if len(q.Types) > 0 && !q.Types[src.Type] {
+		return nil, false
+	}
+
+	tx := x.db.Table(src.Table)
+	for _, group := range q.Groups {
+		var conds []string
+		var args []any
+		for _, t := range group {
+			cond, a, ok := x.termCondition(src, t)
+			if !ok {
+				continue
+			}
+			conds = append(conds, cond)
+			args = append(args, a...)
+		}
+		if len(conds) == 0 {
+			return nil, false
+		}
+		tx = tx.Where("("+strings.Join(conds, " OR ")+")", args...)
+	}
+	if q.From != nil {
+		tx = tx.Where("created_at >= ?", *q.From)
+	}
+	if q.To != nil {
+		tx = tx.Where("created_at < ?", *q.To)
+	}
+	if before != nil {
+		tx = tx.Where("created_at < ?", *before)
+	}
+	return tx, true
+}
+
+// Search menjalankan query ke semua sumber lalu menggabungkan hasil terurut
+// waktu (terbaru dulu).
+func (x *Index) Search(q Query, before *time.Time, limit int) ([]Result, error) {
+	var results []Result
+	loc := x.loc
+
+	for _, src := range searchSources {
+		tx, ok := x.buildSourceQuery(src, q, before)
+		if !ok {
+			continue
+		}
+		tx = tx.Order("created_at desc").Limit(limit)
+
+		switch src.Type {
+		case SearchTypeAttendance:
+			var rows []store.Attendance
+			if err := tx.Find(&rows).Error; err != nil {
+				return nil, err
+			}
+			for _, r := range rows {
+				summary := fmt.Sprintf("%s %s di %s", r.Username, r.Arrow, r.DoorID)
+				if r.Reason != "" {
+					summary += " (" + r.Reason + ")"
+				}
+				results = append(results, Result{Type: src.Type, ID: r.ID, Time: r.CreatedAt.In(loc),
+					AccessID: r.AccessID, Username: r.Username, DoorID: r.DoorID, Status: r.Status,
+					Summary: summary, Record: r, createdAt: r.CreatedAt})
+			}
+		case SearchTypeAlarm:
+			var rows []store.Alarm
+			if err := tx.Find(&rows).Error; err != nil {
+				return nil, err
+			}
+			for _, r := range rows {
+				results = append(results, Result{Type: src.Type, ID: r.ID, Time: r.CreatedAt.In(loc),
+					AccessID: r.AccessID, Username: r.Username, DoorID: r.DoorID, Status: r.Status,
+					Summary: r.Reason, Record: r, createdAt: r.CreatedAt})
+			}
+		case SearchTypeDoorOpen:
+			var rows []store.DoorOpenLog
+			if err := tx.Find(&rows).Error; err != nil {
+				return nil, err
+			}
+			for _, r := range rows {
+				results = append(results, Result{Type: src.Type, ID: r.ID, Time: r.CreatedAt.In(loc),
+					AccessID: r.AccessID, Username: r.Username, DoorID: r.DoorID,
+					Summary: fmt.Sprintf("pintu %s terbuka %d detik", r.DoorID, r.Duration), Record: r, createdAt: r.CreatedAt})
+			}
+		case SearchTypeCommand:
+			var rows []store.CommandLog
+			if err := tx.Find(&rows).Error; err != nil {
+				return nil, err
+			}
+			for _, r := range rows {
+				results = append(results, Result{Type: src.Type, ID: r.ID, Time: r.CreatedAt.In(loc),
+					Username: r.Actor, DoorID: r.Target, Status: r.Result,
+					Summary: fmt.Sprintf("%s %s via %s", r.Command, r.Target, r.Source), Record: r, createdAt: r.CreatedAt})
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].createdAt.After(results[j].createdAt) })
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	if results == nil {
+		results = []Result{}
+	}
+	return results, nil
+}
+
+// Index mencari event di attendance, alarm, door open log dan command log.
+type Index struct {
+	db  *gorm.DB
+	loc *time.Location // zona waktu situs untuk Result.Time
+	// fts bernilai true bila tabel FTS5 search_fts ada. Tabel dan
+	// triggernya dibuat migrasi search_fts, yang hanya berhasil bila
+	// go-sqlite3 dibangun dengan tag sqlite_fts5; tanpa itu pencarian teks
+	// memakai LOWER(kolom) LIKE '%...%' yang memindai seluruh tabel.
+	fts bool
+}
+
+// NewIndex menyiapkan index pencarian dan memeriksa apakah search_fts
+// tersedia.
+func NewIndex(db *gorm.DB, loc *time.Location) *Index {
+	x := &Index{db: db, loc: loc}
+	x.init()
+	return x
+}
+
+// FullText melaporkan apakah pencarian memakai FTS5.
+func (x *Index) FullText() bool {
+	return x.fts
+}
+
+func (x *Index) init() {
+	db := x.db
+	if db.Dialector.Name() != "sqlite" {
+		return
+	}
+
+	var existing int64
+	db.Raw("SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'search_fts'").Scan(&existing)
+	if existing == 0 {
+		log.Println("⚠️ FTS5 index tidak ada (binary tanpa tag sqlite_fts5?), pencarian memakai LIKE")
+		return
+	}
+	x.fts = true
+	log.Println("✅ Search index (FTS5) ready")
+}
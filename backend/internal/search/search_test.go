@@ -0,0 +1,98 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/migrations"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	loc := config.Default().Location
+	now := time.Date(2025, 11, 12, 10, 0, 0, 0, loc)
+
+	q, err := ParseQuery(`access_id:A003 OR name:Dewi door:D02 date:last-month "pintu belakang"`, now, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Groups) != 3 || len(q.Groups[0]) != 2 || q.Groups[2][0].Value != "pintu belakang" {
+		t.Fatalf("groups = %+v", q.Groups)
+	}
+	if !q.From.Equal(time.Date(2025, 10, 1, 0, 0, 0, 0, loc)) || !q.To.Equal(time.Date(2025, 11, 1, 0, 0, 0, 0, loc)) {
+		t.Errorf("range = %v .. %v", q.From, q.To)
+	}
+
+	for _, bad := range []string{"OR name:Dewi", "name:Dewi OR", "colour:red", "type:badge", "date:kemarin"} {
+		if _, err := ParseQuery(bad, now, loc); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestSearchEventsAcrossTables(t *testing.T) {
+	db := storetest.Open(t, &store.Attendance{}, &store.Alarm{}, &store.DoorOpenLog{}, &store.CommandLog{})
+	loc := config.Default().Location
+	x := NewIndex(db, loc)
+	at := func(day, hour int) time.Time { return time.Date(2025, 10, day, hour, 0, 0, 0, loc) }
+	db.Create(&store.Attendance{AccessID: "A003", Username: "Dewi", DoorID: "D02", Arrow: "in", Status: "success", CreatedAt: at(6, 8)})
+	db.Create(&store.Attendance{AccessID: "A001", Username: "Budi", DoorID: "D02", Arrow: "in", Status: "success", CreatedAt: at(6, 9)})
+	db.Create(&store.Alarm{AccessID: "A003", Username: "Dewi", DoorID: "D02", Reason: "3 kali gagal masuk", CreatedAt: at(7, 10)})
+	db.Create(&store.DoorOpenLog{AccessID: "A003", Username: "Dewi", DoorID: "D01", Duration: 90, CreatedAt: at(8, 11)})
+	db.Create(&store.CommandLog{Actor: "admin", Command: "unlock", Target: "D02", Result: "ok", CreatedAt: at(9, 12)})
+	db.Create(&store.Attendance{AccessID: "A003", Username: "Dewi", DoorID: "D02", Arrow: "in", Status: "success", CreatedAt: at(15, 8).AddDate(0, 1, 0)})
+
+	now := time.Date(2025, 11, 20, 0, 0, 0, 0, loc)
+	q, _ := ParseQuery("access_id:A003 OR name:Dewi door:D02 date:last-month", now, loc)
+	results, err := x.Search(q, nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Type != SearchTypeAlarm || results[1].Type != SearchTypeAttendance {
+		t.Fatalf("results = %+v, want alarm then attendance", results)
+	}
+
+	q, _ = ParseQuery("gagal", now, loc)
+	results, _ = x.Search(q, nil, 10)
+	if len(results) != 1 || results[0].Type != SearchTypeAlarm {
+		t.Fatalf("free text results = %+v", results)
+	}
+
+	q, _ = ParseQuery("door:D02 type:command", now, loc)
+	results, _ = x.Search(q, nil, 10)
+	if len(results) != 1 || results[0].Username != "admin" {
+		t.Fatalf("command results = %+v", results)
+	}
+}
+
+func TestSearchFullTextIndex(t *testing.T) {
+	db := storetest.Open(t, &store.Attendance{}, &store.Alarm{}, &store.DoorOpenLog{}, &store.CommandLog{})
+	loc := config.Default().Location
+	// data lama diisi ke index oleh migrasi
+	db.Create(&store.Alarm{AccessID: "A003", Username: "Dewi", DoorID: "D02", Reason: "3 kali gagal masuk"})
+	for _, m := range migrations.All() {
+		if m.Name == "search_fts" {
+			if err := m.Up(db); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	x := NewIndex(db, loc)
+	if !x.FullText() {
+		t.Skip("go-sqlite3 tanpa FTS5; jalankan dengan -tags sqlite_fts5")
+	}
+	db.Create(&store.Attendance{AccessID: "A001", Username: "Budi", DoorID: "D01", Arrow: "in", Status: "denied", Reason: "wrong pin"})
+
+	for term, want := range map[string]string{"gagal": SearchTypeAlarm, "budi": SearchTypeAttendance} {
+		q, _ := ParseQuery(term, time.Now(), loc)
+		results, err := x.Search(q, nil, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 1 || results[0].Type != want {
+			t.Errorf("%q: results = %+v, want one %s", term, results, want)
+		}
+	}
+}
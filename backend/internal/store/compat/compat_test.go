@@ -0,0 +1,143 @@
+package compat
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/analytics"
+	"smart-door-lock/backend/internal/audit"
+	"smart-door-lock/backend/internal/config"
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/migrations"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+// eachDriver menjalankan fn untuk setiap driver pada database yang sudah
+// dimigrasi.
+func eachDriver(t *testing.T, fn func(t *testing.T, db *gorm.DB)) {
+	for _, driver := range storetest.Drivers {
+		t.Run(driver, func(t *testing.T) {
+			db := storetest.OpenDriver(t, driver)
+			if _, err := migrations.New(db).Up(); err != nil {
+				t.Fatal(err)
+			}
+			fn(t, db)
+		})
+	}
+}
+
+func TestMigrationsRoundTrip(t *testing.T) {
+	eachDriver(t, func(t *testing.T, db *gorm.DB) {
+		for _, model := range store.Models() {
+			if !db.Migrator().HasTable(model) {
+				t.Errorf("table for %T missing", model)
+			}
+		}
+
+		m := migrations.New(db)
+		if _, err := m.Down(len(migrations.All())); err != nil {
+			t.Fatal(err)
+		}
+		if db.Migrator().HasTable(&store.User{}) {
+			t.Error("users table still exists after rollback")
+		}
+		if _, err := m.Up(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	eachDriver(t, func(t *testing.T, db *gorm.DB) {
+		st := store.NewGorm(db)
+		if err := st.CreateDoorlockUser(&store.DoorlockUser{Name: "Budi", AccessID: "A001", DoorID: "D01", Pin: "123456", IsActive: true}); err != nil {
+			t.Fatal(err)
+		}
+		if err := st.CreateDoorlockUser(&store.DoorlockUser{Name: "Lain", AccessID: "A001", DoorID: "D01", Pin: "654321"}); err == nil {
+			t.Error("duplicate access_id accepted")
+		}
+
+		until := time.Date(2026, 3, 1, 10, 30, 15, 123456000, time.UTC)
+		if ok, err := st.LockDoorlockUser("A001", until); !ok || err != nil {
+			t.Fatalf("LockDoorlockUser = %v, %v", ok, err)
+		}
+		u, err := st.DoorlockUser("A001")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.LockedUntil == nil || !u.LockedUntil.Equal(until) {
+			t.Errorf("locked_until = %v, want %v", u.LockedUntil, until)
+		}
+		if _, err := st.DoorlockUser("nope"); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("missing user: err = %v", err)
+		}
+
+		for _, name := range []string{"Administrator", "budi"} {
+			db.Create(&store.User{Username: name, IsActive: true})
+		}
+		var found []store.User
+		db.Where(store.Like("username"), "%ADMIN%").Find(&found)
+		if len(found) != 1 || found[0].Username != "Administrator" {
+			t.Errorf("Like search = %+v", found)
+		}
+	})
+}
+
+func TestDailyCountsUseSiteTimezone(t *testing.T) {
+	loc := config.Default().Location
+	eachDriver(t, func(t *testing.T, db *gorm.DB) {
+		// 23:30 dan 00:30 WIB jatuh di tanggal berbeda walau sama di UTC
+		for _, at := range []time.Time{
+			time.Date(2026, 3, 1, 23, 30, 0, 0, loc),
+			time.Date(2026, 3, 2, 0, 30, 0, 0, loc),
+			time.Date(2026, 3, 2, 9, 0, 0, 0, loc),
+		} {
+			db.Create(&store.Attendance{AccessID: "A001", DoorID: "D01", Status: "success", Arrow: "in", CreatedAt: at})
+		}
+
+		days, err := analytics.DailyCounts(db, time.Date(2026, 3, 1, 0, 0, 0, 0, loc), time.Date(2026, 3, 3, 0, 0, 0, 0, loc), loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []analytics.DayCount{{Date: "2026-03-02", Count: 2}, {Date: "2026-03-01", Count: 1}}
+		if len(days) != len(want) || days[0] != want[0] || days[1] != want[1] {
+			t.Errorf("DailyCounts = %+v, want %+v", days, want)
+		}
+	})
+}
+
+func TestAuditChainSurvivesRoundTrip(t *testing.T) {
+	eachDriver(t, func(t *testing.T, db *gorm.DB) {
+		log := audit.New(db)
+		for _, action := range []string{audit.Login, audit.DoorControl, audit.Login} {
+			if _, err := log.Record(store.AuditEvent{Actor: "admin", Action: action, Target: "D01"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		v, err := log.Verify()
+		if err != nil || !v.Valid || v.Checked != 3 {
+			t.Errorf("Verify = %+v, %v", v, err)
+		}
+	})
+}
+
+func TestFrequentAccessRollup(t *testing.T) {
+	eachDriver(t, func(t *testing.T, db *gorm.DB) {
+		now := time.Now()
+		for i := 0; i < 4; i++ {
+			db.Create(&store.Attendance{Username: "Budi", AccessID: "A001", DoorID: "D01", Status: "success", CreatedAt: now.Add(-time.Duration(i) * time.Minute)})
+		}
+		db.Create(&store.Attendance{Username: "Ani", AccessID: "A002", DoorID: "D01", Status: "success", CreatedAt: now})
+
+		rows, err := analytics.NewRollups(db, config.Default().Location).FrequentAccess(now.Add(-time.Hour), 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 1 || rows[0].AccessID != "A001" || rows[0].AccessCount != 4 {
+			t.Errorf("FrequentAccess = %+v", rows)
+		}
+	})
+}
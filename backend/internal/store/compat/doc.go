@@ -0,0 +1,5 @@
+// Package compat berisi test kompatibilitas yang menjalankan migrasi,
+// repository dan query analitik terhadap setiap driver database (sqlite,
+// postgres, mysql). SQLite selalu diuji; postgres dan mysql hanya bila
+// TEST_POSTGRES_DSN / TEST_MYSQL_DSN di-set.
+package compat
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"fmt"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Driver database yang didukung
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+)
+
+// Open membuka database dengan driver tertentu. Untuk sqlite dsn adalah
+// path file database.
+func Open(driver, dsn string) (*gorm.DB, error) {
+	dialector, err := Dialector(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return gorm.Open(dialector, &gorm.Config{})
+}
+
+// Dialector memilih dialector gorm untuk driver. Driver kosong berarti
+// sqlite.
+func Dialector(driver, dsn string) (gorm.Dialector, error) {
+	switch driver {
+	case "", DriverSQLite:
+		return openSQLite(dsn)
+	case DriverPostgres:
+		return postgres.Open(dsn), nil
+	case DriverMySQL:
+		cfg, err := mysqldriver.ParseDSN(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("DSN mysql tidak valid: %w", err)
+		}
+		// kolom DATETIME harus di-scan ke time.Time
+		cfg.ParseTime = true
+		// RowsAffected menghitung baris yang cocok, bukan hanya yang berubah,
+		// supaya update bersyarat tanpa perubahan nilai tidak dianggap gagal
+		cfg.ClientFoundRows = true
+		// presisi mikrodetik, sama dengan yang dipakai hash audit
+		precision := 6
+		return mysql.New(mysql.Config{DSN: cfg.FormatDSN(), DefaultDatetimePrecision: &precision}), nil
+	default:
+		return nil, fmt.Errorf("driver database %q tidak dikenal, pakai sqlite, postgres atau mysql", driver)
+	}
+}
+
+// Like membuat kondisi pencarian teks yang tidak peka huruf besar/kecil di
+// semua dialect; LIKE di PostgreSQL peka huruf besar/kecil sedangkan di
+// SQLite dan MySQL tidak.
+func Like(column string) string {
+	return "LOWER(" + column + ") LIKE LOWER(?)"
+}
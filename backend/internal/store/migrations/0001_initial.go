@@ -0,0 +1,227 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Skema awal dibekukan di sini (bukan memakai store.Models) supaya migrasi
+// ini tetap sama walaupun model berubah. Up memakai AutoMigrate sehingga
+// database lama yang dibuat AutoMigrate di startup langsung diadopsi tanpa
+// kehilangan data.
+
+type user0001 struct {
+	ID             uint   `gorm:"primaryKey"`
+	Username       string `gorm:"size:191;uniqueIndex"`
+	Password       string
+	Role           string
+	IsActive       bool
+	TelegramChatID int64 `gorm:"index"`
+	CreatedAt      time.Time
+}
+
+func (user0001) TableName() string { return "users" }
+
+type attendance0001 struct {
+	ID        uint `gorm:"primaryKey"`
+	Username  string
+	AccessID  string `gorm:"index"`
+	DoorID    string `gorm:"index"`
+	Status    string
+	Reason    string
+	Arrow     string
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (attendance0001) TableName() string { return "attendances" }
+
+type alarm0001 struct {
+	ID             uint `gorm:"primaryKey"`
+	AlarmType      int
+	Username       string
+	AccessID       string `gorm:"index"`
+	DoorID         string `gorm:"index"`
+	Reason         string
+	Severity       string
+	Status         string `gorm:"default:open;index"`
+	AssignedTo     string
+	AcknowledgedBy string
+	AcknowledgedAt *time.Time
+	ResolvedBy     string
+	ResolvedAt     *time.Time
+	EscalatedAt    *time.Time
+	Notes          string
+	CreatedAt      time.Time `gorm:"index"`
+}
+
+func (alarm0001) TableName() string { return "alarms" }
+
+type doorlockUser0001 struct {
+	ID          uint `gorm:"primaryKey"`
+	Name        string
+	AccessID    string `gorm:"size:191;uniqueIndex"`
+	DoorID      string
+	Pin         string `gorm:"size:6"`
+	IsActive    bool
+	LockedUntil *time.Time
+	CreatedAt   time.Time
+}
+
+func (doorlockUser0001) TableName() string { return "doorlock_users" }
+
+type doorOpenLog0001 struct {
+	ID        uint   `gorm:"primaryKey"`
+	DoorID    string `gorm:"index"`
+	AccessID  string `gorm:"index"`
+	Username  string
+	Duration  int
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (doorOpenLog0001) TableName() string { return "door_open_logs" }
+
+type accessFrequency0001 struct {
+	ID          uint   `gorm:"primaryKey"`
+	AccessID    string `gorm:"index"`
+	DoorID      string
+	Username    string
+	AccessCount int
+	TimeFrame   string    `gorm:"index:idx_access_frequency_period"`
+	PeriodStart time.Time `gorm:"index:idx_access_frequency_period"`
+	PeriodEnd   time.Time
+}
+
+func (accessFrequency0001) TableName() string { return "access_frequencies" }
+
+type door0001 struct {
+	ID                  uint   `gorm:"primaryKey"`
+	DoorID              string `gorm:"size:191;uniqueIndex"`
+	Name                string
+	BuzzerID            string
+	BuzzerDuration      int
+	FailedAttemptLimit  int
+	FailedAttemptWindow int
+	LockoutMinutes      int
+	OpenAlarmSeconds    int
+	Zone                string
+	AntiPassback        string
+	AntiPassbackReset   int
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+func (door0001) TableName() string { return "doors" }
+
+type notificationRule0001 struct {
+	ID          uint `gorm:"primaryKey"`
+	Name        string
+	Event       string
+	AlarmType   int
+	DoorID      string
+	MinSeverity string
+	Channel     string
+	Target      string
+	Enabled     bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (notificationRule0001) TableName() string { return "notification_rules" }
+
+type notificationOutbox0001 struct {
+	ID            uint `gorm:"primaryKey"`
+	RuleID        uint
+	AlarmID       uint
+	AlarmType     int
+	DoorID        string
+	Event         string
+	Channel       string
+	Target        string
+	Subject       string
+	Body          string
+	Severity      string
+	Status        string `gorm:"index"`
+	Attempts      int
+	NextAttemptAt time.Time `gorm:"index"`
+	LastError     string
+	SentAt        *time.Time
+	CreatedAt     time.Time
+}
+
+func (notificationOutbox0001) TableName() string { return "notification_outboxes" }
+
+type commandLog0001 struct {
+	ID        uint `gorm:"primaryKey"`
+	Source    string
+	Actor     string
+	Command   string
+	Target    string
+	Result    string
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (commandLog0001) TableName() string { return "command_logs" }
+
+type occupancy0001 struct {
+	ID         uint   `gorm:"primaryKey"`
+	AccessID   string `gorm:"index"`
+	Username   string
+	DoorID     string
+	Zone       string
+	EnteredAt  time.Time
+	ExitedAt   *time.Time `gorm:"index"`
+	ExitDoorID string
+	ExitReason string
+	CreatedAt  time.Time
+}
+
+func (occupancy0001) TableName() string { return "occupancies" }
+
+type auditEvent0001 struct {
+	ID        uint   `gorm:"primaryKey"`
+	Actor     string `gorm:"index"`
+	Action    string `gorm:"index"`
+	Target    string `gorm:"index"`
+	Before    string
+	After     string
+	IP        string
+	PrevHash  string
+	Hash      string    `gorm:"size:64;uniqueIndex"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (auditEvent0001) TableName() string { return "audit_events" }
+
+type rollupState0001 struct {
+	Name      string `gorm:"primaryKey"`
+	LastID    uint
+	UpdatedAt time.Time
+}
+
+func (rollupState0001) TableName() string { return "rollup_states" }
+
+func initialModels() []interface{} {
+	return []interface{}{
+		&user0001{}, &attendance0001{}, &alarm0001{}, &doorlockUser0001{}, &doorOpenLog0001{},
+		&accessFrequency0001{}, &door0001{}, &notificationRule0001{}, &notificationOutbox0001{},
+		&commandLog0001{}, &occupancy0001{}, &auditEvent0001{}, &rollupState0001{},
+	}
+}
+
+var initialSchema = Migration{
+	Version: 1,
+	Name:    "initial_schema",
+	Up: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(initialModels()...)
+	},
+	Down: func(tx *gorm.DB) error {
+		models := initialModels()
+		for i := len(models) - 1; i >= 0; i-- {
+			if err := tx.Migrator().DropTable(models[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
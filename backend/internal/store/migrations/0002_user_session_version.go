@@ -0,0 +1,24 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// user0002 hanya berisi kolom baru di users.
+type user0002 struct {
+	SessionVersion int `gorm:"not null;default:0"`
+}
+
+func (user0002) TableName() string { return "users" }
+
+var userSessionVersion = Migration{
+	Version: 2,
+	Name:    "user_session_version",
+	Up: func(tx *gorm.DB) error {
+		if tx.Migrator().HasColumn(&user0002{}, "SessionVersion") {
+			return nil
+		}
+		return tx.Migrator().AddColumn(&user0002{}, "SessionVersion")
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(&user0002{}, "SessionVersion")
+	},
+}
@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// doorlockUser0003 hanya berisi kolom baru di doorlock_users: masa
+// berlaku, suspend, rotasi PIN dan soft-delete.
+type doorlockUser0003 struct {
+	ExpiresAt     *time.Time `gorm:"index"`
+	SuspendedAt   *time.Time
+	SuspendReason string
+	PinChangedAt  *time.Time
+	DeletedAt     *time.Time `gorm:"index"`
+}
+
+func (doorlockUser0003) TableName() string { return "doorlock_users" }
+
+var doorlockUser0003Columns = []string{"ExpiresAt", "SuspendedAt", "SuspendReason", "PinChangedAt", "DeletedAt"}
+var doorlockUser0003Indexes = []string{"ExpiresAt", "DeletedAt"}
+
+var doorlockUserLifecycle = Migration{
+	Version: 3,
+	Name:    "doorlock_user_lifecycle",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		for _, col := range doorlockUser0003Columns {
+			if m.HasColumn(&doorlockUser0003{}, col) {
+				continue
+			}
+			if err := m.AddColumn(&doorlockUser0003{}, col); err != nil {
+				return err
+			}
+		}
+		for _, field := range doorlockUser0003Indexes {
+			if m.HasIndex(&doorlockUser0003{}, field) {
+				continue
+			}
+			if err := m.CreateIndex(&doorlockUser0003{}, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		for _, field := range doorlockUser0003Indexes {
+			if err := m.DropIndex(&doorlockUser0003{}, field); err != nil {
+				return err
+			}
+		}
+		for _, col := range doorlockUser0003Columns {
+			if err := m.DropColumn(&doorlockUser0003{}, col); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// auditEvent0004 hanya berisi prev_hash. Index unik membuat dua event
+// yang menyambung ke ujung rantai yang sama tidak mungkin tersimpan
+// bersamaan, walaupun ditulis dari proses yang berbeda.
+type auditEvent0004 struct {
+	PrevHash string `gorm:"size:64;uniqueIndex"`
+}
+
+func (auditEvent0004) TableName() string { return "audit_events" }
+
+var auditPrevHashUnique = Migration{
+	Version: 4,
+	Name:    "audit_prev_hash_unique",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if m.HasIndex(&auditEvent0004{}, "PrevHash") {
+			return nil
+		}
+		// rantai yang sudah bercabang tidak bisa diberi index unik
+		var forks int64
+		err := tx.Table("audit_events").Select("prev_hash").Group("prev_hash").
+			Having("COUNT(*) > 1").Count(&forks).Error
+		if err != nil {
+			return err
+		}
+		if forks > 0 {
+			return fmt.Errorf("audit_events punya %d prev_hash ganda; jalankan `audit verify` dan perbaiki rantai dulu", forks)
+		}
+		// MySQL tidak bisa meng-index kolom longtext
+		if tx.Dialector.Name() == "mysql" {
+			if err := m.AlterColumn(&auditEvent0004{}, "PrevHash"); err != nil {
+				return err
+			}
+		}
+		return m.CreateIndex(&auditEvent0004{}, "PrevHash")
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropIndex(&auditEvent0004{}, "PrevHash")
+	},
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// alarm0005 hanya berisi dedup_key: kunci stabil untuk alarm yang dibuat
+// berulang oleh pemindai (mis. anomali per access_id dan tanggal), karena
+// reason bisa berubah antar pemindaian.
+type alarm0005 struct {
+	DedupKey string `gorm:"size:191;index"`
+}
+
+func (alarm0005) TableName() string { return "alarms" }
+
+var alarmDedupKey = Migration{
+	Version: 5,
+	Name:    "alarm_dedup_key",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if !m.HasColumn(&alarm0005{}, "DedupKey") {
+			if err := m.AddColumn(&alarm0005{}, "DedupKey"); err != nil {
+				return err
+			}
+		}
+		if m.HasIndex(&alarm0005{}, "DedupKey") {
+			return nil
+		}
+		return m.CreateIndex(&alarm0005{}, "DedupKey")
+	},
+	Down: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if err := m.DropIndex(&alarm0005{}, "DedupKey"); err != nil {
+			return err
+		}
+		return m.DropColumn(&alarm0005{}, "DedupKey")
+	},
+}
@@ -0,0 +1,95 @@
+package migrations
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// searchFTS0006 adalah salinan beku sumber pencarian teks saat migrasi ini
+// dibuat. rowid search_fts = id*searchFTSSlots + slot, sama dengan yang
+// dipakai paket search; mengubah kolom berarti migrasi baru.
+var searchFTS0006 = []struct {
+	table string
+	slot  int
+	text  []string
+}{
+	{"attendances", 0, []string{"username", "access_id", "reason"}},
+	{"alarms", 1, []string{"username", "access_id", "reason", "notes"}},
+	{"door_open_logs", 2, []string{"username", "access_id"}},
+	{"command_logs", 3, []string{"actor", "command", "target"}},
+}
+
+const searchFTSSlots = 4
+
+// searchFTS membuat tabel FTS5 search_fts beserta trigger yang menjaganya
+// sinkron dengan tabel sumber. Hanya untuk SQLite yang dibangun dengan tag
+// sqlite_fts5; tanpa modul fts5 (atau di Postgres/MySQL) migrasi tidak
+// membuat apa-apa dan pencarian memakai LIKE.
+var searchFTS = Migration{
+	Version: 6,
+	Name:    "search_fts",
+	Up: func(tx *gorm.DB) error {
+		if tx.Dialector.Name() != "sqlite" {
+			return nil
+		}
+		// versi lama membuat search_fts saat startup; trigger dibuat ulang
+		// bila belum ada tetapi index tidak diisi dua kali
+		var existing int64
+		if err := tx.Raw("SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'search_fts'").Scan(&existing).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("CREATE VIRTUAL TABLE IF NOT EXISTS search_fts USING fts5(body)").Error; err != nil {
+			if strings.Contains(err.Error(), "no such module: fts5") {
+				return nil
+			}
+			return err
+		}
+
+		for _, src := range searchFTS0006 {
+			body := func(prefix string) string {
+				parts := make([]string, len(src.text))
+				for i, col := range src.text {
+					parts[i] = fmt.Sprintf("coalesce(%s.%s, '')", prefix, col)
+				}
+				return strings.Join(parts, " || ' ' || ")
+			}
+			rowid := fmt.Sprintf("new.id * %d + %d", searchFTSSlots, src.slot)
+			oldRowid := fmt.Sprintf("old.id * %d + %d", searchFTSSlots, src.slot)
+			stmts := []string{
+				fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS search_fts_%[1]s_ai AFTER INSERT ON %[1]s BEGIN
+					INSERT INTO search_fts(rowid, body) VALUES (%[2]s, %[3]s); END`, src.table, rowid, body("new")),
+				fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS search_fts_%[1]s_au AFTER UPDATE ON %[1]s BEGIN
+					DELETE FROM search_fts WHERE rowid = %[2]s;
+					INSERT INTO search_fts(rowid, body) VALUES (%[3]s, %[4]s); END`, src.table, oldRowid, rowid, body("new")),
+				fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS search_fts_%[1]s_ad AFTER DELETE ON %[1]s BEGIN
+					DELETE FROM search_fts WHERE rowid = %[2]s; END`, src.table, oldRowid),
+			}
+			if existing == 0 {
+				// isi index dari data lama
+				stmts = append(stmts, fmt.Sprintf("INSERT INTO search_fts(rowid, body) SELECT id * %d + %d, %s FROM %s",
+					searchFTSSlots, src.slot, body(src.table), src.table))
+			}
+			for _, stmt := range stmts {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return fmt.Errorf("search_fts %s: %w", src.table, err)
+				}
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		if tx.Dialector.Name() != "sqlite" {
+			return nil
+		}
+		for _, src := range searchFTS0006 {
+			for _, suffix := range []string{"ai", "au", "ad"} {
+				if err := tx.Exec(fmt.Sprintf("DROP TRIGGER IF EXISTS search_fts_%s_%s", src.table, suffix)).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Exec("DROP TABLE IF EXISTS search_fts").Error
+	},
+}
@@ -0,0 +1,192 @@
+// Package migrations menjalankan migrasi skema berversi. Setiap migrasi
+// punya Up dan Down, dan versi yang sudah dijalankan dicatat di tabel
+// schema_migrations sehingga perubahan yang tidak bisa dilakukan
+// AutoMigrate (rename kolom, constraint, migrasi data) tetap tercatat dan
+// bisa di-rollback.
+package migrations
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration adalah satu langkah perubahan skema. Up dan Down dijalankan
+// di dalam transaksi bersama pencatatan versinya.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration adalah satu baris di schema_migrations.
+type SchemaMigration struct {
+	Version   int       `json:"version" gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+func (SchemaMigration) TableName() string { return "schema_migrations" }
+
+// Status adalah keadaan satu migrasi untuk `migrate status`.
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	// Unknown berarti versi tercatat di database tetapi tidak ada di binary
+	// ini (database lebih baru).
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// all adalah daftar migrasi, diurutkan menurut versi. Tambahkan migrasi
+// baru di akhir; jangan ubah migrasi yang sudah dirilis.
+var all = []Migration{
+	initialSchema,
+	userSessionVersion,
+	doorlockUserLifecycle,
+	auditPrevHashUnique,
+	alarmDedupKey,
+	searchFTS,
+}
+
+// All mengembalikan salinan daftar migrasi bawaan.
+func All() []Migration {
+	return append([]Migration(nil), all...)
+}
+
+// Migrator menjalankan daftar migrasi terhadap satu database.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New memakai migrasi bawaan.
+func New(db *gorm.DB) *Migrator {
+	return NewWith(db, all)
+}
+
+// NewWith memakai daftar migrasi tertentu (untuk test).
+func NewWith(db *gorm.DB, migrations []Migration) *Migrator {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return &Migrator{db: db, migrations: sorted}
+}
+
+func (m *Migrator) applied() (map[int]SchemaMigration, error) {
+	if err := m.db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("gagal menyiapkan schema_migrations: %w", err)
+	}
+	var rows []SchemaMigration
+	if err := m.db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[int]SchemaMigration, len(rows))
+	for _, r := range rows {
+		out[r.Version] = r
+	}
+	return out, nil
+}
+
+func (m *Migrator) known(version int) bool {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Up menjalankan semua migrasi yang belum diterapkan secara berurutan dan
+// mengembalikan yang baru saja dijalankan. Berhenti pada migrasi pertama
+// yang gagal; migrasi sebelumnya tetap tercatat.
+func (m *Migrator) Up() ([]Migration, error) {
+	done, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	for version := range done {
+		if !m.known(version) {
+			return nil, fmt.Errorf("database memiliki migrasi %d yang tidak dikenal binary ini", version)
+		}
+	}
+
+	var ran []Migration
+	for _, mig := range m.migrations {
+		if _, ok := done[mig.Version]; ok {
+			continue
+		}
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := mig.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: mig.Version, Name: mig.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return ran, fmt.Errorf("migrasi %d (%s) gagal: %w", mig.Version, mig.Name, err)
+		}
+		ran = append(ran, mig)
+	}
+	return ran, nil
+}
+
+// Down me-rollback n migrasi terakhir yang sudah diterapkan, dari versi
+// tertinggi, dan mengembalikan yang di-rollback.
+func (m *Migrator) Down(n int) ([]Migration, error) {
+	done, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var rolled []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(rolled) < n; i-- {
+		mig := m.migrations[i]
+		if _, ok := done[mig.Version]; !ok {
+			continue
+		}
+		if mig.Down == nil {
+			return rolled, fmt.Errorf("migrasi %d (%s) tidak bisa di-rollback", mig.Version, mig.Name)
+		}
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := mig.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, mig.Version).Error
+		})
+		if err != nil {
+			return rolled, fmt.Errorf("rollback migrasi %d (%s) gagal: %w", mig.Version, mig.Name, err)
+		}
+		rolled = append(rolled, mig)
+	}
+	return rolled, nil
+}
+
+// Status mengembalikan semua migrasi yang dikenal beserta versi di
+// database yang tidak dikenal.
+func (m *Migrator) Status() ([]Status, error) {
+	done, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Status
+	for _, mig := range m.migrations {
+		s := Status{Version: mig.Version, Name: mig.Name}
+		if row, ok := done[mig.Version]; ok {
+			at := row.AppliedAt
+			s.Applied, s.AppliedAt = true, &at
+		}
+		out = append(out, s)
+	}
+	for version, row := range done {
+		if !m.known(version) {
+			at := row.AppliedAt
+			out = append(out, Status{Version: version, Name: row.Name, Applied: true, AppliedAt: &at, Unknown: true})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
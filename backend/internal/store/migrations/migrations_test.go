@@ -0,0 +1,235 @@
+package migrations
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+// assertSchemaCoversModels memastikan setiap field di store.Models() punya
+// kolom, supaya perubahan model tanpa migrasi baru ketahuan.
+func assertSchemaCoversModels(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	for _, model := range store.Models() {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatal(err)
+		}
+		if !db.Migrator().HasTable(stmt.Schema.Table) {
+			t.Errorf("table %s missing", stmt.Schema.Table)
+			continue
+		}
+		for _, f := range stmt.Schema.Fields {
+			if f.DBName != "" && !db.Migrator().HasColumn(model, f.DBName) {
+				t.Errorf("column %s.%s missing: add a migration", stmt.Schema.Table, f.DBName)
+			}
+		}
+	}
+}
+
+func TestUpOnEmptyDatabase(t *testing.T) {
+	db := storetest.Open(t)
+	m := New(db)
+
+	ran, err := m.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ran) != len(All()) {
+		t.Fatalf("ran %d migrations, want %d", len(ran), len(All()))
+	}
+	assertSchemaCoversModels(t, db)
+
+	// kedua kali tidak ada yang dijalankan
+	if ran, err := m.Up(); err != nil || len(ran) != 0 {
+		t.Errorf("second Up = %d, %v", len(ran), err)
+	}
+}
+
+// legacySchema adalah salinan beku skema SQLite yang dibuat AutoMigrate di
+// startup sebelum ada schema_migrations. Jangan diubah mengikuti model:
+// test adopsi harus tetap memakai database lama apa adanya.
+const legacySchema = `
+CREATE TABLE users (id integer PRIMARY KEY AUTOINCREMENT,username text,password text,role text,is_active numeric,telegram_chat_id integer,created_at datetime);
+CREATE INDEX idx_users_telegram_chat_id ON users(telegram_chat_id);
+CREATE UNIQUE INDEX idx_users_username ON users(username);
+CREATE TABLE attendances (id integer PRIMARY KEY AUTOINCREMENT,username text,access_id text,door_id text,status text,reason text,arrow text,created_at datetime);
+CREATE INDEX idx_attendances_created_at ON attendances(created_at);
+CREATE INDEX idx_attendances_door_id ON attendances(door_id);
+CREATE INDEX idx_attendances_access_id ON attendances(access_id);
+CREATE TABLE alarms (id integer PRIMARY KEY AUTOINCREMENT,alarm_type integer,username text,access_id text,door_id text,reason text,severity text,status text DEFAULT "open",assigned_to text,acknowledged_by text,acknowledged_at datetime,resolved_by text,resolved_at datetime,escalated_at datetime,notes text,created_at datetime);
+CREATE INDEX idx_alarms_created_at ON alarms(created_at);
+CREATE INDEX idx_alarms_status ON alarms(status);
+CREATE INDEX idx_alarms_door_id ON alarms(door_id);
+CREATE INDEX idx_alarms_access_id ON alarms(access_id);
+CREATE TABLE doorlock_users (id integer PRIMARY KEY AUTOINCREMENT,name text,access_id text,door_id text,pin text,is_active numeric,locked_until datetime,created_at datetime);
+CREATE UNIQUE INDEX idx_doorlock_users_access_id ON doorlock_users(access_id);
+CREATE TABLE door_open_logs (id integer PRIMARY KEY AUTOINCREMENT,door_id text,access_id text,username text,duration integer,created_at datetime);
+CREATE INDEX idx_door_open_logs_created_at ON door_open_logs(created_at);
+CREATE INDEX idx_door_open_logs_access_id ON door_open_logs(access_id);
+CREATE INDEX idx_door_open_logs_door_id ON door_open_logs(door_id);
+CREATE TABLE access_frequencies (id integer PRIMARY KEY AUTOINCREMENT,access_id text,door_id text,username text,access_count integer,time_frame text,period_start datetime,period_end datetime);
+CREATE INDEX idx_access_frequency_period ON access_frequencies(time_frame,period_start);
+CREATE INDEX idx_access_frequencies_access_id ON access_frequencies(access_id);
+CREATE TABLE doors (id integer PRIMARY KEY AUTOINCREMENT,door_id text,name text,buzzer_id text,buzzer_duration integer,failed_attempt_limit integer,failed_attempt_window integer,lockout_minutes integer,open_alarm_seconds integer,zone text,anti_passback text,anti_passback_reset integer,created_at datetime,updated_at datetime);
+CREATE UNIQUE INDEX idx_doors_door_id ON doors(door_id);
+CREATE TABLE notification_rules (id integer PRIMARY KEY AUTOINCREMENT,name text,event text,alarm_type integer,door_id text,min_severity text,channel text,target text,enabled numeric,created_at datetime,updated_at datetime);
+CREATE TABLE notification_outboxes (id integer PRIMARY KEY AUTOINCREMENT,rule_id integer,alarm_id integer,alarm_type integer,door_id text,event text,channel text,target text,subject text,body text,severity text,status text,attempts integer,next_attempt_at datetime,last_error text,sent_at datetime,created_at datetime);
+CREATE INDEX idx_notification_outboxes_next_attempt_at ON notification_outboxes(next_attempt_at);
+CREATE INDEX idx_notification_outboxes_status ON notification_outboxes(status);
+CREATE TABLE command_logs (id integer PRIMARY KEY AUTOINCREMENT,source text,actor text,command text,target text,result text,created_at datetime);
+CREATE INDEX idx_command_logs_created_at ON command_logs(created_at);
+CREATE TABLE occupancies (id integer PRIMARY KEY AUTOINCREMENT,access_id text,username text,door_id text,zone text,entered_at datetime,exited_at datetime,exit_door_id text,exit_reason text,created_at datetime);
+CREATE INDEX idx_occupancies_exited_at ON occupancies(exited_at);
+CREATE INDEX idx_occupancies_access_id ON occupancies(access_id);
+CREATE TABLE audit_events (id integer PRIMARY KEY AUTOINCREMENT,actor text,action text,target text,before text,after text,ip text,prev_hash text,hash text,created_at datetime);
+CREATE INDEX idx_audit_events_created_at ON audit_events(created_at);
+CREATE UNIQUE INDEX idx_audit_events_hash ON audit_events(hash);
+CREATE INDEX idx_audit_events_target ON audit_events(target);
+CREATE INDEX idx_audit_events_action ON audit_events(action);
+CREATE INDEX idx_audit_events_actor ON audit_events(actor);
+CREATE TABLE rollup_states (name text,last_id integer,updated_at datetime,PRIMARY KEY (name));
+INSERT INTO users (username,password,role,is_active) VALUES ('admin','x','admin',1);
+INSERT INTO doorlock_users (name,access_id,door_id,pin,is_active) VALUES ('Budi','A001','D01','123456',1);
+`
+
+func TestUpAdoptsAutoMigratedDatabase(t *testing.T) {
+	// snapshot skema lama: dibuat AutoMigrate di startup, tanpa schema_migrations
+	db := storetest.Open(t)
+	for _, stmt := range strings.Split(strings.TrimSpace(legacySchema), ";\n") {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	if _, err := New(db).Up(); err != nil {
+		t.Fatal(err)
+	}
+	assertSchemaCoversModels(t, db)
+
+	var users, doorlockUsers int64
+	db.Model(&store.User{}).Count(&users)
+	db.Model(&store.DoorlockUser{}).Count(&doorlockUsers)
+	if users != 1 || doorlockUsers != 1 {
+		t.Errorf("data lost: users=%d doorlock_users=%d", users, doorlockUsers)
+	}
+
+	statuses, err := New(db).Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if !s.Applied {
+			t.Errorf("migration %d not recorded", s.Version)
+		}
+	}
+}
+
+func TestDownThenUp(t *testing.T) {
+	db := storetest.Open(t)
+	m := New(db)
+	if _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	rolled, err := m.Down(len(All()))
+	if err != nil || len(rolled) != len(All()) {
+		t.Fatalf("Down = %d, %v", len(rolled), err)
+	}
+	if db.Migrator().HasTable(&store.User{}) {
+		t.Error("users table still exists after rollback")
+	}
+
+	if _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	assertSchemaCoversModels(t, db)
+}
+
+func TestOrderingAndFailure(t *testing.T) {
+	db := storetest.Open(t)
+	var order []int
+	step := func(v int, fail bool) Migration {
+		return Migration{
+			Version: v,
+			Name:    "step",
+			Up: func(tx *gorm.DB) error {
+				if fail {
+					return errors.New("boom")
+				}
+				order = append(order, v)
+				return nil
+			},
+			Down: func(tx *gorm.DB) error {
+				order = append(order, -v)
+				return nil
+			},
+		}
+	}
+
+	ran, err := NewWith(db, []Migration{step(3, true), step(1, false), step(2, false)}).Up()
+	if err == nil || !strings.Contains(err.Error(), "migrasi 3") {
+		t.Fatalf("err = %v, want failure on migration 3", err)
+	}
+	if len(ran) != 2 || order[0] != 1 || order[1] != 2 {
+		t.Fatalf("ran %d, order %v", len(ran), order)
+	}
+
+	statuses, _ := NewWith(db, []Migration{step(1, false), step(2, false), step(3, false)}).Status()
+	if !statuses[0].Applied || !statuses[1].Applied || statuses[2].Applied {
+		t.Errorf("statuses = %+v", statuses)
+	}
+
+	order = nil
+	if _, err := NewWith(db, []Migration{step(1, false), step(2, false)}).Down(1); err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 1 || order[0] != -2 {
+		t.Errorf("Down rolled back %v, want [-2]", order)
+	}
+}
+
+func TestUpRejectsUnknownVersion(t *testing.T) {
+	db := storetest.Open(t)
+	noop := func(tx *gorm.DB) error { return nil }
+	newer := []Migration{{Version: 1, Name: "a", Up: noop}, {Version: 2, Name: "b", Up: noop}}
+	if _, err := NewWith(db, newer).Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	older := NewWith(db, newer[:1])
+	if _, err := older.Up(); err == nil || !strings.Contains(err.Error(), "tidak dikenal") {
+		t.Errorf("err = %v, want unknown version error", err)
+	}
+	statuses, err := older.Status()
+	if err != nil || len(statuses) != 2 || !statuses[1].Unknown {
+		t.Errorf("statuses = %+v, %v", statuses, err)
+	}
+}
+
+func TestAuditPrevHashUniqueRefusesForkedChain(t *testing.T) {
+	db := storetest.Open(t)
+	if _, err := NewWith(db, all[:3]).Up(); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&auditEvent0001{Action: "a", PrevHash: "", Hash: "h1"})
+	db.Create(&auditEvent0001{Action: "b", PrevHash: "h1", Hash: "h2"})
+	db.Create(&auditEvent0001{Action: "c", PrevHash: "h1", Hash: "h3"})
+
+	if _, err := New(db).Up(); err == nil || !strings.Contains(err.Error(), "prev_hash ganda") {
+		t.Fatalf("err = %v, want forked chain error", err)
+	}
+
+	db.Delete(&auditEvent0001{}, "hash = ?", "h3")
+	if _, err := New(db).Up(); err != nil {
+		t.Fatal(err)
+	}
+	if !db.Migrator().HasIndex(&auditEvent0004{}, "PrevHash") {
+		t.Error("prev_hash index missing")
+	}
+}
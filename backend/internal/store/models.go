@@ -0,0 +1,249 @@
+package store
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ====== MODELS ======
+type User struct {
+	ID             uint   `json:"id" gorm:"primaryKey"`
+	Username       string `json:"username" gorm:"size:191;uniqueIndex"`
+	Password       string `json:"-"` // hash bcrypt; MD5 lama diganti saat login
+	Role           string `json:"role"`
+	IsActive       bool   `json:"is_active"`
+	TelegramChatID int64  `json:"telegram_chat_id" gorm:"index"` // 0 = tidak terhubung ke bot
+	// SessionVersion dinaikkan untuk mencabut semua token login user
+	SessionVersion int       `json:"-" gorm:"not null;default:0"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Role user dashboard
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+type Attendance struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Username  string    `json:"username"`
+	AccessID  string    `json:"access_id" gorm:"index"`
+	DoorID    string    `json:"door_id" gorm:"index"`
+	Status    string    `json:"status"`           // "success" atau "denied"
+	Reason    string    `json:"reason,omitempty"` // alasan penolakan / pelanggaran
+	Arrow     string    `json:"arrow"`            // "in" atau "out"
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// ====== ALARM TYPES ======
+const (
+	AlarmTypeFailedAttempts  = 1 // "3 kali gagal masuk"
+	AlarmTypeDoorOpenTooLong = 2 // "Pintu terbuka > 1 menit"
+	AlarmTypeAntiPassback    = 3 // akses "in"/"out" dua kali berturut-turut
+	AlarmTypeAnomaly         = 4 // pola akses menyimpang dari baseline
+)
+
+// ====== ALARM LIFECYCLE ======
+const (
+	AlarmStatusOpen         = "open"
+	AlarmStatusAcknowledged = "acknowledged"
+	AlarmStatusResolved     = "resolved"
+)
+
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// SeverityRank mengurutkan severity untuk perbandingan min_severity.
+var SeverityRank = map[string]int{
+	SeverityLow:      1,
+	SeverityMedium:   2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+type Alarm struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	AlarmType      int        `json:"alarm_type"`
+	Username       string     `json:"username"`
+	AccessID       string     `json:"access_id" gorm:"index"`
+	DoorID         string     `json:"door_id" gorm:"index"`
+	Reason         string     `json:"reason"`
+	Severity       string     `json:"severity"`
+	Status         string     `json:"status" gorm:"default:open;index"` // open, acknowledged, resolved
+	AssignedTo     string     `json:"assigned_to"`
+	AcknowledgedBy string     `json:"acknowledged_by"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	ResolvedBy     string     `json:"resolved_by"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	EscalatedAt    *time.Time `json:"escalated_at"`
+	Notes          string     `json:"notes"`
+	// DedupKey diisi alarm otomatis yang tidak boleh dibuat dua kali untuk
+	// kejadian yang sama, mis. "anomaly:spike|A001|2025-10-06".
+	DedupKey  string    `json:"dedup_key,omitempty" gorm:"size:191;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+type DoorlockUser struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Name        string     `json:"name"`
+	AccessID    string     `json:"access_id" gorm:"size:191;uniqueIndex"`
+	DoorID      string     `json:"door_id"`
+	Pin         string     `json:"-" gorm:"size:6"` // hanya keluar lewat export admin
+	IsActive    bool       `json:"is_active"`
+	LockedUntil *time.Time `json:"locked_until"`
+	// ExpiresAt: setelah waktu ini akses ditolak dan user dinonaktifkan
+	// otomatis. Nil berarti tidak kedaluwarsa.
+	ExpiresAt     *time.Time `json:"expires_at" gorm:"index"`
+	SuspendedAt   *time.Time `json:"suspended_at"`
+	SuspendReason string     `json:"suspend_reason"`
+	PinChangedAt  *time.Time `json:"pin_changed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	// Delete hanya mengisi deleted_at sehingga riwayat attendance tetap
+	// bisa dirujuk dan access_id-nya tidak dipakai ulang.
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// Door menyimpan konfigurasi per pintu. Nilai 0 berarti pakai default.
+type Door struct {
+	ID                  uint      `json:"id" gorm:"primaryKey"`
+	DoorID              string    `json:"door_id" gorm:"size:191;uniqueIndex"`
+	Name                string    `json:"name"`
+	BuzzerID            string    `json:"buzzer_id"`
+	BuzzerDuration      int       `json:"buzzer_duration"`       // detik
+	FailedAttemptLimit  int       `json:"failed_attempt_limit"`  // jumlah gagal sebelum alarm
+	FailedAttemptWindow int       `json:"failed_attempt_window"` // detik
+	LockoutMinutes      int       `json:"lockout_minutes"`       // 0 = tanpa lockout
+	OpenAlarmSeconds    int       `json:"open_alarm_seconds"`    // batas pintu terbuka
+	Zone                string    `json:"zone"`
+	AntiPassback        string    `json:"anti_passback"`       // off, soft, hard
+	AntiPassbackReset   int       `json:"anti_passback_reset"` // menit, 0 = reset tiap hari
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// ====== TREND ANALYSIS MODELS ======
+type DoorOpenLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	DoorID    string    `json:"door_id" gorm:"index"`
+	AccessID  string    `json:"access_id" gorm:"index"`
+	Username  string    `json:"username"`
+	Duration  int       `json:"duration"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// AccessFrequency adalah rollup jumlah akses per access_id & pintu per
+// periode (hour, day, week), diisi oleh scheduler rollup di paket analytics.
+type AccessFrequency struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	AccessID    string    `json:"access_id" gorm:"index"`
+	DoorID      string    `json:"door_id"`
+	Username    string    `json:"username"`
+	AccessCount int       `json:"access_count"`
+	TimeFrame   string    `json:"time_frame" gorm:"index:idx_access_frequency_period"`
+	PeriodStart time.Time `json:"period_start" gorm:"index:idx_access_frequency_period"`
+	PeriodEnd   time.Time `json:"period_end"`
+}
+
+// RollupState menyimpan id attendance terakhir yang sudah masuk rollup.
+// Attendance yang datang terlambat (created_at lama, id baru) tetap
+// terdeteksi karena watermark berdasarkan id, bukan waktu.
+type RollupState struct {
+	Name      string    `json:"name" gorm:"primaryKey"`
+	LastID    uint      `json:"last_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ====== NOTIFICATION MODELS ======
+
+// NotificationRule menentukan ke mana notifikasi dikirim. Field kosong
+// (0 atau "") berarti cocok dengan semua nilai.
+type NotificationRule struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name"`
+	Event       string    `json:"event"` // alarm, escalation, resolved
+	AlarmType   int       `json:"alarm_type"`
+	DoorID      string    `json:"door_id"`
+	MinSeverity string    `json:"min_severity"`
+	Channel     string    `json:"channel"`
+	Target      string    `json:"target"`
+	Enabled     bool      `json:"enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NotificationOutbox menyimpan setiap pengiriman agar bisa di-retry.
+type NotificationOutbox struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	RuleID        uint       `json:"rule_id"`
+	AlarmID       uint       `json:"alarm_id"`
+	AlarmType     int        `json:"alarm_type"`
+	DoorID        string     `json:"door_id"`
+	Event         string     `json:"event"`
+	Channel       string     `json:"channel"`
+	Target        string     `json:"target"`
+	Subject       string     `json:"subject"`
+	Body          string     `json:"body"`
+	Severity      string     `json:"severity"`
+	Status        string     `json:"status" gorm:"index"` // pending, sent, failed
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index"`
+	LastError     string     `json:"last_error"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// CommandLog mencatat setiap perintah ke perangkat dan perintah bot,
+// baik dari REST API maupun Telegram.
+type CommandLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Source    string    `json:"source"` // api, telegram
+	Actor     string    `json:"actor"`
+	Command   string    `json:"command"`
+	Target    string    `json:"target"`
+	Result    string    `json:"result"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// Occupancy adalah satu kunjungan: dibuka oleh "in", ditutup oleh "out",
+// oleh "in" berikutnya (out terlewat) atau oleh expiry akhir hari.
+type Occupancy struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	AccessID   string     `json:"access_id" gorm:"index"`
+	Username   string     `json:"username"`
+	DoorID     string     `json:"door_id"`
+	Zone       string     `json:"zone"`
+	EnteredAt  time.Time  `json:"entered_at"`
+	ExitedAt   *time.Time `json:"exited_at" gorm:"index"`
+	ExitDoorID string     `json:"exit_door_id"`
+	ExitReason string     `json:"exit_reason"` // out, reentry, expired
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// AuditEvent mencatat satu aksi administratif. Setiap baris menyimpan hash
+// baris sebelumnya sehingga perubahan/penghapusan baris lama memutus rantai.
+type AuditEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Actor     string    `json:"actor" gorm:"index"`
+	Action    string    `json:"action" gorm:"index"`
+	Target    string    `json:"target" gorm:"index"`
+	Before    string    `json:"before"` // JSON, kosong bila tidak ada
+	After     string    `json:"after"`
+	IP        string    `json:"ip"`
+	PrevHash  string    `json:"prev_hash" gorm:"size:64;uniqueIndex"`
+	Hash      string    `json:"hash" gorm:"size:64;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// Models mengembalikan semua model. Skema produksi dibuat lewat paket
+// migrations; daftar ini dipakai test (storetest) dan sebagai acuan drift.
+func Models() []interface{} {
+	return []interface{}{
+		&User{}, &Attendance{}, &Alarm{}, &DoorlockUser{}, &DoorOpenLog{}, &AccessFrequency{}, &Door{},
+		&NotificationRule{}, &NotificationOutbox{}, &CommandLog{}, &Occupancy{}, &AuditEvent{}, &RollupState{},
+	}
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// SQLite menyimpan waktu sebagai teks lengkap dengan offset zona waktunya,
+// sehingga perbandingan created_at >= ? antara waktu server (UTC) dan batas
+// hari di zona waktu situs dibandingkan sebagai string dan hasilnya salah.
+// PostgreSQL dan MySQL membandingkan instant, jadi di sini semua parameter
+// waktu dinormalisasi ke UTC agar SQLite berperilaku sama.
+
+func openSQLite(dsn string) (gorm.Dialector, error) {
+	db, err := sql.Open(sqlite.DriverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return sqlite.New(sqlite.Config{DSN: dsn, Conn: utcPool{db}}), nil
+}
+
+func utcArgs(args []interface{}) []interface{} {
+	for i, a := range args {
+		switch v := a.(type) {
+		case time.Time:
+			args[i] = v.UTC()
+		case *time.Time:
+			if v != nil {
+				args[i] = v.UTC()
+			}
+		}
+	}
+	return args
+}
+
+// utcQuerier adalah bagian *sql.DB dan *sql.Tx yang dipakai gorm.
+type utcQuerier interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type utcConn struct {
+	q  utcQuerier
+	db *sql.DB
+}
+
+func (c utcConn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return c.q.PrepareContext(ctx, query)
+}
+
+func (c utcConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.q.ExecContext(ctx, query, utcArgs(args)...)
+}
+
+func (c utcConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return c.q.QueryContext(ctx, query, utcArgs(args)...)
+}
+
+func (c utcConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return c.q.QueryRowContext(ctx, query, utcArgs(args)...)
+}
+
+// GetDBConn membuat gorm.DB.DB() tetap mengembalikan *sql.DB asli.
+func (c utcConn) GetDBConn() (*sql.DB, error) {
+	return c.db, nil
+}
+
+// utcPool membungkus *sql.DB; transaksinya ikut dibungkus.
+type utcPool struct {
+	*sql.DB
+}
+
+func (p utcPool) conn() utcConn {
+	return utcConn{q: p.DB, db: p.DB}
+}
+
+func (p utcPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return p.conn().ExecContext(ctx, query, args...)
+}
+
+func (p utcPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return p.conn().QueryContext(ctx, query, args...)
+}
+
+func (p utcPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return p.conn().QueryRowContext(ctx, query, args...)
+}
+
+func (p utcPool) GetDBConn() (*sql.DB, error) {
+	return p.DB, nil
+}
+
+func (p utcPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	tx, err := p.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &utcTx{utcConn{q: tx, db: p.DB}, tx}, nil
+}
+
+// utcTx harus pointer karena gorm memeriksa committer dengan IsNil.
+type utcTx struct {
+	utcConn
+	tx *sql.Tx
+}
+
+func (t *utcTx) Commit() error   { return t.tx.Commit() }
+func (t *utcTx) Rollback() error { return t.tx.Rollback() }
+
+// ErrBackupUnsupported dikembalikan Backup untuk driver selain sqlite.
+var ErrBackupUnsupported = errors.New("backup hanya didukung untuk sqlite; pakai pg_dump atau mysqldump")
+
+// Backup menyalin database sqlite yang sedang dipakai ke path memakai
+// VACUUM INTO, sehingga aman dijalankan saat server hidup. File tujuan
+// tidak boleh sudah ada.
+func Backup(db *gorm.DB, path string) error {
+	if db.Dialector.Name() != DriverSQLite {
+		return ErrBackupUnsupported
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("file %s sudah ada", path)
+	}
+	return db.Exec("VACUUM INTO ?", path).Error
+}
@@ -0,0 +1,193 @@
+// Package store berisi model database dan repository entitas utama.
+package store
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound dikembalikan bila entitas tidak ditemukan. Nilainya sama
+// dengan gorm.ErrRecordNotFound sehingga errors.Is bekerja untuk keduanya.
+var ErrNotFound = gorm.ErrRecordNotFound
+
+// Store adalah akses data yang dipakai jalur utama (login, keputusan akses,
+// alarm, kontrol perangkat). Handler dan service bergantung pada interface
+// ini sehingga bisa diganti fake di test. Query laporan dan analitik yang
+// berat tetap memakai *gorm.DB langsung.
+type Store interface {
+	UserByUsername(username string) (User, error)
+	UserByID(id uint) (User, error)
+	// UserByTelegramChat hanya mengembalikan user aktif.
+	UserByTelegramChat(chatID int64) (User, error)
+	CreateUser(u *User) error
+	// CountActiveAdmins dipakai untuk mengetahui apakah setup awal sudah
+	// selesai.
+	CountActiveAdmins() (int64, error)
+	SaveUser(u *User) error
+	DeleteUser(u *User) error
+
+	DoorlockUser(accessID string) (DoorlockUser, error)
+	CreateDoorlockUser(u *DoorlockUser) error
+	SaveDoorlockUser(u *DoorlockUser) error
+	DeleteDoorlockUser(u *DoorlockUser) error
+	// LockDoorlockUser mengisi locked_until; false bila access_id tidak ada.
+	LockDoorlockUser(accessID string, until time.Time) (bool, error)
+
+	Door(doorID string) (Door, error)
+	Doors() ([]Door, error)
+	SaveDoor(d *Door) error
+
+	CreateAttendance(a *Attendance) error
+	// LastSuccessfulAccess mengembalikan akses sukses terakhir access_id
+	// sejak since, di pintu doorID atau di semua pintu zone bila zone diisi.
+	LastSuccessfulAccess(accessID, doorID, zone string, since time.Time) (Attendance, error)
+
+	Alarm(id uint) (Alarm, error)
+	CreateAlarm(al *Alarm) error
+	// UpdateAlarm menyimpan al hanya bila status di database masih status;
+	// false bila alarm sudah diubah oleh proses lain.
+	UpdateAlarm(al *Alarm, status string) (bool, error)
+	// UnresolvedAlarms mengembalikan alarm open/acknowledged terbaru.
+	UnresolvedAlarms(limit int) ([]Alarm, error)
+	CountUnresolvedAlarms() (int64, error)
+	// EscalationDue mengembalikan alarm open yang belum dieskalasi dan dibuat
+	// sebelum cutoff.
+	EscalationDue(cutoff time.Time) ([]Alarm, error)
+	MarkEscalated(id uint, at time.Time) error
+
+	CreateDoorOpenLog(l *DoorOpenLog) error
+	CreateCommandLog(l *CommandLog) error
+}
+
+// Gorm adalah implementasi Store di atas gorm.
+type Gorm struct {
+	DB *gorm.DB
+}
+
+func NewGorm(db *gorm.DB) *Gorm {
+	return &Gorm{DB: db}
+}
+
+var _ Store = (*Gorm)(nil)
+
+func (s *Gorm) UserByUsername(username string) (User, error) {
+	var u User
+	err := s.DB.Where("username = ?", username).First(&u).Error
+	return u, err
+}
+
+func (s *Gorm) UserByID(id uint) (User, error) {
+	var u User
+	err := s.DB.First(&u, id).Error
+	return u, err
+}
+
+func (s *Gorm) UserByTelegramChat(chatID int64) (User, error) {
+	var u User
+	if chatID == 0 {
+		return u, ErrNotFound
+	}
+	err := s.DB.Where("telegram_chat_id = ? AND is_active = ?", chatID, true).First(&u).Error
+	return u, err
+}
+
+func (s *Gorm) CountActiveAdmins() (int64, error) {
+	var n int64
+	err := s.DB.Model(&User{}).Where("role = ? AND is_active = ?", RoleAdmin, true).Count(&n).Error
+	return n, err
+}
+
+func (s *Gorm) CreateUser(u *User) error { return s.DB.Create(u).Error }
+func (s *Gorm) SaveUser(u *User) error   { return s.DB.Save(u).Error }
+func (s *Gorm) DeleteUser(u *User) error { return s.DB.Delete(u).Error }
+
+func (s *Gorm) DoorlockUser(accessID string) (DoorlockUser, error) {
+	var u DoorlockUser
+	err := s.DB.Where("access_id = ?", accessID).First(&u).Error
+	return u, err
+}
+
+func (s *Gorm) CreateDoorlockUser(u *DoorlockUser) error { return s.DB.Create(u).Error }
+func (s *Gorm) SaveDoorlockUser(u *DoorlockUser) error   { return s.DB.Save(u).Error }
+func (s *Gorm) DeleteDoorlockUser(u *DoorlockUser) error { return s.DB.Delete(u).Error }
+
+func (s *Gorm) LockDoorlockUser(accessID string, until time.Time) (bool, error) {
+	res := s.DB.Model(&DoorlockUser{}).Where("access_id = ?", accessID).Update("locked_until", until)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (s *Gorm) Door(doorID string) (Door, error) {
+	var d Door
+	err := s.DB.Where("door_id = ?", doorID).First(&d).Error
+	return d, err
+}
+
+func (s *Gorm) Doors() ([]Door, error) {
+	var list []Door
+	err := s.DB.Order("door_id").Find(&list).Error
+	return list, err
+}
+
+func (s *Gorm) SaveDoor(d *Door) error { return s.DB.Save(d).Error }
+
+func (s *Gorm) CreateAttendance(a *Attendance) error { return s.DB.Create(a).Error }
+
+func (s *Gorm) LastSuccessfulAccess(accessID, doorID, zone string, since time.Time) (Attendance, error) {
+	var prev Attendance
+	q := s.DB.Where("access_id = ? AND status = ? AND created_at >= ?", accessID, "success", since)
+	if zone != "" {
+		q = q.Where("door_id IN (?)", s.DB.Model(&Door{}).Select("door_id").Where("zone = ?", zone))
+	} else {
+		q = q.Where("door_id = ?", doorID)
+	}
+	err := q.Order("created_at desc").First(&prev).Error
+	return prev, err
+}
+
+func (s *Gorm) Alarm(id uint) (Alarm, error) {
+	var al Alarm
+	err := s.DB.First(&al, id).Error
+	return al, err
+}
+
+func (s *Gorm) CreateAlarm(al *Alarm) error { return s.DB.Create(al).Error }
+
+func (s *Gorm) UpdateAlarm(al *Alarm, status string) (bool, error) {
+	res := s.DB.Model(&Alarm{}).Where("id = ? AND status = ?", al.ID, status).
+		Select("*").Omit("id", "created_at").Updates(al)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (s *Gorm) UnresolvedAlarms(limit int) ([]Alarm, error) {
+	var list []Alarm
+	err := s.DB.Where("status <> ?", AlarmStatusResolved).Order("created_at desc").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (s *Gorm) CountUnresolvedAlarms() (int64, error) {
+	var n int64
+	err := s.DB.Model(&Alarm{}).Where("status <> ?", AlarmStatusResolved).Count(&n).Error
+	return n, err
+}
+
+func (s *Gorm) EscalationDue(cutoff time.Time) ([]Alarm, error) {
+	var list []Alarm
+	err := s.DB.Where("status = ? AND escalated_at IS NULL AND created_at <= ?", AlarmStatusOpen, cutoff).
+		Order("created_at").
+		Find(&list).Error
+	return list, err
+}
+
+func (s *Gorm) MarkEscalated(id uint, at time.Time) error {
+	return s.DB.Model(&Alarm{}).Where("id = ?", id).Update("escalated_at", at).Error
+}
+
+func (s *Gorm) CreateDoorOpenLog(l *DoorOpenLog) error { return s.DB.Create(l).Error }
+func (s *Gorm) CreateCommandLog(l *CommandLog) error   { return s.DB.Create(l).Error }
+
+// IsNotFound melaporkan apakah err berarti entitas tidak ditemukan.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
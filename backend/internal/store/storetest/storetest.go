@@ -0,0 +1,77 @@
+// Package storetest berisi helper database untuk test paket lain.
+package storetest
+
+import (
+	"os"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"smart-door-lock/backend/internal/store"
+)
+
+// Open membuka SQLite in-memory per test dan memigrasi models.
+func Open(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	dialector, err := store.Dialector(store.DriverSQLite, "file:"+t.Name()+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// DSNEnv adalah environment variable berisi DSN database test per driver.
+// Test kompatibilitas di-skip untuk driver yang DSN-nya tidak di-set.
+var DSNEnv = map[string]string{
+	store.DriverPostgres: "TEST_POSTGRES_DSN",
+	store.DriverMySQL:    "TEST_MYSQL_DSN",
+}
+
+// Drivers adalah semua driver yang diuji test kompatibilitas.
+var Drivers = []string{store.DriverSQLite, store.DriverPostgres, store.DriverMySQL}
+
+// OpenDriver membuka database kosong untuk driver: SQLite in-memory, atau
+// database dari DSNEnv untuk postgres/mysql. Semua tabel dihapus sebelum
+// dan sesudah test karena database tersebut dipakai bergantian.
+func OpenDriver(t *testing.T, driver string) *gorm.DB {
+	t.Helper()
+	if driver == store.DriverSQLite {
+		return Open(t)
+	}
+	dsn := os.Getenv(DSNEnv[driver])
+	if dsn == "" {
+		t.Skipf("%s tidak di-set", DSNEnv[driver])
+	}
+	dialector, err := store.Dialector(driver, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dropAll(t, db)
+	t.Cleanup(func() {
+		dropAll(t, db)
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func dropAll(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	tables := append([]interface{}{"schema_migrations"}, store.Models()...)
+	if err := db.Migrator().DropTable(tables...); err != nil {
+		t.Fatal(err)
+	}
+}
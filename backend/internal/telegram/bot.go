@@ -0,0 +1,241 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"smart-door-lock/backend/internal/access"
+	"smart-door-lock/backend/internal/alarms"
+	"smart-door-lock/backend/internal/audit"
+	"smart-door-lock/backend/internal/devices"
+	"smart-door-lock/backend/internal/store"
+)
+
+// Perintah /unlock harus dikonfirmasi dengan /confirm dalam waktu ini
+const unlockConfirmWindow = 60 * time.Second
+
+type pendingUnlock struct {
+	DoorID    string
+	ExpiresAt time.Time
+}
+
+// Deps adalah service yang dipakai perintah bot.
+type Deps struct {
+	Store     store.Store
+	Devices   *devices.Registry
+	Doors     *devices.Tracker
+	Control   *devices.Controller
+	Alarms    *alarms.Service
+	Occupancy *access.Occupancy
+	Audit     *audit.Logger
+	// Location adalah zona waktu situs untuk jam di balasan bot.
+	Location *time.Location
+}
+
+// Bot menjalankan perintah dari chat Telegram.
+type Bot struct {
+	Deps
+	client *Client
+
+	mu      sync.Mutex
+	pending map[int64]pendingUnlock
+}
+
+func NewBot(client *Client, deps Deps) *Bot {
+	return &Bot{Deps: deps, client: client, pending: make(map[int64]pendingUnlock)}
+}
+
+const botHelp = `Perintah yang tersedia:
+/status - status perangkat dan alarm
+/lock <door_id> - kunci pintu
+/unlock <door_id> - buka pintu (perlu /confirm)
+/alarms - alarm yang belum selesai
+/ack <id> [catatan] - acknowledge alarm
+/who - siapa yang sedang di dalam`
+
+// Run membaca update dari Telegram dan menjalankan perintah dari chat yang
+// terhubung ke User aktif.
+func (b *Bot) Run() {
+	if b.client == nil || b.client.api == nil {
+		return
+	}
+
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 60
+	updates := b.client.api.GetUpdatesChan(u)
+	log.Println("✅ Telegram Bot mendengarkan perintah")
+
+	for update := range updates {
+		if update.Message == nil || !update.Message.IsCommand() {
+			continue
+		}
+		msg := update.Message
+		reply := b.Handle(msg.Chat.ID, msg.Command(), msg.CommandArguments())
+		if err := b.client.SendMessage(msg.Chat.ID, reply); err != nil {
+			log.Printf("Gagal membalas perintah Telegram: %v", err)
+		}
+	}
+}
+
+// Handle menjalankan satu perintah bot dan mengembalikan balasan. Hanya
+// chat yang terhubung ke User aktif yang diizinkan; semua perintah dicatat
+// di CommandLog.
+func (b *Bot) Handle(chatID int64, command, args string) string {
+	user, err := b.Store.UserByTelegramChat(chatID)
+	if err != nil {
+		b.Control.LogCommand(devices.CommandSourceTelegram, fmt.Sprintf("chat:%d", chatID), "/"+command, args, "denied")
+		log.Printf("⛔ Perintah Telegram /%s dari chat %d ditolak", command, chatID)
+		return fmt.Sprintf("⛔ Chat ini (%d) tidak terdaftar. Hubungi admin untuk menghubungkan akun Anda.", chatID)
+	}
+
+	args = strings.TrimSpace(args)
+	reply, result := b.run(chatID, user, command, args)
+	b.Control.LogCommand(devices.CommandSourceTelegram, user.Username, "/"+command, args, result)
+	return reply
+}
+
+func (b *Bot) run(chatID int64, user store.User, command, args string) (reply string, result string) {
+	switch command {
+	case "start", "help":
+		return botHelp, "success"
+
+	case "status":
+		return b.status(), "success"
+
+	case "lock":
+		if args == "" {
+			return "Gunakan: /lock <door_id>", "invalid"
+		}
+		err := b.Control.ControlDoor(args, "lock", user.Username, devices.CommandSourceTelegram)
+		b.Audit.Origin(user.Username, audit.DoorControl, args, fmt.Sprintf("telegram:%d", chatID), nil,
+			map[string]any{"command": "lock", "published": err == nil})
+		if err != nil {
+			return fmt.Sprintf("❌ Perintah kunci pintu %s GAGAL dikirim: %v", args, err), "failed"
+		}
+		return fmt.Sprintf("🔒 Pintu %s dikunci", args), "success"
+
+	case "unlock":
+		if args == "" {
+			return "Gunakan: /unlock <door_id>", "invalid"
+		}
+		b.mu.Lock()
+		b.pending[chatID] = pendingUnlock{DoorID: args, ExpiresAt: time.Now().Add(unlockConfirmWindow)}
+		b.mu.Unlock()
+		return fmt.Sprintf("⚠️ Buka pintu %s? Ketik /confirm dalam %d detik atau /cancel.",
+			args, int(unlockConfirmWindow.Seconds())), "pending confirmation"
+
+	case "confirm":
+		b.mu.Lock()
+		p, ok := b.pending[chatID]
+		delete(b.pending, chatID)
+		b.mu.Unlock()
+		if !ok || time.Now().After(p.ExpiresAt) {
+			return "Tidak ada perintah yang menunggu konfirmasi.", "invalid"
+		}
+		err := b.Control.ControlDoor(p.DoorID, "unlock", user.Username, devices.CommandSourceTelegram)
+		b.Audit.Origin(user.Username, audit.DoorControl, p.DoorID, fmt.Sprintf("telegram:%d", chatID), nil,
+			map[string]any{"command": "unlock", "published": err == nil})
+		if err != nil {
+			return fmt.Sprintf("❌ Perintah buka pintu %s GAGAL dikirim: %v", p.DoorID, err), "failed"
+		}
+		return fmt.Sprintf("🔓 Pintu %s dibuka", p.DoorID), "success"
+
+	case "cancel":
+		b.mu.Lock()
+		delete(b.pending, chatID)
+		b.mu.Unlock()
+		return "Dibatalkan.", "success"
+
+	case "alarms":
+		return b.alarms(), "success"
+
+	case "ack":
+		fields := strings.Fields(args)
+		if len(fields) == 0 {
+			return "Gunakan: /ack <id> [catatan]", "invalid"
+		}
+		id, err := strconv.ParseUint(strings.TrimPrefix(fields[0], "#"), 10, 64)
+		if err != nil {
+			return "ID alarm tidak valid.", "invalid"
+		}
+		note := strings.TrimSpace(strings.TrimPrefix(args, fields[0]))
+		before, _ := b.Store.Alarm(uint(id))
+		al, err := b.Alarms.Acknowledge(uint(id), user.Username, note)
+		if err != nil {
+			switch {
+			case errors.Is(err, alarms.ErrNotFound):
+				return fmt.Sprintf("Alarm #%d tidak ditemukan.", id), "not found"
+			case errors.Is(err, alarms.ErrResolved):
+				return fmt.Sprintf("Alarm #%d sudah selesai.", id), "already resolved"
+			}
+			return "Gagal acknowledge alarm.", "error: " + err.Error()
+		}
+		b.Audit.Origin(user.Username, audit.AlarmPrefix+"ack", strconv.FormatUint(id, 10),
+			fmt.Sprintf("telegram:%d", chatID), before, al)
+		return fmt.Sprintf("✅ Alarm #%d di-acknowledge oleh %s", id, user.Username), "success"
+
+	case "who":
+		return b.who(), "success"
+	}
+
+	return "Perintah tidak dikenal.\n\n" + botHelp, "unknown command"
+}
+
+func (b *Bot) status() string {
+	dev := b.Devices.Snapshot()
+
+	activeAlarms, _ := b.Store.CountUnresolvedAlarms()
+
+	var sb strings.Builder
+	sb.WriteString("📊 STATUS SISTEM\n\n")
+	fmt.Fprintf(&sb, "Pintu: %v\nReader: %v\nPinpad: %v\nBuzzer: %v\n", dev["door"], dev["reader"], dev["pinpad"], dev["buzzer"])
+	fmt.Fprintf(&sb, "Alarm aktif: %d\n", activeAlarms)
+
+	for id, openedAt := range b.Doors.OpenSince() {
+		fmt.Fprintf(&sb, "🚪 %s terbuka sejak %s\n", id, time.Since(openedAt).Round(time.Second))
+	}
+	return sb.String()
+}
+
+func (b *Bot) alarms() string {
+	list, _ := b.Store.UnresolvedAlarms(10)
+	if len(list) == 0 {
+		return "✅ Tidak ada alarm aktif."
+	}
+
+	loc := b.Location
+	var sb strings.Builder
+	sb.WriteString("🚨 ALARM AKTIF\n")
+	for _, al := range list {
+		fmt.Fprintf(&sb, "\n#%d [%s] %s\n%s - %s %s (%s)\n",
+			al.ID, al.Severity, al.Reason, al.Username, al.DoorID,
+			al.CreatedAt.In(loc).Format("2 Jan 15:04 MST"), al.Status)
+	}
+	return sb.String()
+}
+
+func (b *Bot) who() string {
+	occ, err := b.Occupancy.Current(time.Now(), "")
+	if err != nil {
+		return "Gagal memuat data occupancy."
+	}
+	if occ.Total == 0 {
+		return "Tidak ada orang di dalam."
+	}
+
+	loc := b.Location
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "👥 Di dalam: %d orang\n\n", occ.Total)
+	for _, p := range occ.People {
+		fmt.Fprintf(&sb, "• %s (%s) %s sejak %s\n", p.Username, p.AccessID, p.DoorID,
+			p.EnteredAt.In(loc).Format("15:04"))
+	}
+	return sb.String()
+}
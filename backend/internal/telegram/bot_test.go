@@ -0,0 +1,136 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/access"
+	"smart-door-lock/backend/internal/alarms"
+	"smart-door-lock/backend/internal/audit"
+	"smart-door-lock/backend/internal/devices"
+	"smart-door-lock/backend/internal/mqttbus"
+	"smart-door-lock/backend/internal/mqttbus/mqtttest"
+	"smart-door-lock/backend/internal/notify"
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+const operatorChat = 1001
+
+func newTestBot(t *testing.T) (*Bot, *gorm.DB, *mqtttest.Client) {
+	t.Helper()
+	db := storetest.Open(t, store.Models()...)
+	db.Create(&store.User{Username: "satpam", Role: "user", IsActive: true, TelegramChatID: operatorChat})
+
+	fake := mqtttest.New()
+	bus := mqttbus.New(fake)
+	st := store.NewGorm(db)
+	dispatcher := notify.NewDispatcher(db, 0, 0)
+	alarmSvc := alarms.NewService(st, dispatcher, nil, 10*time.Minute, time.UTC)
+	registry := devices.NewRegistry()
+	doors := devices.NewTracker(st, alarmSvc, dispatcher, time.UTC)
+
+	bot := NewBot(&Client{}, Deps{
+		Store:     st,
+		Devices:   registry,
+		Doors:     doors,
+		Control:   devices.NewController(st, bus),
+		Alarms:    alarmSvc,
+		Occupancy: access.NewOccupancy(db, st, time.UTC),
+		Audit:     audit.New(db),
+		Location:  time.UTC,
+	})
+	return bot, db, fake
+}
+
+func TestHandleRejectsUnknownChat(t *testing.T) {
+	bot, db, fake := newTestBot(t)
+
+	reply := bot.Handle(999, "lock", "D01")
+	if !strings.Contains(reply, "tidak terdaftar") {
+		t.Errorf("reply = %q", reply)
+	}
+	if msgs := fake.Published(); len(msgs) != 0 {
+		t.Errorf("published %+v for unknown chat", msgs)
+	}
+
+	var logs []store.CommandLog
+	db.Find(&logs)
+	if len(logs) != 1 || logs[0].Result != "denied" || logs[0].Source != devices.CommandSourceTelegram {
+		t.Errorf("command logs = %+v", logs)
+	}
+}
+
+func TestLockReportsFailureWhenBrokerDisconnected(t *testing.T) {
+	bot, db, fake := newTestBot(t)
+	fake.SetConnected(false)
+
+	reply := bot.Handle(operatorChat, "lock", "D01")
+	if !strings.Contains(reply, "GAGAL") || strings.Contains(reply, "dikunci") {
+		t.Errorf("reply = %q", reply)
+	}
+	var log store.CommandLog
+	db.Where("command = ?", "/lock").First(&log)
+	if log.Result != "failed" {
+		t.Errorf("command log result = %q, want failed", log.Result)
+	}
+}
+
+func TestUnlockRequiresConfirmation(t *testing.T) {
+	bot, db, fake := newTestBot(t)
+
+	bot.Handle(operatorChat, "unlock", "D01")
+	if msgs := fake.Published(); len(msgs) != 0 {
+		t.Fatalf("published %+v before /confirm", msgs)
+	}
+
+	reply := bot.Handle(operatorChat, "confirm", "")
+	if !strings.Contains(reply, "dibuka") {
+		t.Fatalf("confirm reply = %q", reply)
+	}
+	msgs := fake.Published()
+	if len(msgs) != 1 || msgs[0].Topic != "doorlock/D01/control" || !strings.Contains(msgs[0].Payload, `"command": "unlock"`) {
+		t.Fatalf("published %+v", msgs)
+	}
+
+	// konfirmasi hanya berlaku sekali
+	if reply := bot.Handle(operatorChat, "confirm", ""); !strings.Contains(reply, "Tidak ada perintah") {
+		t.Errorf("second confirm = %q", reply)
+	}
+
+	var ev store.AuditEvent
+	db.Where("action = ?", audit.DoorControl).First(&ev)
+	if ev.Actor != "satpam" || ev.Target != "D01" {
+		t.Errorf("audit event = %+v", ev)
+	}
+}
+
+func TestAckAlarm(t *testing.T) {
+	bot, db, _ := newTestBot(t)
+	db.Create(&store.Alarm{AlarmType: store.AlarmTypeFailedAttempts, Status: store.AlarmStatusOpen, Reason: "3 kali gagal masuk"})
+
+	if reply := bot.Handle(operatorChat, "alarms", ""); !strings.Contains(reply, "#1") {
+		t.Errorf("alarms reply = %q", reply)
+	}
+	if reply := bot.Handle(operatorChat, "ack", "#1 sudah dicek"); !strings.Contains(reply, "di-acknowledge oleh satpam") {
+		t.Fatalf("ack reply = %q", reply)
+	}
+
+	var al store.Alarm
+	db.First(&al, 1)
+	if al.Status != store.AlarmStatusAcknowledged || !strings.Contains(al.Notes, "sudah dicek") {
+		t.Errorf("alarm = %+v", al)
+	}
+	var ev store.AuditEvent
+	db.Where("action = ?", audit.AlarmPrefix+"ack").First(&ev)
+	if ev.Actor != "satpam" || ev.Target != "1" || ev.IP != "telegram:1001" ||
+		!strings.Contains(ev.Before, `"status":"open"`) || !strings.Contains(ev.After, `"status":"acknowledged"`) {
+		t.Errorf("ack audit = %+v", ev)
+	}
+	if reply := bot.Handle(operatorChat, "ack", "42"); !strings.Contains(reply, "tidak ditemukan") {
+		t.Errorf("unknown alarm reply = %q", reply)
+	}
+}
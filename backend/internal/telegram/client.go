@@ -0,0 +1,42 @@
+// Package telegram mengirim notifikasi lewat Telegram dan menjalankan
+// perintah bot dari chat yang terdaftar.
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Client membungkus BotAPI. Client dengan api nil (token salah atau tidak
+// ada koneksi) tetap bisa dipakai; SendMessage mengembalikan error.
+type Client struct {
+	api *tgbotapi.BotAPI
+}
+
+// Connect membuat Client dari token bot.
+func Connect(token string) *Client {
+	api, err := tgbotapi.NewBotAPI(token)
+	if err != nil {
+		log.Printf("PERINGATAN: Gagal menginisialisasi Telegram Bot: %v. Notifikasi nonaktif.", err)
+		return &Client{}
+	}
+	log.Println("✅ Telegram Bot terhubung:", api.Self.UserName)
+	return &Client{api: api}
+}
+
+func (c *Client) SendMessage(chatID int64, message string) error {
+	if c == nil || c.api == nil {
+		return errors.New("Telegram bot tidak terinisialisasi")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, message)
+	if _, err := c.api.Send(msg); err != nil {
+		return fmt.Errorf("gagal mengirim pesan: %w", err)
+	}
+
+	log.Println("Notifikasi Telegram terkirim.")
+	return nil
+}
@@ -0,0 +1,135 @@
+package users
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"smart-door-lock/backend/internal/store"
+)
+
+// Kolom CSV user doorlock. Kolom pin hanya ditulis saat export bila
+// diminta; saat import pin boleh kosong untuk user yang di-update.
+// expires_at berupa RFC3339 atau tanggal YYYY-MM-DD (berlaku sampai akhir
+// hari itu di zona waktu situs).
+var doorlockColumns = []string{"name", "access_id", "door_id", "pin", "is_active", "expires_at"}
+
+func parseExpiry(v string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", v, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return day.AddDate(0, 0, 1), nil
+}
+
+// ReadDoorlockCSV membaca CSV dengan header. Urutan kolom bebas; name,
+// access_id dan door_id wajib ada. Nilai is_active atau expires_at yang
+// tidak valid tidak menolak seluruh file, tetapi menjadi error baris itu
+// di laporan import. Tanggal expires_at dibaca di zona waktu situs loc.
+func ReadDoorlockCSV(r io.Reader, loc *time.Location) ([]DoorlockInput, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: CSV kosong", ErrInvalid)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	col := make(map[string]int, len(header))
+	for i, h := range header {
+		// BOM dari Excel ikut terbaca di kolom pertama
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		known := false
+		for _, c := range doorlockColumns {
+			known = known || c == h
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: kolom %q tidak dikenal", ErrInvalid, h)
+		}
+		col[h] = i
+	}
+	for _, required := range []string{"name", "access_id", "door_id"} {
+		if _, ok := col[required]; !ok {
+			return nil, fmt.Errorf("%w: kolom %s wajib ada", ErrInvalid, required)
+		}
+	}
+
+	field := func(rec []string, name string) string {
+		if i, ok := col[name]; ok && i < len(rec) {
+			return rec[i]
+		}
+		return ""
+	}
+
+	var rows []DoorlockInput
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		in := DoorlockInput{
+			Name:     field(rec, "name"),
+			AccessID: field(rec, "access_id"),
+			DoorID:   field(rec, "door_id"),
+			Pin:      field(rec, "pin"),
+		}
+		if v := strings.TrimSpace(field(rec, "is_active")); v != "" {
+			if active, err := strconv.ParseBool(v); err == nil {
+				in.IsActive = &active
+			} else {
+				in.parseErrors = append(in.parseErrors, fmt.Sprintf("is_active %q bukan true/false", v))
+			}
+		}
+		if v := strings.TrimSpace(field(rec, "expires_at")); v != "" {
+			if at, err := parseExpiry(v, loc); err == nil {
+				in.ExpiresAt = &at
+			} else {
+				in.parseErrors = append(in.parseErrors, fmt.Sprintf("expires_at %q bukan RFC3339 atau YYYY-MM-DD", v))
+			}
+		}
+		rows = append(rows, in)
+	}
+}
+
+// WriteDoorlockCSV menulis user doorlock dengan header yang bisa dibaca
+// ulang ReadDoorlockCSV. PIN hanya ikut bila withPins.
+func WriteDoorlockCSV(w io.Writer, list []store.DoorlockUser, withPins bool) error {
+	cw := csv.NewWriter(w)
+	var header []string
+	for _, c := range doorlockColumns {
+		if c != "pin" || withPins {
+			header = append(header, c)
+		}
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, u := range list {
+		expires := ""
+		if u.ExpiresAt != nil {
+			expires = u.ExpiresAt.Format(time.RFC3339)
+		}
+		rec := []string{u.Name, u.AccessID, u.DoorID}
+		if withPins {
+			rec = append(rec, u.Pin)
+		}
+		rec = append(rec, strconv.FormatBool(u.IsActive), expires)
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
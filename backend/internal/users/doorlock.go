@@ -0,0 +1,327 @@
+package users
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/store"
+)
+
+var (
+	ErrAccessIDTaken = errors.New("access_id already exists")
+	ErrImportInvalid = errors.New("import rejected")
+)
+
+// Panjang PIN keypad doorlock
+const (
+	MinPinLength = 4
+	MaxPinLength = 6
+)
+
+// DoorlockInput adalah data satu user doorlock dari API, CSV atau JSON.
+// IsActive nil berarti aktif untuk user baru dan tidak diubah untuk user
+// yang sudah ada.
+type DoorlockInput struct {
+	Name     string `json:"name"`
+	AccessID string `json:"access_id"`
+	DoorID   string `json:"door_id"`
+	Pin      string `json:"pin"`
+	IsActive *bool  `json:"is_active,omitempty"`
+	// ExpiresAt nil berarti tidak kedaluwarsa (user baru) atau tidak diubah
+	// (user yang sudah ada).
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// parseErrors berisi nilai CSV yang tidak bisa dibaca (is_active,
+	// expires_at) supaya dilaporkan per baris oleh import.
+	parseErrors []string
+}
+
+func (in *DoorlockInput) normalize() {
+	in.Name = strings.TrimSpace(in.Name)
+	in.AccessID = strings.TrimSpace(in.AccessID)
+	in.DoorID = strings.TrimSpace(in.DoorID)
+	in.Pin = strings.TrimSpace(in.Pin)
+}
+
+// problems mengembalikan semua kesalahan isian; dipakai bersama oleh
+// CreateDoorlockUser dan import. Pin boleh kosong bila pinOptional (update
+// user yang sudah ada). doors adalah pintu yang dikenal (lihat knownDoors).
+func (in DoorlockInput) problems(pinOptional bool, doors map[string]bool) []string {
+	out := append([]string(nil), in.parseErrors...)
+	if in.Name == "" {
+		out = append(out, "name wajib diisi")
+	}
+	switch {
+	case in.AccessID == "":
+		out = append(out, "access_id wajib diisi")
+	case len(in.AccessID) > 191 || strings.ContainsAny(in.AccessID, " \t\r\n"):
+		out = append(out, "access_id maksimal 191 karakter tanpa spasi")
+	}
+	if msg := doorProblem(in.DoorID, doors); msg != "" {
+		out = append(out, msg)
+	}
+	if in.ExpiresAt != nil && !in.ExpiresAt.After(time.Now()) {
+		out = append(out, "expires_at harus di masa depan")
+	}
+	if in.Pin == "" && pinOptional {
+		return out
+	}
+	if msg := pinProblem(in.Pin); msg != "" {
+		out = append(out, msg)
+	} else if weakPin(in.Pin) {
+		out = append(out, "pin terlalu mudah ditebak (digit berulang atau berurutan)")
+	}
+	return out
+}
+
+// doorProblem memeriksa door_id terhadap pintu yang dikenal; dipakai juga
+// oleh UpdateDoorlockUser.
+func doorProblem(doorID string, doors map[string]bool) string {
+	switch {
+	case doorID == "":
+		return "door_id wajib diisi"
+	case !doors[doorID]:
+		return fmt.Sprintf("door_id %s tidak dikenal; daftarkan dulu lewat PUT /api/doors/%s", doorID, doorID)
+	}
+	return ""
+}
+
+func pinProblem(pin string) string {
+	if len(pin) < MinPinLength || len(pin) > MaxPinLength || strings.Trim(pin, "0123456789") != "" {
+		return fmt.Sprintf("pin harus %d-%d digit angka", MinPinLength, MaxPinLength)
+	}
+	return ""
+}
+
+func (in DoorlockInput) apply(u *store.DoorlockUser) {
+	u.Name = in.Name
+	u.AccessID = in.AccessID
+	u.DoorID = in.DoorID
+	if in.Pin != "" {
+		u.Pin = in.Pin
+	}
+	if in.IsActive != nil {
+		u.IsActive = *in.IsActive
+	}
+	if in.ExpiresAt != nil {
+		u.ExpiresAt = in.ExpiresAt
+	}
+}
+
+// DoorlockUsers mengembalikan semua user doorlock urut id.
+func (s *Service) DoorlockUsers() ([]store.DoorlockUser, error) {
+	var list []store.DoorlockUser
+	err := s.db.Order("id").Find(&list).Error
+	return list, err
+}
+
+// CreateDoorlockUser memvalidasi lalu menyimpan satu user doorlock.
+func (s *Service) CreateDoorlockUser(in DoorlockInput) (store.DoorlockUser, error) {
+	in.normalize()
+	var existing []store.DoorlockUser
+	if err := s.db.Unscoped().Select("door_id").Find(&existing).Error; err != nil {
+		return store.DoorlockUser{}, err
+	}
+	doors, err := s.knownDoors(existing)
+	if err != nil {
+		return store.DoorlockUser{}, err
+	}
+	if p := in.problems(false, doors); len(p) > 0 {
+		return store.DoorlockUser{}, fmt.Errorf("%w: %s", ErrInvalid, strings.Join(p, "; "))
+	}
+	// access_id user yang sudah dihapus tetap tercatat di attendance
+	var taken int64
+	if err := s.db.Unscoped().Model(&store.DoorlockUser{}).Where("access_id = ?", in.AccessID).Count(&taken).Error; err != nil {
+		return store.DoorlockUser{}, err
+	}
+	if taken > 0 {
+		return store.DoorlockUser{}, ErrAccessIDTaken
+	}
+
+	u := store.DoorlockUser{IsActive: true, CreatedAt: time.Now()}
+	in.apply(&u)
+	return u, s.store.CreateDoorlockUser(&u)
+}
+
+// DeleteDoorlockUser menghapus user doorlock berdasarkan access_id
+// (soft-delete). Akses dengan access_id itu langsung ditolak.
+func (s *Service) DeleteDoorlockUser(accessID string) (store.DoorlockUser, error) {
+	u, err := s.store.DoorlockUser(accessID)
+	if err != nil {
+		return u, notFound(err)
+	}
+	return u, s.store.DeleteDoorlockUser(&u)
+}
+
+// ====== IMPORT ======
+
+// Aksi per baris di ImportReport
+const (
+	ImportCreate = "create"
+	ImportUpdate = "update"
+	ImportError  = "error"
+)
+
+// ImportOptions mengatur ImportDoorlockUsers.
+//   - Update: baris dengan access_id yang sudah ada menimpa data lama;
+//     tanpa Update baris itu dianggap error.
+//   - DryRun: hanya validasi, tidak ada yang disimpan.
+//   - Partial: baris valid tetap disimpan walaupun ada baris error. Default
+//     all-or-nothing: satu baris error membatalkan semuanya.
+type ImportOptions struct {
+	Update  bool
+	DryRun  bool
+	Partial bool
+}
+
+// ImportRow adalah hasil satu baris; Row dimulai dari 1 (baris data
+// pertama, tanpa header).
+type ImportRow struct {
+	Row      int      `json:"row"`
+	AccessID string   `json:"access_id"`
+	Action   string   `json:"action"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// ImportReport merangkum import. Applied false berarti tidak ada baris
+// yang disimpan; Created dan Updated pada dry-run adalah pratinjau.
+type ImportReport struct {
+	DryRun  bool        `json:"dry_run"`
+	Partial bool        `json:"partial"`
+	Applied bool        `json:"applied"`
+	Total   int         `json:"total"`
+	Created int         `json:"created"`
+	Updated int         `json:"updated"`
+	Failed  int         `json:"failed"`
+	Rows    []ImportRow `json:"rows"`
+}
+
+// weakPin menolak PIN yang mudah ditebak: satu digit berulang (1111) atau
+// deret naik/turun (1234, 654321).
+func weakPin(pin string) bool {
+	if len(pin) < 2 {
+		return false
+	}
+	same, up, down := true, true, true
+	for i := 1; i < len(pin); i++ {
+		d := int(pin[i]) - int(pin[i-1])
+		same = same && d == 0
+		up = up && d == 1
+		down = down && d == -1
+	}
+	return same || up || down
+}
+
+// knownDoors adalah pintu yang sudah dikonfigurasi atau sudah dipakai user
+// doorlock lain.
+func (s *Service) knownDoors(existing []store.DoorlockUser) (map[string]bool, error) {
+	doors, err := s.store.Doors()
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(doors))
+	for _, d := range doors {
+		known[d.DoorID] = true
+	}
+	for _, u := range existing {
+		known[u.DoorID] = true
+	}
+	return known, nil
+}
+
+// ImportDoorlockUsers memvalidasi semua baris (isian wajib, format dan
+// kekuatan PIN, access_id duplikat, pintu tidak dikenal) lalu menyimpannya
+// dalam satu transaksi. Pada mode all-or-nothing satu baris error
+// membatalkan semuanya dan error-nya ErrImportInvalid; laporan per baris
+// selalu diisi.
+func (s *Service) ImportDoorlockUsers(rows []DoorlockInput, opt ImportOptions) (ImportReport, error) {
+	report := ImportReport{DryRun: opt.DryRun, Partial: opt.Partial, Total: len(rows), Rows: make([]ImportRow, len(rows))}
+
+	var existing []store.DoorlockUser
+	if err := s.db.Unscoped().Find(&existing).Error; err != nil {
+		return report, err
+	}
+	byAccessID := make(map[string]store.DoorlockUser, len(existing))
+	deleted := make(map[string]bool)
+	for _, u := range existing {
+		if u.DeletedAt.Valid {
+			deleted[u.AccessID] = true
+			continue
+		}
+		byAccessID[u.AccessID] = u
+	}
+	doors, err := s.knownDoors(existing)
+	if err != nil {
+		return report, err
+	}
+
+	seen := make(map[string]int, len(rows))
+	for i := range rows {
+		rows[i].normalize()
+		in := rows[i]
+		res := ImportRow{Row: i + 1, AccessID: in.AccessID, Action: ImportCreate}
+
+		_, exists := byAccessID[in.AccessID]
+		if exists {
+			res.Action = ImportUpdate
+		}
+		res.Errors = in.problems(exists && opt.Update, doors)
+		if first, dup := seen[in.AccessID]; dup && in.AccessID != "" {
+			res.Errors = append(res.Errors, fmt.Sprintf("access_id duplikat dengan baris %d", first))
+		} else {
+			seen[in.AccessID] = i + 1
+		}
+		if (exists && !opt.Update) || deleted[in.AccessID] {
+			res.Errors = append(res.Errors, ErrAccessIDTaken.Error())
+		}
+
+		switch {
+		case len(res.Errors) > 0:
+			res.Action = ImportError
+			report.Failed++
+		case res.Action == ImportUpdate:
+			report.Updated++
+		default:
+			report.Created++
+		}
+		report.Rows[i] = res
+	}
+
+	if report.Failed > 0 && !opt.Partial {
+		return report, fmt.Errorf("%w: %d dari %d baris tidak valid", ErrImportInvalid, report.Failed, report.Total)
+	}
+	if opt.DryRun || report.Created+report.Updated == 0 {
+		return report, nil
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		st := store.NewGorm(tx)
+		now := time.Now()
+		for i, in := range rows {
+			switch report.Rows[i].Action {
+			case ImportUpdate:
+				u := byAccessID[in.AccessID]
+				in.apply(&u)
+				if err := st.SaveDoorlockUser(&u); err != nil {
+					return fmt.Errorf("access_id %s: %w", in.AccessID, err)
+				}
+			case ImportCreate:
+				u := store.DoorlockUser{IsActive: true, CreatedAt: now}
+				in.apply(&u)
+				if err := st.CreateDoorlockUser(&u); err != nil {
+					return fmt.Errorf("access_id %s: %w", in.AccessID, err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+	report.Applied = true
+	return report, nil
+}
@@ -0,0 +1,233 @@
+package users
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"smart-door-lock/backend/internal/store"
+)
+
+var ErrExpired = errors.New("credential expired; set a new expires_at first")
+
+// OptionalTime membedakan field JSON yang tidak dikirim (Set false) dari
+// null (Set true, Time nil) pada PATCH.
+type OptionalTime struct {
+	Set  bool
+	Time *time.Time
+}
+
+func (o *OptionalTime) UnmarshalJSON(b []byte) error {
+	o.Set = true
+	if string(b) == "null" {
+		o.Time = nil
+		return nil
+	}
+	var t time.Time
+	if err := json.Unmarshal(b, &t); err != nil {
+		return err
+	}
+	o.Time = &t
+	return nil
+}
+
+// DoorlockPatch adalah perubahan sebagian user doorlock; field nil tidak
+// diubah. PIN diganti lewat RotatePin, bukan lewat patch.
+type DoorlockPatch struct {
+	Name      *string      `json:"name"`
+	DoorID    *string      `json:"door_id"`
+	IsActive  *bool        `json:"is_active"`
+	ExpiresAt OptionalTime `json:"expires_at"`
+}
+
+// UpdateDoorlockUser menerapkan patch dan mengembalikan nilai sebelum dan
+// sesudah. is_active false sama dengan suspend tanpa alasan; true sama
+// dengan resume.
+func (s *Service) UpdateDoorlockUser(accessID string, p DoorlockPatch) (store.DoorlockUser, store.DoorlockUser, error) {
+	u, err := s.store.DoorlockUser(accessID)
+	if err != nil {
+		return u, u, notFound(err)
+	}
+	before := u
+	now := time.Now()
+
+	var problems []string
+	if p.Name != nil {
+		if u.Name = strings.TrimSpace(*p.Name); u.Name == "" {
+			problems = append(problems, "name wajib diisi")
+		}
+	}
+	if p.DoorID != nil {
+		// pintu lama tetap boleh; pintu baru harus dikenal seperti saat create
+		if u.DoorID = strings.TrimSpace(*p.DoorID); u.DoorID != before.DoorID {
+			var existing []store.DoorlockUser
+			if err := s.db.Unscoped().Select("door_id").Find(&existing).Error; err != nil {
+				return before, before, err
+			}
+			doors, err := s.knownDoors(existing)
+			if err != nil {
+				return before, before, err
+			}
+			if msg := doorProblem(u.DoorID, doors); msg != "" {
+				problems = append(problems, msg)
+			}
+		}
+	}
+	if p.ExpiresAt.Set {
+		if p.ExpiresAt.Time != nil && !p.ExpiresAt.Time.After(now) {
+			problems = append(problems, "expires_at harus di masa depan")
+		}
+		u.ExpiresAt = p.ExpiresAt.Time
+	}
+	if len(problems) > 0 {
+		return before, before, fmt.Errorf("%w: %s", ErrInvalid, strings.Join(problems, "; "))
+	}
+
+	if p.IsActive != nil && *p.IsActive != u.IsActive {
+		if *p.IsActive {
+			err = resume(&u, now)
+		} else {
+			suspend(&u, "", now)
+		}
+		if err != nil {
+			return before, before, err
+		}
+	}
+	return before, u, s.store.SaveDoorlockUser(&u)
+}
+
+func suspend(u *store.DoorlockUser, reason string, now time.Time) {
+	u.IsActive = false
+	u.SuspendedAt = &now
+	u.SuspendReason = reason
+}
+
+func resume(u *store.DoorlockUser, now time.Time) error {
+	if u.ExpiresAt != nil && !u.ExpiresAt.After(now) {
+		return ErrExpired
+	}
+	u.IsActive = true
+	u.SuspendedAt = nil
+	u.SuspendReason = ""
+	return nil
+}
+
+// Suspend menonaktifkan kredensial sementara dengan alasan yang wajib
+// diisi.
+func (s *Service) Suspend(accessID, reason string) (store.DoorlockUser, store.DoorlockUser, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return store.DoorlockUser{}, store.DoorlockUser{}, fmt.Errorf("%w: reason wajib diisi", ErrInvalid)
+	}
+	u, err := s.store.DoorlockUser(accessID)
+	if err != nil {
+		return u, u, notFound(err)
+	}
+	before := u
+	suspend(&u, reason, time.Now())
+	return before, u, s.store.SaveDoorlockUser(&u)
+}
+
+// Resume mengaktifkan kembali kredensial yang di-suspend. Kredensial yang
+// sudah kedaluwarsa harus diberi expires_at baru dulu.
+func (s *Service) Resume(accessID string) (store.DoorlockUser, store.DoorlockUser, error) {
+	u, err := s.store.DoorlockUser(accessID)
+	if err != nil {
+		return u, u, notFound(err)
+	}
+	before := u
+	if err := resume(&u, time.Now()); err != nil {
+		return before, before, err
+	}
+	return before, u, s.store.SaveDoorlockUser(&u)
+}
+
+// RotatePin mengganti PIN. PIN kosong berarti dibuatkan PIN acak
+// MaxPinLength digit; PIN baru dikembalikan supaya bisa diserahkan ke
+// pemiliknya.
+func (s *Service) RotatePin(accessID, pin string) (store.DoorlockUser, string, error) {
+	u, err := s.store.DoorlockUser(accessID)
+	if err != nil {
+		return u, "", notFound(err)
+	}
+
+	pin = strings.TrimSpace(pin)
+	if pin == "" {
+		if pin, err = randomPin(u.Pin); err != nil {
+			return u, "", err
+		}
+	}
+	switch {
+	case pinProblem(pin) != "":
+		return u, "", fmt.Errorf("%w: %s", ErrInvalid, pinProblem(pin))
+	case weakPin(pin):
+		return u, "", fmt.Errorf("%w: pin terlalu mudah ditebak", ErrInvalid)
+	case pin == u.Pin:
+		return u, "", fmt.Errorf("%w: pin baru sama dengan pin lama", ErrInvalid)
+	}
+
+	now := time.Now()
+	u.Pin = pin
+	u.PinChangedAt = &now
+	return u, pin, s.store.SaveDoorlockUser(&u)
+}
+
+// randomPin membuat PIN acak yang tidak lemah dan berbeda dari current.
+func randomPin(current string) (string, error) {
+	limit := big.NewInt(1)
+	for i := 0; i < MaxPinLength; i++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+	for {
+		n, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return "", err
+		}
+		pin := fmt.Sprintf("%0*d", MaxPinLength, n)
+		if !weakPin(pin) && pin != current {
+			return pin, nil
+		}
+	}
+}
+
+// ====== EXPIRY ======
+
+// DeactivateExpired menonaktifkan user aktif yang expires_at-nya sudah
+// lewat dan mengembalikan user yang dinonaktifkan.
+func (s *Service) DeactivateExpired(now time.Time) ([]store.DoorlockUser, error) {
+	var expired []store.DoorlockUser
+	if err := s.db.Where("is_active = ? AND expires_at <= ?", true, now).Find(&expired).Error; err != nil {
+		return nil, err
+	}
+	for i := range expired {
+		expired[i].IsActive = false
+		if err := s.store.SaveDoorlockUser(&expired[i]); err != nil {
+			return expired[:i], err
+		}
+	}
+	return expired, nil
+}
+
+// RunExpiry memeriksa kedaluwarsa setiap menit. onExpire dipanggil untuk
+// setiap user yang dinonaktifkan (mis. untuk audit log).
+func (s *Service) RunExpiry(onExpire func(store.DoorlockUser)) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for ; ; <-ticker.C {
+		expired, err := s.DeactivateExpired(time.Now())
+		if err != nil {
+			log.Printf("⚠️ Gagal menonaktifkan user doorlock kedaluwarsa: %v", err)
+		}
+		for _, u := range expired {
+			log.Printf("⏳ User doorlock %s (%s) kedaluwarsa dan dinonaktifkan", u.Name, u.AccessID)
+			if onExpire != nil {
+				onExpire(u)
+			}
+		}
+	}
+}
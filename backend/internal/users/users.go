@@ -0,0 +1,225 @@
+// Package users mengelola user dashboard dan user doorlock. Handler HTTP
+// dan subcommand CLI memakai service yang sama sehingga aturan validasi,
+// hashing password dan pencabutan sesi hanya ada di satu tempat.
+package users
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/auth"
+	"smart-door-lock/backend/internal/store"
+)
+
+var (
+	ErrNotFound   = errors.New("user not found")
+	ErrExists     = auth.ErrUserExists
+	ErrInvalid    = errors.New("invalid input")
+	ErrSelfDelete = errors.New("cannot delete your own account")
+)
+
+// Service dipakai bersama oleh httpapi dan CLI.
+type Service struct {
+	db    *gorm.DB
+	store store.Store
+}
+
+func NewService(db *gorm.DB, st store.Store) *Service {
+	return &Service{db: db, store: st}
+}
+
+// notFound menerjemahkan record-not-found menjadi ErrNotFound.
+func notFound(err error) error {
+	if store.IsNotFound(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// checkRole mengisi role kosong dengan user dan menolak role lain.
+func checkRole(role string) (string, error) {
+	switch role {
+	case "":
+		return store.RoleUser, nil
+	case store.RoleAdmin, store.RoleUser:
+		return role, nil
+	default:
+		return "", fmt.Errorf("%w: role harus %s atau %s", ErrInvalid, store.RoleAdmin, store.RoleUser)
+	}
+}
+
+// checkPassword menerapkan kebijakan password admin; user biasa cukup
+// tidak kosong.
+func checkPassword(username, password, role string) error {
+	if password == "" {
+		return fmt.Errorf("%w: password wajib diisi", ErrInvalid)
+	}
+	if role == store.RoleAdmin {
+		return auth.CheckPassword(username, password)
+	}
+	return nil
+}
+
+// ====== USER DASHBOARD ======
+
+// List mengembalikan semua user dashboard urut id.
+func (s *Service) List() ([]store.User, error) {
+	var list []store.User
+	err := s.db.Order("id").Find(&list).Error
+	return list, err
+}
+
+// Create membuat user dashboard aktif.
+func (s *Service) Create(username, password, role string) (store.User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return store.User{}, auth.ErrUsernameRequired
+	}
+	role, err := checkRole(role)
+	if err != nil {
+		return store.User{}, err
+	}
+	if err := checkPassword(username, password, role); err != nil {
+		return store.User{}, err
+	}
+	if _, err := s.store.UserByUsername(username); err == nil {
+		return store.User{}, ErrExists
+	} else if !store.IsNotFound(err) {
+		return store.User{}, err
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return store.User{}, err
+	}
+	u := store.User{
+		Username:  username,
+		Password:  hash,
+		Role:      role,
+		IsActive:  true,
+		CreatedAt: time.Now(),
+	}
+	return u, s.store.CreateUser(&u)
+}
+
+// UserUpdate adalah perubahan dari PUT /api/users/:id. Password kosong
+// berarti tidak diubah, kecuali saat user dijadikan admin: password baru
+// wajib dan harus lolos kebijakan password admin.
+type UserUpdate struct {
+	Username       string
+	Password       string
+	Role           string
+	IsActive       bool
+	TelegramChatID *int64
+}
+
+// Update mengganti data user dan mengembalikan nilai sebelum dan sesudah.
+func (s *Service) Update(id uint, in UserUpdate) (store.User, store.User, error) {
+	u, err := s.store.UserByID(id)
+	if err != nil {
+		return u, u, notFound(err)
+	}
+	before := u
+
+	in.Username = strings.TrimSpace(in.Username)
+	if in.Username == "" {
+		return before, u, auth.ErrUsernameRequired
+	}
+	role, err := checkRole(in.Role)
+	if err != nil {
+		return before, u, err
+	}
+	if role == store.RoleAdmin && before.Role != store.RoleAdmin && in.Password == "" {
+		return before, u, fmt.Errorf("%w: password baru wajib saat menjadikan user admin", auth.ErrWeakPassword)
+	}
+	if in.Password != "" {
+		if err := checkPassword(in.Username, in.Password, role); err != nil {
+			return before, u, err
+		}
+		hash, err := auth.HashPassword(in.Password)
+		if err != nil {
+			return before, u, err
+		}
+		// password baru mencabut sesi lama, sama seperti ResetPassword
+		u.Password = hash
+		u.SessionVersion++
+	}
+	if in.Username != u.Username {
+		if _, err := s.store.UserByUsername(in.Username); err == nil {
+			return before, u, ErrExists
+		} else if !store.IsNotFound(err) {
+			return before, u, err
+		}
+	}
+
+	u.Username = in.Username
+	u.Role = role
+	u.IsActive = in.IsActive
+	if in.TelegramChatID != nil {
+		u.TelegramChatID = *in.TelegramChatID
+	}
+	return before, u, s.store.SaveUser(&u)
+}
+
+// Delete menghapus user; actor tidak boleh menghapus dirinya sendiri.
+func (s *Service) Delete(id uint, actor string) (store.User, error) {
+	if current, err := s.store.UserByUsername(actor); err == nil && current.ID == id {
+		return store.User{}, ErrSelfDelete
+	}
+	u, err := s.store.UserByID(id)
+	if err != nil {
+		return u, notFound(err)
+	}
+	return u, s.store.DeleteUser(&u)
+}
+
+// SetActive mengaktifkan atau menonaktifkan user. Token user nonaktif
+// langsung ditolak middleware auth.
+func (s *Service) SetActive(username string, active bool) (store.User, error) {
+	u, err := s.store.UserByUsername(username)
+	if err != nil {
+		return u, notFound(err)
+	}
+	u.IsActive = active
+	return u, s.store.SaveUser(&u)
+}
+
+// ResetPassword mengganti password dan mencabut semua sesi user tersebut.
+func (s *Service) ResetPassword(username, password string) (store.User, error) {
+	u, err := s.store.UserByUsername(username)
+	if err != nil {
+		return u, notFound(err)
+	}
+	if err := checkPassword(u.Username, password, u.Role); err != nil {
+		return u, err
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return u, err
+	}
+	u.Password = hash
+	u.SessionVersion++
+	return u, s.store.SaveUser(&u)
+}
+
+// RevokeSessions membatalkan semua token yang sudah diterbitkan untuk user.
+func (s *Service) RevokeSessions(username string) (store.User, error) {
+	u, err := s.store.UserByUsername(username)
+	if err != nil {
+		return u, notFound(err)
+	}
+	u.SessionVersion++
+	return u, s.store.SaveUser(&u)
+}
+
+// RevokeAllSessions membatalkan token semua user, mis. setelah JWT secret
+// diduga bocor. Mengembalikan jumlah user yang terdampak.
+func (s *Service) RevokeAllSessions() (int64, error) {
+	res := s.db.Model(&store.User{}).Where("1 = 1").
+		Update("session_version", gorm.Expr("session_version + 1"))
+	return res.RowsAffected, res.Error
+}
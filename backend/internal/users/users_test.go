@@ -0,0 +1,309 @@
+package users
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"smart-door-lock/backend/internal/auth"
+	"smart-door-lock/backend/internal/store"
+	"smart-door-lock/backend/internal/store/storetest"
+)
+
+func newService(t *testing.T) *Service {
+	t.Helper()
+	db := storetest.Open(t, store.Models()...)
+	return NewService(db, store.NewGorm(db))
+}
+
+func TestCreateUser(t *testing.T) {
+	svc := newService(t)
+
+	u, err := svc.Create(" budi ", "rahasia", "")
+	if err != nil || u.Username != "budi" || u.Role != store.RoleUser || !u.IsActive {
+		t.Fatalf("Create = %+v, %v", u, err)
+	}
+	if _, err := svc.Create("budi", "rahasia", ""); !errors.Is(err, ErrExists) {
+		t.Errorf("duplicate: err = %v", err)
+	}
+	if _, err := svc.Create("ops", "rahasia", store.RoleAdmin); !errors.Is(err, auth.ErrWeakPassword) {
+		t.Errorf("weak admin password: err = %v", err)
+	}
+	if _, err := svc.Create("ops", "rahasia", "root"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("unknown role: err = %v", err)
+	}
+}
+
+func TestUpdatePromotionNeedsStrongPassword(t *testing.T) {
+	svc := newService(t)
+	u, err := svc.Create("budi", "rahasia", store.RoleUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	promote := UserUpdate{Username: "budi", Role: store.RoleAdmin, IsActive: true}
+	if _, _, err := svc.Update(u.ID, promote); !errors.Is(err, auth.ErrWeakPassword) {
+		t.Errorf("promote without password: err = %v", err)
+	}
+	promote.Password = "rahasia"
+	if _, _, err := svc.Update(u.ID, promote); !errors.Is(err, auth.ErrWeakPassword) {
+		t.Errorf("promote with weak password: err = %v", err)
+	}
+	promote.Password = "Gerbang-Utama-2026"
+	_, u, err = svc.Update(u.ID, promote)
+	if err != nil || u.Role != store.RoleAdmin || u.SessionVersion != 1 || !auth.VerifyPassword(u.Password, "Gerbang-Utama-2026") {
+		t.Fatalf("promote = %+v, %v", u, err)
+	}
+
+	// admin yang sudah admin boleh diubah tanpa ganti password
+	if _, u, err = svc.Update(u.ID, UserUpdate{Username: "budi", Role: store.RoleAdmin, IsActive: true}); err != nil || u.SessionVersion != 1 {
+		t.Errorf("update admin without password = %+v, %v", u, err)
+	}
+}
+
+func TestResetPasswordRevokesSessions(t *testing.T) {
+	svc := newService(t)
+	if _, err := svc.Create("admin", "Pintu-Kantor-2026", store.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.ResetPassword("admin", "pendek"); !errors.Is(err, auth.ErrWeakPassword) {
+		t.Errorf("weak reset: err = %v", err)
+	}
+	u, err := svc.ResetPassword("admin", "Gerbang-Utama-2026")
+	if err != nil || !auth.VerifyPassword(u.Password, "Gerbang-Utama-2026") || u.SessionVersion != 1 {
+		t.Fatalf("ResetPassword = %+v, %v", u, err)
+	}
+	if _, err := svc.ResetPassword("nobody", "Gerbang-Utama-2026"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown user: err = %v", err)
+	}
+
+	if n, err := svc.RevokeAllSessions(); err != nil || n != 1 {
+		t.Fatalf("RevokeAllSessions = %d, %v", n, err)
+	}
+	list, _ := svc.List()
+	if list[0].SessionVersion != 2 {
+		t.Errorf("session_version = %d, want 2", list[0].SessionVersion)
+	}
+}
+
+func TestImportIsAllOrNothing(t *testing.T) {
+	svc := newService(t)
+	svc.store.SaveDoor(&store.Door{DoorID: "D01"})
+	if _, err := svc.CreateDoorlockUser(DoorlockInput{Name: "Budi", AccessID: "A001", DoorID: "D01", Pin: "482913"}); err != nil {
+		t.Fatal(err)
+	}
+	svc.store.SaveDoor(&store.Door{DoorID: "D02"})
+
+	rows := []DoorlockInput{
+		{Name: "Ani", AccessID: "B001", DoorID: "D01", Pin: "2580"},
+		{Name: "Budi", AccessID: "A001", DoorID: "D02", Pin: "650193"}, // sudah ada
+		{Name: "Citra", AccessID: "B002", DoorID: "D01", Pin: "12a4"},
+		{Name: "Ani 2", AccessID: "B001", DoorID: "D01", Pin: "7391"},
+	}
+	report, err := svc.ImportDoorlockUsers(rows, ImportOptions{})
+	if !errors.Is(err, ErrImportInvalid) || report.Applied || report.Failed != 3 {
+		t.Fatalf("import = %+v, %v", report, err)
+	}
+	if report.Rows[0].Action != ImportCreate || report.Rows[3].Action != ImportError {
+		t.Errorf("rows = %+v", report.Rows)
+	}
+	if list, _ := svc.DoorlockUsers(); len(list) != 1 {
+		t.Fatalf("invalid import stored %d users", len(list))
+	}
+
+	// dengan Update, baris tanpa PIN mempertahankan PIN lama
+	rows = []DoorlockInput{
+		{Name: "Ani", AccessID: "B001", DoorID: "D01", Pin: "2580"},
+		{Name: "Budi", AccessID: "A001", DoorID: "D02"},
+	}
+	report, err = svc.ImportDoorlockUsers(rows, ImportOptions{Update: true})
+	if err != nil || !report.Applied || report.Created != 1 || report.Updated != 1 {
+		t.Fatalf("import = %+v, %v", report, err)
+	}
+	list, _ := svc.DoorlockUsers()
+	if len(list) != 2 || list[0].DoorID != "D02" || list[0].Pin != "482913" || !list[1].IsActive {
+		t.Errorf("users = %+v", list)
+	}
+}
+
+func TestCreateDoorlockUserValidation(t *testing.T) {
+	svc := newService(t)
+	svc.store.SaveDoor(&store.Door{DoorID: "D01"})
+
+	for _, in := range []DoorlockInput{
+		{Name: "Budi", AccessID: "A001", DoorID: "D01", Pin: "1234"},
+		{Name: "Budi", AccessID: "A001", DoorID: "D99", Pin: "482913"},
+	} {
+		if _, err := svc.CreateDoorlockUser(in); !errors.Is(err, ErrInvalid) {
+			t.Errorf("create %+v: err = %v", in, err)
+		}
+	}
+	if _, err := svc.CreateDoorlockUser(DoorlockInput{Name: "Budi", AccessID: "A001", DoorID: "D01", Pin: "482913"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportValidationModes(t *testing.T) {
+	svc := newService(t)
+	svc.store.SaveDoor(&store.Door{DoorID: "D01"})
+
+	rows := []DoorlockInput{
+		{Name: "Ani", AccessID: "B001", DoorID: "D01", Pin: "2580"},
+		{Name: "Budi", AccessID: "B002", DoorID: "D01", Pin: "1111"},
+		{Name: "Citra", AccessID: "B003", DoorID: "D01", Pin: "654321"},
+		{Name: "Dedi", AccessID: "B004", DoorID: "D99", Pin: "2580"},
+	}
+	report, err := svc.ImportDoorlockUsers(rows, ImportOptions{DryRun: true, Partial: true})
+	if err != nil || report.Applied || report.Created != 1 || report.Failed != 3 {
+		t.Fatalf("dry run = %+v, %v", report, err)
+	}
+	for _, i := range []int{1, 2, 3} {
+		if len(report.Rows[i].Errors) != 1 {
+			t.Errorf("row %d errors = %v", i+1, report.Rows[i].Errors)
+		}
+	}
+	if list, _ := svc.DoorlockUsers(); len(list) != 0 {
+		t.Fatalf("dry run stored %d users", len(list))
+	}
+
+	report, err = svc.ImportDoorlockUsers(rows, ImportOptions{Partial: true})
+	if err != nil || !report.Applied || report.Created != 1 {
+		t.Fatalf("partial = %+v, %v", report, err)
+	}
+	if list, _ := svc.DoorlockUsers(); len(list) != 1 || list[0].AccessID != "B001" {
+		t.Errorf("partial stored %+v", list)
+	}
+}
+
+func TestDoorlockCSVRoundTrip(t *testing.T) {
+	list := []store.DoorlockUser{
+		{Name: "Budi, S.T.", AccessID: "A001", DoorID: "D01", Pin: "123456", IsActive: true},
+		{Name: "Ani", AccessID: "A002", DoorID: "D02", Pin: "1234"},
+	}
+	var buf bytes.Buffer
+	if err := WriteDoorlockCSV(&buf, list, true); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := ReadDoorlockCSV(strings.NewReader("\ufeff"+buf.String()), time.UTC)
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("read = %+v, %v", rows, err)
+	}
+	if rows[0].Name != "Budi, S.T." || rows[0].Pin != "123456" || *rows[1].IsActive {
+		t.Errorf("rows = %+v", rows)
+	}
+
+	buf.Reset()
+	WriteDoorlockCSV(&buf, list, false)
+	if strings.Contains(buf.String(), "123456") {
+		t.Error("PIN exported without withPins")
+	}
+
+	if _, err := ReadDoorlockCSV(strings.NewReader("name,access_id,door,pin\n"), time.UTC); !errors.Is(err, ErrInvalid) {
+		t.Errorf("unknown column: err = %v", err)
+	}
+
+	// nilai yang salah menjadi error baris, bukan menolak seluruh file
+	rows, err = ReadDoorlockCSV(strings.NewReader("name,access_id,door_id,pin,is_active,expires_at\n"+
+		"Ani,B001,D01,2580,ya,\nCitra,B002,D01,7391,true,besok\nDedi,B003,D01,7391,,\n"), time.UTC)
+	if err != nil || len(rows) != 3 {
+		t.Fatalf("read with bad values = %+v, %v", rows, err)
+	}
+	if len(rows[0].parseErrors) != 1 || len(rows[1].parseErrors) != 1 || len(rows[2].parseErrors) != 0 {
+		t.Errorf("parse errors = %v / %v / %v", rows[0].parseErrors, rows[1].parseErrors, rows[2].parseErrors)
+	}
+	svc := newService(t)
+	svc.store.SaveDoor(&store.Door{DoorID: "D01"})
+	report, err := svc.ImportDoorlockUsers(rows, ImportOptions{Partial: true})
+	if err != nil || report.Created != 1 || report.Failed != 2 || report.Rows[0].Action != ImportError {
+		t.Errorf("import = %+v, %v", report, err)
+	}
+}
+
+func TestDoorlockLifecycle(t *testing.T) {
+	svc := newService(t)
+	svc.store.SaveDoor(&store.Door{DoorID: "D01"})
+	if _, err := svc.CreateDoorlockUser(DoorlockInput{Name: "Budi", AccessID: "A001", DoorID: "D01", Pin: "482913"}); err != nil {
+		t.Fatal(err)
+	}
+
+	name, past, future := "Budi S.", time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	_, u, err := svc.UpdateDoorlockUser("A001", DoorlockPatch{Name: &name, ExpiresAt: OptionalTime{Set: true, Time: &future}})
+	if err != nil || u.Name != name || u.DoorID != "D01" || u.ExpiresAt == nil {
+		t.Fatalf("patch = %+v, %v", u, err)
+	}
+	if _, _, err := svc.UpdateDoorlockUser("A001", DoorlockPatch{ExpiresAt: OptionalTime{Set: true, Time: &past}}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("past expiry: err = %v", err)
+	}
+	unknown, other := "D99", "D02"
+	if _, _, err := svc.UpdateDoorlockUser("A001", DoorlockPatch{DoorID: &unknown}); !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), "D99 tidak dikenal") {
+		t.Errorf("unknown door: err = %v", err)
+	}
+	svc.store.SaveDoor(&store.Door{DoorID: other})
+	if _, u, err := svc.UpdateDoorlockUser("A001", DoorlockPatch{DoorID: &other}); err != nil || u.DoorID != other {
+		t.Errorf("move to configured door = %+v, %v", u, err)
+	}
+
+	if _, _, err := svc.Suspend("A001", " "); !errors.Is(err, ErrInvalid) {
+		t.Errorf("suspend without reason: err = %v", err)
+	}
+	if _, u, err = svc.Suspend("A001", "kartu hilang"); err != nil || u.IsActive || u.SuspendReason != "kartu hilang" {
+		t.Fatalf("suspend = %+v, %v", u, err)
+	}
+	if _, u, err = svc.Resume("A001"); err != nil || !u.IsActive || u.SuspendedAt != nil {
+		t.Fatalf("resume = %+v, %v", u, err)
+	}
+
+	// kedaluwarsa: dinonaktifkan job dan tidak bisa di-resume tanpa expiry baru
+	expired, err := svc.DeactivateExpired(future.Add(time.Minute))
+	if err != nil || len(expired) != 1 || expired[0].IsActive {
+		t.Fatalf("DeactivateExpired = %+v, %v", expired, err)
+	}
+	svc.db.Model(&store.DoorlockUser{}).Where("access_id = ?", "A001").Update("expires_at", past)
+	if _, _, err := svc.Resume("A001"); !errors.Is(err, ErrExpired) {
+		t.Errorf("resume expired: err = %v", err)
+	}
+	active := true
+	if _, u, err = svc.UpdateDoorlockUser("A001", DoorlockPatch{IsActive: &active, ExpiresAt: OptionalTime{Set: true}}); err != nil || !u.IsActive || u.ExpiresAt != nil {
+		t.Fatalf("reactivate = %+v, %v", u, err)
+	}
+
+	// soft-delete: hilang dari daftar, access_id tidak bisa dipakai ulang
+	if _, err := svc.DeleteDoorlockUser("A001"); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := svc.DoorlockUsers(); len(list) != 0 {
+		t.Errorf("deleted user still listed: %+v", list)
+	}
+	var deleted int64
+	svc.db.Unscoped().Model(&store.DoorlockUser{}).Where("deleted_at IS NOT NULL").Count(&deleted)
+	if deleted != 1 {
+		t.Errorf("soft-deleted rows = %d, want 1", deleted)
+	}
+	if _, err := svc.CreateDoorlockUser(DoorlockInput{Name: "Baru", AccessID: "A001", DoorID: "D01", Pin: "739105"}); !errors.Is(err, ErrAccessIDTaken) {
+		t.Errorf("reuse deleted access_id: err = %v", err)
+	}
+}
+
+func TestRotatePin(t *testing.T) {
+	svc := newService(t)
+	svc.store.SaveDoor(&store.Door{DoorID: "D01"})
+	if _, err := svc.CreateDoorlockUser(DoorlockInput{Name: "Budi", AccessID: "A001", DoorID: "D01", Pin: "482913"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pin := range []string{"482913", "123456", "12"} {
+		if _, _, err := svc.RotatePin("A001", pin); !errors.Is(err, ErrInvalid) {
+			t.Errorf("RotatePin(%q): err = %v", pin, err)
+		}
+	}
+	u, pin, err := svc.RotatePin("A001", "")
+	if err != nil || len(pin) != MaxPinLength || u.Pin != pin || u.PinChangedAt == nil || weakPin(pin) {
+		t.Fatalf("generated = %q, %+v, %v", pin, u, err)
+	}
+	if _, _, err := svc.RotatePin("A404", "739105"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown user: err = %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"smart-door-lock/backend/internal/auth"
+	"smart-door-lock/backend/internal/store"
+)
+
+// ====== SEEDING ======
+
+// seedIfEmpty mengisi data demo bila belum ada user sama sekali dan
+// mengembalikan true bila data diisi. Hanya dipanggil lewat subcommand seed
+// atau SEED_DEMO=true karena kredensialnya diketahui umum.
+func seedIfEmpty(db *gorm.DB) bool {
+	var userCount int64
+	db.Model(&store.User{}).Count(&userCount)
+	if userCount > 0 {
+		return false
+	}
+	log.Println("Database is empty, seeding demo data...")
+	seedUsers(db)
+	seedDoorlockUsers(db)
+	seedAttendanceData(db)
+	log.Println("✅ Demo data seeding complete.")
+	return true
+}
+
+func seedUsers(db *gorm.DB) {
+	adminPass, err := auth.HashPassword("admin123")
+	if err != nil {
+		log.Fatalf("❌ Failed to hash password: %v", err)
+	}
+	userPass, err := auth.HashPassword("password123")
+	if err != nil {
+		log.Fatalf("❌ Failed to hash password: %v", err)
+	}
+
+	users := []store.User{
+		{Username: "admin", Password: adminPass, Role: "admin", IsActive: true, CreatedAt: time.Now()},
+		{Username: "budi", Password: userPass, Role: "user", IsActive: true, CreatedAt: time.Now()},
+		{Username: "citra", Password: userPass, Role: "user", IsActive: false, CreatedAt: time.Now()},
+	}
+
+	if err := db.Create(&users).Error; err != nil {
+		log.Fatalf("❌ Failed to seed users: %v", err)
+	}
+	log.Println(" 	-> ✅ Users seeded.")
+}
+
+func seedDoorlockUsers(db *gorm.DB) {
+	doorUsers := []store.DoorlockUser{
+		{Name: "Budi", AccessID: "A001", DoorID: "D01", Pin: "123456", IsActive: true, CreatedAt: time.Now()},
+		{Name: "Citra", AccessID: "A002", DoorID: "D01", Pin: "654321", IsActive: true, CreatedAt: time.Now()},
+		{Name: "Dewi", AccessID: "A003", DoorID: "D02", Pin: "111111", IsActive: true, CreatedAt: time.Now()},
+		{Name: "Eka", AccessID: "A004", DoorID: "D02", Pin: "222222", IsActive: true, CreatedAt: time.Now()},
+		{Name: "Fajar", AccessID: "A005", DoorID: "D01", Pin: "333333", IsActive: false, CreatedAt: time.Now()},
+	}
+	if err := db.Create(&doorUsers).Error; err != nil {
+		log.Fatalf("❌ Failed to seed doorlock users: %v", err)
+	}
+	log.Println(" 	-> ✅ Doorlock Users seeded.")
+}
+
+func seedAttendanceData(db *gorm.DB) {
+	loc, _ := time.LoadLocation("Asia/Jakarta")
+
+	attendances := []store.Attendance{
+		{Username: "Budi", AccessID: "A001", Status: "success", Arrow: "in", CreatedAt: time.Date(2025, 10, 5, 9, 15, 0, 0, loc)},
+		{Username: "Citra", AccessID: "A002", Status: "success", Arrow: "in", CreatedAt: time.Date(2025, 10, 5, 14, 30, 0, 0, loc)},
+		{Username: "Budi", AccessID: "A001", Status: "success", Arrow: "out", CreatedAt: time.Date(2025, 10, 5, 17, 0, 0, 0, loc)},
+		{Username: "Budi", AccessID: "A001", Status: "success", Arrow: "in", CreatedAt: time.Date(2025, 10, 6, 8, 5, 0, 0, loc)},
+		{Username: "Citra", AccessID: "A002", Status: "success", Arrow: "in", CreatedAt: time.Date(2025, 10, 6, 8, 7, 0, 0, loc)},
+		{Username: "Dewi", AccessID: "A003", Status: "success", Arrow: "in", CreatedAt: time.Date(2025, 10, 6, 8, 10, 0, 0, loc)},
+		{Username: "Eka", AccessID: "A004", Status: "success", Arrow: "in", CreatedAt: time.Date(2025, 10, 6, 9, 0, 0, 0, loc)},
+		{Username: "Budi", AccessID: "A001", Status: "success", Arrow: "out", CreatedAt: time.Date(2025, 10, 6, 17, 30, 0, 0, loc)},
+		{Username: "Citra", AccessID: "A002", Status: "success", Arrow: "in", CreatedAt: time.Date(2025, 10, 7, 8, 20, 0, 0, loc)},
+		{Username: "Dewi", AccessID: "A003", Status: "success", Arrow: "in", CreatedAt: time.Date(2025, 10, 7, 9, 5, 0, 0, loc)},
+		{Username: "Eka", AccessID: "A004", Status: "success", Arrow: "out", CreatedAt: time.Date(2025, 10, 7, 18, 0, 0, 0, loc)},
+	}
+
+	if err := db.Create(&attendances).Error; err != nil {
+		log.Fatalf("❌ Failed to seed attendance data: %v", err)
+	}
+
+	log.Println(" 	-> ✅ Attendance data seeded.")
+}
@@ -1,18 +1,24 @@
 import { useEffect, useState } from "react";
-import { apiGet, mqttService } from "../services/api";
+import { apiList, mqttService } from "../services/api";
+
+const PAGE_SIZE = 50;
 
 export default function Alarms() {
   const [list, setList] = useState([]);
+  const [page, setPage] = useState(1);
+  const [total, setTotal] = useState(0);
   const [loading, setLoading] = useState(false);
   const [connectionStatus, setConnectionStatus] = useState(false);
   const [lastUpdate, setLastUpdate] = useState(new Date());
   const [newAlarms, setNewAlarms] = useState(0);
 
-  const load = async () => {
+  const load = async (targetPage = page) => {
     try {
       setLoading(true);
-      const res = await apiGet("/alarms");
-      setList(res || []);
+      const res = await apiList("/alarms", { page: targetPage, page_size: PAGE_SIZE });
+      setList(res.data);
+      setTotal(res.total);
+      setPage(targetPage);
       setLastUpdate(new Date());
       setNewAlarms(0); // Reset counter setelah load
     } catch (err) {
@@ -142,7 +148,7 @@ export default function Alarms() {
           </span>
           <button 
             className="btn btn-sm btn-outline-primary me-2"
-            onClick={load}
+            onClick={() => load()}
             disabled={loading}
           >
             {loading ? (
@@ -168,7 +174,7 @@ export default function Alarms() {
             <div>
               <button 
                 className="btn btn-sm btn-outline-danger me-2"
-                onClick={load}
+                onClick={() => load()}
               >
                 Load Now
               </button>
@@ -187,7 +193,7 @@ export default function Alarms() {
         <div className="col-md-3">
           <div className="card text-center border-dark">
             <div className="card-body py-3">
-              <h5 className="card-title text-dark mb-1">{list.length}</h5>
+              <h5 className="card-title text-dark mb-1">{total}</h5>
               <small className="text-muted">Total Alarms</small>
             </div>
           </div>
@@ -257,7 +263,7 @@ export default function Alarms() {
               ) : (
                 list.map((a, index) => (
                   <tr key={a.id} className={index < newAlarms ? 'table-danger' : ''}>
-                    <td>{index + 1}</td>
+                    <td>{(page - 1) * PAGE_SIZE + index + 1}</td>
                     <td>
                       <strong>{a.username}</strong>
                     </td>
@@ -288,6 +294,27 @@ export default function Alarms() {
               )}
             </tbody>
           </table>
+          <div className="d-flex justify-content-between align-items-center">
+            <small className="text-muted">
+              Page {page} of {Math.max(1, Math.ceil(total / PAGE_SIZE))} • {total} record(s)
+            </small>
+            <div>
+              <button
+                className="btn btn-sm btn-outline-secondary me-2"
+                onClick={() => load(page - 1)}
+                disabled={page <= 1}
+              >
+                ‹ Prev
+              </button>
+              <button
+                className="btn btn-sm btn-outline-secondary"
+                onClick={() => load(page + 1)}
+                disabled={page * PAGE_SIZE >= total}
+              >
+                Next ›
+              </button>
+            </div>
+          </div>
         </div>
       )}
 
@@ -1,18 +1,24 @@
 import { useEffect, useState } from "react";
-import { apiGet, mqttService } from "../services/api";
+import { apiList, mqttService } from "../services/api";
+
+const PAGE_SIZE = 50;
 
 export default function Attendance() {
   const [list, setList] = useState([]);
+  const [page, setPage] = useState(1);
+  const [total, setTotal] = useState(0);
   const [loading, setLoading] = useState(false);
   const [connectionStatus, setConnectionStatus] = useState(false);
   const [lastUpdate, setLastUpdate] = useState(new Date());
   const [newRecords, setNewRecords] = useState(0);
 
-  const load = async () => {
+  const load = async (targetPage = page) => {
     try {
       setLoading(true);
-      const res = await apiGet("/attendance");
-      setList(res || []);
+      const res = await apiList("/attendance", { page: targetPage, page_size: PAGE_SIZE });
+      setList(res.data);
+      setTotal(res.total);
+      setPage(targetPage);
       setLastUpdate(new Date());
       setNewRecords(0); // Reset counter setelah load
     } catch (err) {
@@ -142,7 +148,7 @@ export default function Attendance() {
           </span>
           <button 
             className="btn btn-sm btn-outline-primary me-2"
-            onClick={load}
+            onClick={() => load()}
             disabled={loading}
           >
             {loading ? (
@@ -168,7 +174,7 @@ export default function Attendance() {
             <div>
               <button 
                 className="btn btn-sm btn-outline-info me-2"
-                onClick={load}
+                onClick={() => load()}
               >
                 Load Now
               </button>
@@ -187,7 +193,7 @@ export default function Attendance() {
         <div className="col-md-3">
           <div className="card text-center border-primary">
             <div className="card-body py-3">
-              <h5 className="card-title text-primary mb-1">{list.length}</h5>
+              <h5 className="card-title text-primary mb-1">{total}</h5>
               <small className="text-muted">Total Records</small>
             </div>
           </div>
@@ -257,7 +263,7 @@ export default function Attendance() {
               ) : (
                 list.map((a, index) => (
                   <tr key={a.id} className={index < newRecords ? 'table-success' : ''}>
-                    <td>{index + 1}</td>
+                    <td>{(page - 1) * PAGE_SIZE + index + 1}</td>
                     <td>
                       <strong>{a.username}</strong>
                     </td>
@@ -298,6 +304,27 @@ export default function Attendance() {
               )}
             </tbody>
           </table>
+          <div className="d-flex justify-content-between align-items-center">
+            <small className="text-muted">
+              Page {page} of {Math.max(1, Math.ceil(total / PAGE_SIZE))} • {total} record(s)
+            </small>
+            <div>
+              <button
+                className="btn btn-sm btn-outline-secondary me-2"
+                onClick={() => load(page - 1)}
+                disabled={page <= 1}
+              >
+                ‹ Prev
+              </button>
+              <button
+                className="btn btn-sm btn-outline-secondary"
+                onClick={() => load(page + 1)}
+                disabled={page * PAGE_SIZE >= total}
+              >
+                Next ›
+              </button>
+            </div>
+          </div>
         </div>
       )}
 
@@ -4,6 +4,7 @@ import {
   getAttendance,
   getAlarms,
   getAttendanceSummary,
+  getAttendanceTimeseries,
   getDoorlockUsersCount,
   getFrequentAccess,
   getLongOpenDoors,
@@ -177,8 +178,8 @@ export default function Dashboard() {
       const results = await Promise.allSettled([
         getSystemUsers(),
         getDoorlockUsersCount(),
-        getAttendance(),
-        getAlarms(),
+        getAttendance({ page_size: 500 }),
+        getAlarms({ page_size: 100 }),
         getAttendanceSummary(),
         getFrequentAccess(24),
         getLongOpenDoors(60),
@@ -202,12 +203,12 @@ export default function Dashboard() {
       if (doorlockResult.status === 'fulfilled') setDoorlockUsers(doorlockResult.value);
       if (attendanceResult.status === 'fulfilled') {
         setAttendance(attendanceResult.value);
-        processAttendanceData(attendanceResult.value);
       }
+      loadInOutSeries();
       if (alarmsResult.status === 'fulfilled') setAlarms(alarmsResult.value);
       if (frequentAccessResult.status === 'fulfilled') setFrequentAccess(frequentAccessResult.frequent_access || []);
       if (longOpenDoorsResult.status === 'fulfilled') setLongOpenDoors(longOpenDoorsResult.long_open_doors || []);
-      if (dashboardStatsResult.status === 'fulfilled') setDashboardStats(dashboardStatsResult);
+      if (dashboardStatsResult.status === 'fulfilled') setDashboardStats(dashboardStatsResult.value);
       
       // Update door status dari device status
       if (deviceStatusResult.status === 'fulfilled') {
@@ -242,9 +243,9 @@ export default function Dashboard() {
 
   const loadAttendanceData = async () => {
     try {
-      const attendanceData = await getAttendance();
+      const attendanceData = await getAttendance({ page_size: 500 });
       setAttendance(attendanceData);
-      processAttendanceData(attendanceData);
+      loadInOutSeries();
       setLastUpdate(new Date());
     } catch (err) {
       console.error('Error refreshing attendance:', err);
@@ -253,7 +254,7 @@ export default function Dashboard() {
 
   const loadAlarmsData = async () => {
     try {
-      const alarmsData = await getAlarms();
+      const alarmsData = await getAlarms({ page_size: 100 });
       setAlarms(alarmsData);
       setLastUpdate(new Date());
     } catch (err) {
@@ -261,32 +262,22 @@ export default function Dashboard() {
     }
   };
 
-  const processAttendanceData = (attendanceData) => {
-    const inData = [];
-    const outData = [];
-    
-    const groupedData = {};
-    
-    attendanceData.forEach(record => {
-      const date = record.created_at.split('T')[0];
-      if (!groupedData[date]) {
-        groupedData[date] = { date, in: 0, out: 0 };
-      }
-      
-      if (record.arrow === 'in') {
-        groupedData[date].in++;
-      } else if (record.arrow === 'out') {
-        groupedData[date].out++;
-      }
-    });
-    
-    Object.values(groupedData).forEach(data => {
-      inData.push({ date: data.date, count: data.in });
-      outData.push({ date: data.date, count: data.out });
-    });
-    
-    setAttendanceIn(inData.slice(-7));
-    setAttendanceOut(outData.slice(-7));
+  // Grafik masuk/keluar 7 hari terakhir dihitung di server per hari (zona waktu situs)
+  const loadInOutSeries = async () => {
+    try {
+      const ts = await getAttendanceTimeseries({ bucket: 'day', split: 'arrow' });
+      const toChart = (arrow) => {
+        const series = (ts.series || []).find(s => s.labels?.arrow === arrow);
+        return (ts.buckets || []).map((bucket, i) => ({
+          date: bucket.split('T')[0],
+          count: series ? series.counts[i] : 0
+        }));
+      };
+      setAttendanceIn(toChart('in'));
+      setAttendanceOut(toChart('out'));
+    } catch (err) {
+      console.error('Error loading attendance timeseries:', err);
+    }
   };
 
   const COLORS = ['#0088FE', '#00C49F', '#FFBB28', '#FF8042', '#8884D8'];
@@ -428,7 +419,7 @@ export default function Dashboard() {
               <div className="card text-center shadow-sm border-primary h-100">
                 <div className="card-body">
                   <h6 className="card-title text-primary">System Users</h6>
-                  <p className="display-6 text-primary mb-0">{dashboardStats.total_users || users.length}</p>
+                  <p className="display-6 text-primary mb-0">{dashboardStats.system_users ?? users.length}</p>
                   <small className="text-muted">Total system accounts</small>
                   {realTimeData.onlineUsers > 0 && (
                     <div className="mt-1">
@@ -445,7 +436,7 @@ export default function Dashboard() {
               <div className="card text-center shadow-sm border-success h-100">
                 <div className="card-body">
                   <h6 className="card-title text-success">Doorlock Users</h6>
-                  <p className="display-6 text-success mb-0">{dashboardStats.total_users || doorlockUsers}</p>
+                  <p className="display-6 text-success mb-0">{dashboardStats.total_users ?? doorlockUsers}</p>
                   <small className="text-muted">Registered access cards</small>
                 </div>
               </div>
@@ -456,7 +447,7 @@ export default function Dashboard() {
                 <div className="card-body">
                   <h6 className="card-title text-info">Today Access</h6>
                   <p className="display-6 text-info mb-0">{dashboardStats.today_attendance || '0'}</p>
-                  <small className="text-muted">Access today</small>
+                  <small className="text-muted">{dashboardStats.today_entries || 0} in / {dashboardStats.today_exits || 0} out · {dashboardStats.people_inside || 0} inside</small>
                   {realTimeData.newAttendance > 0 && (
                     <div className="mt-1">
                       <span className="badge bg-warning animate-pulse">
@@ -472,8 +463,8 @@ export default function Dashboard() {
               <div className="card text-center shadow-sm border-warning h-100">
                 <div className="card-body">
                   <h6 className="card-title text-warning">Active Alarms</h6>
-                  <p className="display-6 text-warning mb-0">{dashboardStats.active_alarms || alarms.length}</p>
-                  <small className="text-muted">Last 24 hours</small>
+                  <p className="display-6 text-warning mb-0">{dashboardStats.unresolved_alarms ?? alarms.length}</p>
+                  <small className="text-muted">Not yet resolved</small>
                   {realTimeData.newAlarms > 0 && (
                     <div className="mt-1">
                       <span className="badge bg-danger animate-pulse">
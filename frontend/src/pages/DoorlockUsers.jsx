@@ -1,5 +1,5 @@
 import { useEffect, useState } from "react";
-import { apiGet, apiPost, apiDelete } from "../services/api";
+import { apiList, apiPost, apiDelete } from "../services/api";
 import { mqttService } from "../services/api";
 
 export default function DoorlockUsers() {
@@ -9,8 +9,8 @@ export default function DoorlockUsers() {
 
   const loadUsers = async () => {
     try {
-      const res = await apiGet("/doorlock/users");
-      setUsers(res || []);
+      const res = await apiList("/doorlock/users", { page_size: 500 });
+      setUsers(res.data);
     } catch (err) {
       console.error("Failed to load doorlock users:", err);
       setUsers([]);
@@ -183,7 +183,7 @@ export default function DoorlockUsers() {
                   <td>{u.name}</td>
                   <td>{u.access_id}</td>
                   <td>{u.door_id}</td>
-                  <td>{u.pin}</td>
+                  <td>••••••</td>
                   <td>
                     <span className={`badge ${u.is_active ? 'bg-success' : 'bg-danger'}`}>
                       {u.is_active ? "Active" : "Inactive"}
@@ -1,5 +1,5 @@
 import { useEffect, useState } from "react";
-import { apiGet, apiPost, apiDelete } from "../services/api";
+import { apiList, apiPost, apiDelete } from "../services/api";
 
 export default function Users() {
   const [users, setUsers] = useState([]);
@@ -12,8 +12,8 @@ export default function Users() {
     try {
       setLoading(true);
       setError("");
-      const res = await apiGet("/users/");
-      setUsers(res.users || []);   // ✅ keep full object with id
+      const res = await apiList("/users/", { page_size: 500 });
+      setUsers(res.data);   // ✅ keep full object with id
     } catch (err) {
       console.error("Error loading users:", err);
       setError("Failed to load users");
@@ -71,6 +71,28 @@ export async function apiDelete(path, auth = true) {
   return apiRequest('DELETE', path, null, auth);
 }
 
+// Membentuk query string dari objek, mengabaikan nilai kosong
+export function toQuery(params = {}) {
+  const qs = new URLSearchParams();
+  Object.entries(params).forEach(([key, value]) => {
+    if (value !== undefined && value !== null && value !== "") qs.append(key, value);
+  });
+  const s = qs.toString();
+  return s ? `?${s}` : "";
+}
+
+// List endpoint mengembalikan envelope { data, total, page, page_size, sort, next_cursor }
+export async function apiList(path, params = {}) {
+  const res = await apiGet(`${path}${toQuery(params)}`);
+  return {
+    data: res.data || [],
+    total: res.total || 0,
+    page: res.page || 1,
+    pageSize: res.page_size || 0,
+    nextCursor: res.next_cursor,
+  };
+}
+
 // ====== ENCRYPTION FUNCTIONS ======
 async function encryptData(data) {
   try {
@@ -162,8 +184,8 @@ export function logout() {
 }
 
 // ====== DOORLOCK USERS API ======
-export async function getDoorlockUsers() {
-  return apiGet("/doorlock/users");
+export async function getDoorlockUsers(params = {}) {
+  return apiList("/doorlock/users", params);
 }
 
 export async function createDoorlockUser(userData) {
@@ -175,8 +197,9 @@ export async function deleteDoorlockUser(accessId) {
 }
 
 // ====== ATTENDANCE API ======
-export async function getAttendance() {
-  return apiGet("/attendance");
+export async function getAttendance(params = {}) {
+  const res = await apiList("/attendance", params);
+  return res.data;
 }
 
 export async function getAttendanceSummary() {
@@ -190,14 +213,15 @@ export async function getAttendanceSummary() {
 }
 
 // ====== ALARMS API ======
-export async function getAlarms() {
-  return apiGet("/alarms");
+export async function getAlarms(params = {}) {
+  const res = await apiList("/alarms", params);
+  return res.data;
 }
 
 // ====== SYSTEM USERS API ======
 export async function getSystemUsers() {
-  const res = await apiGet("/users/");
-  return res.users || [];
+  const res = await apiList("/users/", { page_size: 500 });
+  return res.data;
 }
 
 export async function createSystemUser(userData) {
@@ -215,8 +239,8 @@ export async function deleteSystemUser(id) {
 // ====== DASHBOARD API ======
 export async function getDoorlockUsersCount() {
   try {
-    const data = await getDoorlockUsers();
-    return Array.isArray(data) ? data.length : 0;
+    const res = await getDoorlockUsers({ page_size: 1 });
+    return res.total;
   } catch (error) {
     console.error('Error fetching doorlock users:', error);
     throw error;
@@ -234,6 +258,26 @@ export async function getDashboardStats() {
 }
 
 // ====== TREND ANALYSIS APIs ======
+// Time series attendance per bucket (minute|hour|day|week), bisa dipecah per arrow/door/status
+export async function getAttendanceTimeseries(params = {}) {
+  try {
+    return await apiGet(`/analytics/timeseries${toQuery(params)}`);
+  } catch (error) {
+    console.error('Error fetching attendance timeseries:', error);
+    throw error;
+  }
+}
+
+// Heatmap jam x hari beserta jam tersibuk
+export async function getAccessHeatmap(params = {}) {
+  try {
+    return await apiGet(`/analytics/heatmap${toQuery(params)}`);
+  } catch (error) {
+    console.error('Error fetching access heatmap:', error);
+    throw error;
+  }
+}
+
 export async function getFrequentAccess(hours = 24) {
   try {
     const data = await apiGet(`/trends/frequent-access?hours=${hours}`);